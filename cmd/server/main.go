@@ -46,6 +46,11 @@ func main() {
 		}
 	}
 
+	// Limpeza periódica da lixeira de personagens
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handlers.IniciarLimpezaLixeira(ctx)
+
 	// Configurar e iniciar servidor
 	server := setupServer()
 	if err := startServerWithGracefulShutdown(server); err != nil {
@@ -80,6 +85,7 @@ func setupRouter() *gin.Engine {
 	r.Use(middleware.SetupCORS())
 	r.Use(middleware.RequestLogger())
 	r.Use(middleware.UserSessionMiddleware()) // Adiciona gerenciamento de sessão
+	r.Use(middleware.AuthMiddleware())        // Identifica o usuário logado, se houver
 
 	// Health check
 	r.GET("/health", healthCheck)
@@ -96,6 +102,13 @@ func setupRouter() *gin.Engine {
 		periciasHandler := handlers.NewPericiasHandler(database.DB)
 		habilidadeHandler := handlers.NewHabilidadeHandler()
 		poderHandler := handlers.NewPoderHandler()
+		itemCatalogoHandler := handlers.NewItemCatalogoHandler()
+		melhoriaHandler := handlers.NewMelhoriaHandler()
+		tesouroHandler := handlers.NewTesouroHandler()
+		authHandler := handlers.NewAuthHandler()
+		sessaoHandler := handlers.NewSessaoHandler()
+		adminHandler := handlers.NewAdminHandler()
+		mesaHandler := handlers.NewMesaHandler()
 
 		// Register routes
 		racaHandler.RegisterRoutes(api)
@@ -105,15 +118,26 @@ func setupRouter() *gin.Engine {
 		personagemHandler.RegisterRoutes(api)
 		habilidadeHandler.RegisterRoutes(api)
 		poderHandler.RegisterRoutes(api)
+		itemCatalogoHandler.RegisterRoutes(api)
+		melhoriaHandler.RegisterRoutes(api)
+		tesouroHandler.RegisterRoutes(api)
+		authHandler.RegisterRoutes(api)
+		sessaoHandler.RegisterRoutes(api)
+		adminHandler.RegisterRoutes(api)
+		mesaHandler.RegisterRoutes(api)
 
 		// Perícias routes
 		api.GET("/pericias", periciasHandler.GetPericias)
 		api.GET("/pericias/:id", periciasHandler.GetPericia)
+		escritaPericias := api.Group("/pericias", middleware.EscritaCatalogo()...)
+		escritaPericias.POST("", periciasHandler.CreatePericia)
+		escritaPericias.PUT("/:id", periciasHandler.UpdatePericia)
+		escritaPericias.DELETE("/:id", periciasHandler.DeletePericia)
 		api.GET("/classes/:id/pericias", periciasHandler.GetPericiasClasse)
 		api.GET("/racas/:id/pericias", periciasHandler.GetPericiasRaca)
 		api.GET("/origens/:id/pericias", periciasHandler.GetPericiasOrigem)
-		api.GET("/personagens/:id/pericias", periciasHandler.GetPericiasPersonagem)
-		api.POST("/personagens/:id/pericias", periciasHandler.UpdatePericiasPersonagem)
+		api.GET("/personagens/:id/pericias", middleware.CarregarPersonagem(), periciasHandler.GetPericiasPersonagem)
+		api.POST("/personagens/:id/pericias", middleware.CarregarPersonagem(), middleware.FichaEditavel(), periciasHandler.UpdatePericiasPersonagem)
 	}
 
 	return r
@@ -1,10 +1,13 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -35,10 +38,34 @@ type ServerConfig struct {
 	IdleTimeout  time.Duration
 }
 
+// AuthConfig estrutura as configurações de contas e sessões de login
+type AuthConfig struct {
+	SessionTTL        time.Duration
+	MinPasswordLength int
+	IPHashSalt        string
+	TransferCodeTTL   time.Duration
+	AdminEmails       []string
+}
+
+// VersoesConfig estrutura a retenção do histórico de versões dos personagens
+type VersoesConfig struct {
+	MaxPorPersonagem int           // 0 = sem limite de quantidade
+	Retencao         time.Duration // 0 = sem limite de idade
+}
+
+// LixeiraConfig estrutura a limpeza dos personagens excluídos
+type LixeiraConfig struct {
+	Retencao  time.Duration // 0 = nunca apaga de vez
+	Intervalo time.Duration
+}
+
 // Config contém todas as configurações da aplicação
 type Config struct {
 	Database DatabaseConfig
 	Server   ServerConfig
+	Auth     AuthConfig
+	Versoes  VersoesConfig
+	Lixeira  LixeiraConfig
 }
 
 var appConfig *Config
@@ -67,10 +94,16 @@ func loadConfig() (*Config, error) {
 	}
 
 	serverConfig := loadServerConfig()
+	authConfig := loadAuthConfig()
+	versoesConfig := loadVersoesConfig()
+	lixeiraConfig := loadLixeiraConfig()
 
 	return &Config{
 		Database: dbConfig,
 		Server:   serverConfig,
+		Auth:     authConfig,
+		Versoes:  versoesConfig,
+		Lixeira:  lixeiraConfig,
 	}, nil
 }
 
@@ -133,6 +166,67 @@ func loadServerConfig() ServerConfig {
 	}
 }
 
+// loadAuthConfig carrega configurações de autenticação
+func loadAuthConfig() AuthConfig {
+	sessionTTL, _ := strconv.Atoi(getEnvOrDefault("AUTH_SESSION_TTL_HOURS", "720"))
+	minPassword, _ := strconv.Atoi(getEnvOrDefault("AUTH_MIN_PASSWORD_LENGTH", "8"))
+	transferTTL, _ := strconv.Atoi(getEnvOrDefault("TRANSFER_CODE_TTL_MINUTES", "15"))
+
+	// Sem salt configurado, gera um por processo: os hashes de IP deixam de bater após reiniciar
+	salt := os.Getenv("IP_HASH_SALT")
+	if salt == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err == nil {
+			salt = hex.EncodeToString(b)
+		}
+		log.Println("IP_HASH_SALT não definido, usando salt aleatório para esta execução")
+	}
+
+	return AuthConfig{
+		SessionTTL:        time.Duration(sessionTTL) * time.Hour,
+		MinPasswordLength: minPassword,
+		IPHashSalt:        salt,
+		TransferCodeTTL:   time.Duration(transferTTL) * time.Minute,
+		AdminEmails:       parseList(os.Getenv("ADMIN_EMAILS")),
+	}
+}
+
+// loadVersoesConfig carrega a retenção do histórico de versões
+func loadVersoesConfig() VersoesConfig {
+	maximo, _ := strconv.Atoi(getEnvOrDefault("VERSOES_MAX_POR_PERSONAGEM", "50"))
+	dias, _ := strconv.Atoi(getEnvOrDefault("VERSOES_RETENCAO_DIAS", "0"))
+
+	return VersoesConfig{
+		MaxPorPersonagem: max(maximo, 0),
+		Retencao:         time.Duration(max(dias, 0)) * 24 * time.Hour,
+	}
+}
+
+// loadLixeiraConfig carrega o prazo e a frequência da limpeza da lixeira
+func loadLixeiraConfig() LixeiraConfig {
+	dias, _ := strconv.Atoi(getEnvOrDefault("LIXEIRA_RETENCAO_DIAS", "30"))
+	intervalo, _ := strconv.Atoi(getEnvOrDefault("LIXEIRA_INTERVALO_MINUTOS", "60"))
+	if intervalo < 1 {
+		intervalo = 60
+	}
+
+	return LixeiraConfig{
+		Retencao:  time.Duration(max(dias, 0)) * 24 * time.Hour,
+		Intervalo: time.Duration(intervalo) * time.Minute,
+	}
+}
+
+// parseList separa uma lista por vírgulas, em minúsculas e sem itens vazios
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // getEnvOrDefault retorna o valor da variável de ambiente ou o padrão
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -157,6 +251,30 @@ func GetServerConfig() ServerConfig {
 	return appConfig.Server
 }
 
+// GetAuthConfig retorna as configurações de autenticação
+func GetAuthConfig() AuthConfig {
+	if appConfig == nil {
+		log.Fatal("Configurações não foram carregadas. Chame config.Load() primeiro.")
+	}
+	return appConfig.Auth
+}
+
+// GetVersoesConfig retorna a retenção do histórico de versões
+func GetVersoesConfig() VersoesConfig {
+	if appConfig == nil {
+		log.Fatal("Configurações não foram carregadas. Chame config.Load() primeiro.")
+	}
+	return appConfig.Versoes
+}
+
+// GetLixeiraConfig retorna as configurações da lixeira de personagens
+func GetLixeiraConfig() LixeiraConfig {
+	if appConfig == nil {
+		log.Fatal("Configurações não foram carregadas. Chame config.Load() primeiro.")
+	}
+	return appConfig.Lixeira
+}
+
 // Get retorna o valor de uma variável de ambiente
 func Get(key string) string {
 	return os.Getenv(key)
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/middleware"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// prefixoApiChave identifica as chaves de API geradas pelo builder
+const prefixoApiChave = "t20_"
+
+// AdminHandler gerencia papéis de usuário, chaves de API e a auditoria do catálogo
+type AdminHandler struct {
+	*GenericService
+}
+
+func NewAdminHandler() *AdminHandler {
+	return &AdminHandler{
+		GenericService: NewGenericService(database.DB),
+	}
+}
+
+// ApiChaveRequest representa a criação de uma chave de API
+type ApiChaveRequest struct {
+	Nome    string   `json:"nome" binding:"required,min=1,max=100"`
+	Escopos []string `json:"escopos" binding:"required,min=1,max=10"`
+}
+
+// PapelRequest representa a mudança de papel de um usuário
+type PapelRequest struct {
+	Papel string `json:"papel" binding:"required,oneof=jogador admin"`
+}
+
+func (h *AdminHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	admin := rg.Group("/admin")
+	{
+		admin.GET("/auditoria", middleware.RequireEscopo(models.EscopoAuditoriaLeitura), h.GetAuditoria)
+
+		apenasAdmin := admin.Group("", middleware.RequireAdmin())
+		apenasAdmin.GET("/usuarios", h.GetUsuarios)
+		apenasAdmin.PUT("/usuarios/:id/papel", h.SetPapelUsuario)
+		apenasAdmin.GET("/api-chaves", h.GetApiChaves)
+		apenasAdmin.POST("/api-chaves", h.CreateApiChave)
+		apenasAdmin.DELETE("/api-chaves/:id", h.RevogarApiChave)
+	}
+}
+
+// GetUsuarios lista as contas e seus papéis
+func (h *AdminHandler) GetUsuarios(c *gin.Context) {
+	var usuarios []models.Usuario
+	if err := h.DB.Order("id").Find(&usuarios).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar usuários")
+		return
+	}
+
+	c.JSON(http.StatusOK, usuarios)
+}
+
+// SetPapelUsuario promove ou rebaixa um usuário. Um administrador não pode rebaixar a si
+// mesmo, para que sempre reste ao menos um.
+func (h *AdminHandler) SetPapelUsuario(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req PapelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	if usuarioID, _ := middleware.GetUsuarioID(c); usuarioID == id && req.Papel != models.PapelAdmin {
+		h.Response.BadRequest(c, "Não é possível remover o próprio papel de administrador")
+		return
+	}
+
+	var usuario models.Usuario
+	if err := h.DB.First(&usuario, id).Error; err != nil {
+		h.Response.NotFound(c, "Usuário não encontrado")
+		return
+	}
+
+	if err := h.DB.Model(&usuario).Update("papel", req.Papel).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao atualizar papel")
+		return
+	}
+
+	h.Response.Success(c, usuario)
+}
+
+// GetApiChaves lista as chaves de API (sem o segredo)
+func (h *AdminHandler) GetApiChaves(c *gin.Context) {
+	var chaves []models.ApiChave
+	if err := h.DB.Order("id").Find(&chaves).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar chaves de API")
+		return
+	}
+
+	c.JSON(http.StatusOK, chaves)
+}
+
+// CreateApiChave gera uma chave de API com os escopos pedidos. A chave só é exibida nesta
+// resposta; o banco guarda apenas o hash.
+func (h *AdminHandler) CreateApiChave(c *gin.Context) {
+	var req ApiChaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	escopos := uniqueStrings(req.Escopos)
+	for _, escopo := range escopos {
+		if !slices.Contains(models.EscoposValidos, escopo) {
+			h.Response.BadRequest(c, fmt.Sprintf("escopo inválido: %s (válidos: %s)", escopo, strings.Join(models.EscoposValidos, ", ")))
+			return
+		}
+	}
+	escoposJSON, err := json.Marshal(escopos)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao criar chave de API")
+		return
+	}
+
+	token, err := middleware.NovoToken()
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao criar chave de API")
+		return
+	}
+	chave := prefixoApiChave + token
+
+	usuarioID, _ := middleware.GetUsuarioID(c)
+	apiChave := models.ApiChave{
+		Nome:      strings.TrimSpace(req.Nome),
+		Prefixo:   chave[:len(prefixoApiChave)+8],
+		ChaveHash: middleware.HashToken(chave),
+		Escopos:   string(escoposJSON),
+		UsuarioID: &usuarioID,
+	}
+	if err := h.DB.Create(&apiChave).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao criar chave de API")
+		return
+	}
+
+	h.Response.Created(c, gin.H{
+		"api_chave": apiChave,
+		"chave":     chave,
+	})
+}
+
+// RevogarApiChave desativa uma chave de API; o registro fica para a auditoria
+func (h *AdminHandler) RevogarApiChave(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	result := h.DB.Model(&models.ApiChave{}).
+		Where("id = ? AND revogada_em IS NULL", id).
+		Update("revogada_em", time.Now())
+	if result.Error != nil {
+		h.Response.InternalError(c, "Erro ao revogar chave de API")
+		return
+	}
+	if result.RowsAffected == 0 {
+		h.Response.NotFound(c, "Chave de API não encontrada")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetAuditoria lista as escritas no catálogo, das mais recentes para as mais antigas
+// (?limite=, padrão 100; filtros opcionais ?usuario_id= e ?api_chave_id=)
+func (h *AdminHandler) GetAuditoria(c *gin.Context) {
+	limite := 100
+	if valor := c.Query("limite"); valor != "" {
+		n, err := strconv.Atoi(valor)
+		if err != nil || n < 1 || n > 1000 {
+			h.Response.BadRequest(c, "limite deve estar entre 1 e 1000")
+			return
+		}
+		limite = n
+	}
+
+	query := h.DB.Order("id DESC").Limit(limite)
+	if usuarioID := c.Query("usuario_id"); usuarioID != "" {
+		query = query.Where("usuario_id = ?", usuarioID)
+	}
+	if chaveID := c.Query("api_chave_id"); chaveID != "" {
+		query = query.Where("api_chave_id = ?", chaveID)
+	}
+
+	var registros []models.AuditoriaCatalogo
+	if err := query.Find(&registros).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar auditoria")
+		return
+	}
+
+	c.JSON(http.StatusOK, registros)
+}
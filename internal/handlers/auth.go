@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"tormenta20-builder/internal/config"
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/middleware"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// AuthHandler gerencia contas locais (e-mail + senha) e sessões de login
+type AuthHandler struct {
+	*GenericService
+}
+
+func NewAuthHandler() *AuthHandler {
+	return &AuthHandler{
+		GenericService: NewGenericService(database.DB),
+	}
+}
+
+// RegistroRequest representa a criação de uma conta
+type RegistroRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+	Nome  string `json:"nome" binding:"max=100"`
+	Senha string `json:"senha" binding:"required,max=72"`
+}
+
+// LoginRequest representa um login com e-mail e senha
+type LoginRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+	Senha string `json:"senha" binding:"required,max=72"`
+}
+
+// TrocaSenhaRequest representa a troca de senha do usuário logado
+type TrocaSenhaRequest struct {
+	SenhaAtual string `json:"senha_atual" binding:"required,max=72"`
+	NovaSenha  string `json:"nova_senha" binding:"required,max=72"`
+}
+
+func (h *AuthHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	auth := rg.Group("/auth")
+	{
+		auth.POST("/registrar", h.Registrar)
+		auth.POST("/login", h.Login)
+		auth.POST("/logout", middleware.RequireAuth(), h.Logout)
+		auth.GET("/me", middleware.RequireAuth(), h.Me)
+		auth.PUT("/senha", middleware.RequireAuth(), h.TrocarSenha)
+		auth.POST("/reivindicar", middleware.RequireAuth(), h.ReivindicarPersonagens)
+	}
+}
+
+// validarSenha aplica o tamanho mínimo configurado (bcrypt ignora além de 72 bytes)
+func validarSenha(senha string) error {
+	minimo := config.GetAuthConfig().MinPasswordLength
+	if len(senha) < minimo {
+		return fmt.Errorf("a senha deve ter pelo menos %d caracteres", minimo)
+	}
+	return nil
+}
+
+// normalizarEmail padroniza o e-mail para comparação (o índice único usa LOWER(email))
+func normalizarEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Registrar cria uma conta e já inicia uma sessão
+func (h *AuthHandler) Registrar(c *gin.Context) {
+	var req RegistroRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+	if err := validarSenha(req.Senha); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	email := normalizarEmail(req.Email)
+	var count int64
+	if err := h.DB.Model(&models.Usuario{}).Where("LOWER(email) = ?", email).Count(&count).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao criar conta")
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Já existe uma conta com este e-mail"})
+		return
+	}
+
+	senhaHash, err := bcrypt.GenerateFromPassword([]byte(req.Senha), bcrypt.DefaultCost)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao criar conta")
+		return
+	}
+
+	usuario := models.Usuario{
+		Email:     email,
+		Nome:      strings.TrimSpace(req.Nome),
+		SenhaHash: string(senhaHash),
+		Papel:     models.PapelJogador,
+	}
+	// Nunca vira admin no registro: o e-mail não é verificado, então qualquer um poderia
+	// cadastrar um endereço de ADMIN_EMAILS. A promoção acontece só no login.
+	if err := h.DB.Create(&usuario).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao criar conta")
+		return
+	}
+
+	token, sessao, err := h.criarSessao(c, usuario.ID)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao iniciar sessão")
+		return
+	}
+
+	h.Response.Created(c, gin.H{
+		"usuario":   usuario,
+		"token":     token,
+		"expira_em": sessao.ExpiraEm,
+	})
+}
+
+// Login valida e-mail e senha e inicia uma sessão. O token volta no corpo e no cookie.
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	var usuario models.Usuario
+	err := h.DB.Where("LOWER(email) = ?", normalizarEmail(req.Email)).First(&usuario).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		h.Response.InternalError(c, "Erro ao fazer login")
+		return
+	}
+	// Mesma resposta para e-mail inexistente e senha errada
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(usuario.SenhaHash), []byte(req.Senha)) != nil {
+		h.Response.Unauthorized(c, "E-mail ou senha inválidos")
+		return
+	}
+
+	// Contas já existentes com e-mail em ADMIN_EMAILS viram administradores no login
+	// (bootstrap do primeiro admin)
+	if usuario.Papel != models.PapelAdmin && slices.Contains(config.GetAuthConfig().AdminEmails, normalizarEmail(usuario.Email)) {
+		if err := h.DB.Model(&usuario).Update("papel", models.PapelAdmin).Error; err != nil {
+			h.Response.InternalError(c, "Erro ao fazer login")
+			return
+		}
+	}
+
+	// Aproveita o login para descartar sessões expiradas do usuário
+	h.DB.Where("usuario_id = ? AND expira_em <= ?", usuario.ID, time.Now()).Delete(&models.UsuarioSessao{})
+
+	token, sessao, err := h.criarSessao(c, usuario.ID)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao iniciar sessão")
+		return
+	}
+
+	h.Response.Success(c, gin.H{
+		"usuario":   usuario,
+		"token":     token,
+		"expira_em": sessao.ExpiraEm,
+	})
+}
+
+// Logout encerra a sessão atual
+func (h *AuthHandler) Logout(c *gin.Context) {
+	sessaoID, _ := middleware.GetSessaoID(c)
+	if err := h.DB.Delete(&models.UsuarioSessao{}, sessaoID).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao encerrar sessão")
+		return
+	}
+
+	middleware.SetAuthCookie(c, "", -1)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Me retorna o usuário logado
+func (h *AuthHandler) Me(c *gin.Context) {
+	usuarioID, _ := middleware.GetUsuarioID(c)
+
+	var usuario models.Usuario
+	if err := h.DB.First(&usuario, usuarioID).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar usuário")
+		return
+	}
+
+	h.Response.Success(c, usuario)
+}
+
+// TrocarSenha altera a senha e encerra as outras sessões do usuário
+func (h *AuthHandler) TrocarSenha(c *gin.Context) {
+	var req TrocaSenhaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+	if err := validarSenha(req.NovaSenha); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	usuarioID, _ := middleware.GetUsuarioID(c)
+	sessaoID, _ := middleware.GetSessaoID(c)
+
+	var usuario models.Usuario
+	if err := h.DB.First(&usuario, usuarioID).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar usuário")
+		return
+	}
+	if bcrypt.CompareHashAndPassword([]byte(usuario.SenhaHash), []byte(req.SenhaAtual)) != nil {
+		h.Response.BadRequest(c, "Senha atual incorreta")
+		return
+	}
+
+	senhaHash, err := bcrypt.GenerateFromPassword([]byte(req.NovaSenha), bcrypt.DefaultCost)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao trocar senha")
+		return
+	}
+
+	var encerradas int64
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&usuario).Update("senha_hash", string(senhaHash)).Error; err != nil {
+			return err
+		}
+		result := tx.Where("usuario_id = ? AND id <> ?", usuarioID, sessaoID).Delete(&models.UsuarioSessao{})
+		encerradas = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao trocar senha")
+		return
+	}
+
+	h.Response.Success(c, gin.H{
+		"message":            "Senha alterada",
+		"sessoes_encerradas": encerradas,
+	})
+}
+
+// ReivindicarPersonagens move para a conta os personagens anônimos da sessão atual
+// (cookie/header de sessão). Personagens que já pertencem a uma conta não são afetados.
+func (h *AuthHandler) ReivindicarPersonagens(c *gin.Context) {
+	usuarioID, _ := middleware.GetUsuarioID(c)
+	sessionID := middleware.GetUserSessionID(c)
+	if sessionID == "" {
+		h.Response.BadRequest(c, "Nenhuma sessão anônima para reivindicar")
+		return
+	}
+
+	var ids []uint
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Inclui os da lixeira, que continuam restauráveis depois de irem para a conta
+		if err := tx.Unscoped().Model(&models.Personagem{}).
+			Where("usuario_id IS NULL AND user_session_id = ?", sessionID).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Unscoped().Model(&models.Personagem{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"usuario_id": usuarioID, "created_by_type": "usuario"}).Error
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao reivindicar personagens")
+		return
+	}
+
+	h.Response.Success(c, gin.H{
+		"reivindicados":  len(ids),
+		"personagem_ids": ids,
+	})
+}
+
+// criarSessao gera um token, salva o hash dele e grava o cookie de login
+func (h *AuthHandler) criarSessao(c *gin.Context, usuarioID uint) (string, *models.UsuarioSessao, error) {
+	token, err := middleware.NovoToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	ttl := config.GetAuthConfig().SessionTTL
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	sessao := models.UsuarioSessao{
+		UsuarioID: usuarioID,
+		TokenHash: middleware.HashToken(token),
+		UserAgent: userAgent,
+		ExpiraEm:  time.Now().Add(ttl),
+	}
+	if err := h.DB.Create(&sessao).Error; err != nil {
+		return "", nil, err
+	}
+
+	middleware.SetAuthCookie(c, token, int(ttl.Seconds()))
+	return token, &sessao, nil
+}
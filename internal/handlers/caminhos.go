@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"tormenta20-builder/internal/models"
+)
+
+// resolverCaminho valida o caminho escolhido para a classe. Classes com caminhos exigem a
+// escolha a partir do nível do caminho; sem escolha nova, o caminho atual é mantido se
+// ainda pertencer à classe. Com exigir falso (ficha salva antes dos caminhos, sem troca de
+// classe ou nível), a falta de caminho é aceita.
+func (h *PersonagemHandler) resolverCaminho(classeID uint, nivel int, caminhoID, atual *uint, exigir bool) (*uint, error) {
+	var caminhos []models.ClasseCaminho
+	if err := h.DB.Where("classe_id = ?", classeID).Order("id").Find(&caminhos).Error; err != nil {
+		return nil, err
+	}
+
+	if caminhoID != nil {
+		for _, caminho := range caminhos {
+			if caminho.ID != *caminhoID {
+				continue
+			}
+			if nivel < caminho.NivelEscolha {
+				return nil, fmt.Errorf("o caminho %s só pode ser escolhido a partir do %dº nível", caminho.Nome, caminho.NivelEscolha)
+			}
+			return caminhoID, nil
+		}
+		return nil, fmt.Errorf("caminho com ID %d não pertence à classe escolhida", *caminhoID)
+	}
+
+	var disponiveis []string
+	for _, caminho := range caminhos {
+		if atual != nil && caminho.ID == *atual && nivel >= caminho.NivelEscolha {
+			return atual, nil
+		}
+		if nivel >= caminho.NivelEscolha {
+			disponiveis = append(disponiveis, fmt.Sprintf("%s (id %d)", caminho.Nome, caminho.ID))
+		}
+	}
+	if len(disponiveis) > 0 && exigir {
+		return nil, fmt.Errorf("escolha obrigatória do caminho da classe: %s", strings.Join(disponiveis, ", "))
+	}
+	return nil, nil
+}
+
+// aplicarCaminho carrega o caminho do personagem, remove as habilidades de outros caminhos
+// e calcula o atributo-chave e a CD das magias (10 + metade do nível + atributo-chave)
+func (h *PersonagemHandler) aplicarCaminho(personagem *models.Personagem, atributoMagia string) {
+	if personagem.CaminhoID != nil && (personagem.Caminho == nil || personagem.Caminho.ID != *personagem.CaminhoID) {
+		var caminho models.ClasseCaminho
+		if err := h.DB.First(&caminho, *personagem.CaminhoID).Error; err == nil {
+			personagem.Caminho = &caminho
+		}
+	}
+
+	if len(personagem.Classe.Habilidades) > 0 {
+		habilidades := make([]models.HabilidadeClasse, 0, len(personagem.Classe.Habilidades))
+		for _, habilidade := range personagem.Classe.Habilidades {
+			if habilidade.CaminhoID != nil && (personagem.CaminhoID == nil || *habilidade.CaminhoID != *personagem.CaminhoID) {
+				continue
+			}
+			habilidades = append(habilidades, habilidade)
+		}
+		personagem.Classe.Habilidades = habilidades
+	}
+
+	personagem.AtributoChave = atributoMagia
+	if personagem.Caminho != nil && personagem.Caminho.AtributoChave != "" {
+		personagem.AtributoChave = personagem.Caminho.AtributoChave
+	}
+
+	personagem.CDMagia = 0
+	if personagem.AtributoChave != "" {
+		personagem.CDMagia = 10 + personagem.Nivel/2 + h.valorAtributo(personagem, personagem.AtributoChave)
+	}
+}
@@ -24,21 +24,23 @@ func (h *ClasseHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	{
 		classes.GET("", h.GetAllClasses)
 		classes.GET("/:id", h.GetClasse)
-		classes.POST("", h.CreateClasse)
-		classes.PUT("/:id", h.UpdateClasse)
-		classes.DELETE("/:id", h.DeleteClasse)
-		classes.PATCH("/:id/stats", h.UpdateClasseStats) // Nova rota para atualizar apenas PV e PM
+
+		escrita := escritaCatalogo(classes)
+		escrita.POST("", h.CreateClasse)
+		escrita.PUT("/:id", h.UpdateClasse)
+		escrita.DELETE("/:id", h.DeleteClasse)
+		escrita.PATCH("/:id/stats", h.UpdateClasseStats) // Nova rota para atualizar apenas PV e PM
 	}
 }
 
 func (h *ClasseHandler) GetAllClasses(c *gin.Context) {
 	var classes []models.Classe
-	h.GetAll(c, &classes, "Habilidades")
+	h.GetAll(c, &classes, "Habilidades", "Caminhos")
 }
 
 func (h *ClasseHandler) GetClasse(c *gin.Context) {
 	var classe models.Classe
-	h.GetByID(c, &classe, "Classe não encontrada", "Habilidades")
+	h.GetByID(c, &classe, "Classe não encontrada", "Habilidades", "KitItens", "Caminhos")
 }
 
 func (h *ClasseHandler) CreateClasse(c *gin.Context) {
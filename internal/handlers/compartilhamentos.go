@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/middleware"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxCompartilhamentosAtivos limita os links ativos de um mesmo personagem
+const maxCompartilhamentosAtivos = 20
+
+var errCompartilhamentoInvalido = errors.New("link de compartilhamento inválido, revogado ou expirado")
+
+// CompartilharRequest representa a criação de um link público de leitura
+type CompartilharRequest struct {
+	Ocultar       []string `json:"ocultar" binding:"max=10"`
+	ExpiraEmHoras int      `json:"expira_em_horas" binding:"omitempty,min=1,max=8760"` // 0 = não expira
+}
+
+// GetCompartilhamentos lista os links de compartilhamento do personagem, inclusive os revogados
+func (h *PersonagemHandler) GetCompartilhamentos(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var compartilhamentos []models.PersonagemCompartilhamento
+	if err := database.DB.Where("personagem_id = ?", id).Order("id").Find(&compartilhamentos).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar compartilhamentos")
+		return
+	}
+
+	c.JSON(http.StatusOK, compartilhamentos)
+}
+
+// Compartilhar cria um link público de leitura para o personagem. O token só é exibido
+// nesta resposta; o banco guarda apenas o hash.
+func (h *PersonagemHandler) Compartilhar(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req CompartilharRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	ocultar := uniqueStrings(req.Ocultar)
+	for _, campo := range ocultar {
+		if !slices.Contains(models.CamposOcultaveis, campo) {
+			h.Response.BadRequest(c, fmt.Sprintf("campo inválido em ocultar: %s (válidos: %s)", campo, strings.Join(models.CamposOcultaveis, ", ")))
+			return
+		}
+	}
+	if ocultar == nil {
+		ocultar = []string{}
+	}
+	ocultarJSON, err := json.Marshal(ocultar)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao criar compartilhamento")
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+	if !middleware.EhDono(c, personagem) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Apenas o dono pode compartilhar o personagem"})
+		return
+	}
+
+	agora := time.Now()
+	var ativos int64
+	if err := database.DB.Model(&models.PersonagemCompartilhamento{}).
+		Where("personagem_id = ? AND revogado_em IS NULL AND (expira_em IS NULL OR expira_em > ?)", id, agora).
+		Count(&ativos).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao criar compartilhamento")
+		return
+	}
+	if ativos >= maxCompartilhamentosAtivos {
+		h.Response.BadRequest(c, fmt.Sprintf("o personagem já tem %d links ativos; revogue algum antes de criar outro", maxCompartilhamentosAtivos))
+		return
+	}
+
+	token, err := middleware.NovoToken()
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao criar compartilhamento")
+		return
+	}
+
+	compartilhamento := models.PersonagemCompartilhamento{
+		PersonagemID: id,
+		TokenHash:    middleware.HashToken(token),
+		Prefixo:      token[:8],
+		Ocultar:      string(ocultarJSON),
+	}
+	if req.ExpiraEmHoras > 0 {
+		expira := agora.Add(time.Duration(req.ExpiraEmHoras) * time.Hour)
+		compartilhamento.ExpiraEm = &expira
+	}
+	if err := database.DB.Create(&compartilhamento).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao criar compartilhamento")
+		return
+	}
+
+	h.Response.Created(c, gin.H{
+		"compartilhamento": compartilhamento,
+		"token":            token,
+		"url":              "/api/v1/compartilhado/" + token,
+	})
+}
+
+// RevogarCompartilhamento desativa um link; quem tiver o token perde o acesso imediatamente
+func (h *PersonagemHandler) RevogarCompartilhamento(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+	compartilhamentoID, err := strconv.ParseUint(c.Param("compartilhamento_id"), 10, 32)
+	if err != nil {
+		h.Response.BadRequest(c, "ID do compartilhamento inválido")
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+	if !middleware.EhDono(c, personagem) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Apenas o dono pode revogar o compartilhamento"})
+		return
+	}
+
+	result := database.DB.Model(&models.PersonagemCompartilhamento{}).
+		Where("id = ? AND personagem_id = ? AND revogado_em IS NULL", compartilhamentoID, id).
+		Update("revogado_em", time.Now())
+	if result.Error != nil {
+		h.Response.InternalError(c, "Erro ao revogar compartilhamento")
+		return
+	}
+	if result.RowsAffected == 0 {
+		h.Response.NotFound(c, "Compartilhamento não encontrado")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetCompartilhado retorna a ficha completa de um personagem compartilhado, somente leitura
+func (h *PersonagemHandler) GetCompartilhado(c *gin.Context) {
+	compartilhamento, err := h.findCompartilhamento(c.Param("token"))
+	if err != nil {
+		h.responderErroCompartilhamento(c, err)
+		return
+	}
+
+	var personagem models.Personagem
+	if err := database.DB.Scopes(preloadFicha).First(&personagem, compartilhamento.PersonagemID).Error; err != nil {
+		h.responderErroCompartilhamento(c, err)
+		return
+	}
+
+	h.loadPersonagemPericias(&personagem)
+	h.calculatePersonagemStats(&personagem)
+	ocultarCamposPrivados(&personagem, compartilhamento.CamposOcultos())
+
+	h.Response.Success(c, personagem)
+}
+
+// ExportCompartilhadoPDF exporta em PDF a ficha de um personagem compartilhado
+// (mesmos parâmetros de query de /personagens/:id/export-pdf)
+func (h *PersonagemHandler) ExportCompartilhadoPDF(c *gin.Context) {
+	compartilhamento, err := h.findCompartilhamento(c.Param("token"))
+	if err != nil {
+		h.responderErroCompartilhamento(c, err)
+		return
+	}
+
+	var personagem models.Personagem
+	if err := database.DB.First(&personagem, compartilhamento.PersonagemID).Error; err != nil {
+		h.responderErroCompartilhamento(c, err)
+		return
+	}
+
+	h.loadPersonagemCompleteData(&personagem)
+	h.calculatePersonagemStats(&personagem)
+	ocultarCamposPrivados(&personagem, compartilhamento.CamposOcultos())
+
+	h.enviarPDF(c, &personagem)
+}
+
+// findCompartilhamento resolve um token ativo e contabiliza o acesso. Tokens revogados ou
+// expirados são tratados como inexistentes.
+func (h *PersonagemHandler) findCompartilhamento(token string) (*models.PersonagemCompartilhamento, error) {
+	if len(token) != 64 {
+		return nil, errCompartilhamentoInvalido
+	}
+
+	var compartilhamento models.PersonagemCompartilhamento
+	if err := database.DB.Where("token_hash = ?", middleware.HashToken(token)).First(&compartilhamento).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errCompartilhamentoInvalido
+		}
+		return nil, err
+	}
+
+	agora := time.Now()
+	if !compartilhamento.Ativo(agora) {
+		return nil, errCompartilhamentoInvalido
+	}
+
+	database.DB.Model(&compartilhamento).Updates(map[string]interface{}{
+		"acessos":          gorm.Expr("acessos + 1"),
+		"ultimo_acesso_em": agora,
+	})
+	return &compartilhamento, nil
+}
+
+// responderErroCompartilhamento responde 404 para links inválidos sem revelar o motivo
+func (h *PersonagemHandler) responderErroCompartilhamento(c *gin.Context, err error) {
+	if errors.Is(err, errCompartilhamentoInvalido) || errors.Is(err, gorm.ErrRecordNotFound) {
+		h.Response.NotFound(c, "Personagem compartilhado não encontrado")
+		return
+	}
+	h.Response.InternalError(c, "Erro ao buscar personagem compartilhado")
+}
+
+// ocultarCamposPrivados remove da ficha compartilhada a identificação do dono e os campos
+// que ele escolheu esconder
+func ocultarCamposPrivados(personagem *models.Personagem, campos []string) {
+	personagem.UsuarioID = nil
+	personagem.UserSessionID = nil
+	personagem.CreatedByType = ""
+
+	for _, campo := range campos {
+		switch campo {
+		case models.CampoAnotacoes:
+			personagem.Anotacoes = ""
+		case models.CampoHistorico:
+			personagem.Historico = ""
+		case models.CampoDinheiro:
+			personagem.Dinheiro = 0
+		}
+	}
+}
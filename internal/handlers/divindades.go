@@ -22,9 +22,11 @@ func (h *DivindadeHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	{
 		divindades.GET("", h.GetAllDivindades)
 		divindades.GET("/:id", h.GetDivindade)
-		divindades.POST("", h.CreateDivindade)
-		divindades.PUT("/:id", h.UpdateDivindade)
-		divindades.DELETE("/:id", h.DeleteDivindade)
+
+		escrita := escritaCatalogo(divindades)
+		escrita.POST("", h.CreateDivindade)
+		escrita.PUT("/:id", h.UpdateDivindade)
+		escrita.DELETE("/:id", h.DeleteDivindade)
 	}
 }
 
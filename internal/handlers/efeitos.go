@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxEfeitosPorPersonagem limita quantos efeitos ativos um personagem pode ter ao mesmo tempo
+const maxEfeitosPorPersonagem = 50
+
+// EfeitoRequest representa a aplicação de um efeito temporário a um personagem
+type EfeitoRequest struct {
+	Nome        string `json:"nome" binding:"required,min=1,max=100"`
+	Fonte       string `json:"fonte" binding:"max=100"`
+	Alvo        string `json:"alvo" binding:"required,oneof=defesa ataque dano pericia pv pm"`
+	Detalhe     string `json:"detalhe" binding:"max=100"`
+	Modificador int    `json:"modificador" binding:"required,min=-50,max=50"`
+	Unidade     string `json:"unidade" binding:"required,oneof=rodada cena dia"`
+	Duracao     int    `json:"duracao" binding:"omitempty,min=1,max=1000"`
+}
+
+// AvancarTempoRequest representa a passagem do tempo: próxima rodada, fim de cena ou novo dia
+type AvancarTempoRequest struct {
+	Unidade    string `json:"unidade" binding:"required,oneof=rodada cena dia"`
+	Quantidade int    `json:"quantidade" binding:"omitempty,min=1,max=1000"`
+}
+
+// AvancarTempoGrupoRequest avança o tempo para vários personagens (ex: rodadas de um combate)
+type AvancarTempoGrupoRequest struct {
+	PersonagemIDs []uint `json:"personagem_ids" binding:"required,min=1,max=50"`
+	Unidade       string `json:"unidade" binding:"required,oneof=rodada cena dia"`
+	Quantidade    int    `json:"quantidade" binding:"omitempty,min=1,max=1000"`
+}
+
+// carregarEfeitos busca os efeitos ativos do personagem se ainda não foram carregados
+func (h *PersonagemHandler) carregarEfeitos(personagem *models.Personagem) {
+	if personagem.Efeitos != nil || personagem.ID == 0 {
+		return
+	}
+	var efeitos []models.PersonagemEfeito
+	if err := h.DB.Where("personagem_id = ? AND restante > 0", personagem.ID).Order("id").Find(&efeitos).Error; err == nil {
+		personagem.Efeitos = efeitos
+	}
+}
+
+// GetEfeitos lista os efeitos ativos de um personagem e o bônus total por alvo
+func (h *PersonagemHandler) GetEfeitos(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var efeitos []models.PersonagemEfeito
+	if err := database.DB.Where("personagem_id = ? AND restante > 0", id).Order("id").Find(&efeitos).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar efeitos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"efeitos": efeitos,
+		"bonus":   models.CalcularBonusEfeitos(efeitos),
+	})
+}
+
+// CreateEfeito aplica um efeito temporário ao personagem (duração padrão 1 na unidade escolhida)
+func (h *PersonagemHandler) CreateEfeito(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req EfeitoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+	if req.Duracao == 0 {
+		req.Duracao = 1
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	detalhe := strings.TrimSpace(req.Detalhe)
+	if req.Alvo != models.AlvoEfeitoPericia {
+		detalhe = ""
+	} else if detalhe != "" {
+		var count int64
+		if err := database.DB.Model(&models.Pericia{}).Where("nome = ?", detalhe).Count(&count).Error; err != nil {
+			h.Response.InternalError(c, "Erro ao aplicar efeito")
+			return
+		}
+		if count == 0 {
+			h.Response.BadRequest(c, fmt.Sprintf("perícia inválida para o efeito: %s", detalhe))
+			return
+		}
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.PersonagemEfeito{}).Where("personagem_id = ?", id).Count(&count).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao aplicar efeito")
+		return
+	}
+	if count >= maxEfeitosPorPersonagem {
+		h.Response.BadRequest(c, fmt.Sprintf("máximo de %d efeitos ativos por personagem", maxEfeitosPorPersonagem))
+		return
+	}
+
+	efeito := models.PersonagemEfeito{
+		PersonagemID: uint(id),
+		Nome:         strings.TrimSpace(req.Nome),
+		Fonte:        req.Fonte,
+		Alvo:         req.Alvo,
+		Detalhe:      detalhe,
+		Modificador:  req.Modificador,
+		Unidade:      req.Unidade,
+		Restante:     req.Duracao,
+	}
+	if err := database.DB.Create(&efeito).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao aplicar efeito")
+		return
+	}
+
+	h.Response.Created(c, efeito)
+}
+
+// DeleteEfeito encerra um efeito antes do fim da duração (dissipado, cancelado...)
+func (h *PersonagemHandler) DeleteEfeito(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+	efeitoID, err := strconv.ParseUint(c.Param("efeito_id"), 10, 32)
+	if err != nil {
+		h.Response.BadRequest(c, "ID do efeito inválido")
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	result := database.DB.Where("id = ? AND personagem_id = ?", efeitoID, id).Delete(&models.PersonagemEfeito{})
+	if result.Error != nil {
+		h.Response.InternalError(c, "Erro ao remover efeito")
+		return
+	}
+	if result.RowsAffected == 0 {
+		h.Response.NotFound(c, "Efeito não encontrado")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// AvancarTempo passa rodadas, cenas ou dias para o personagem: reduz a duração dos efeitos,
+// remove os expirados e, no fim da cena ou em um novo dia, reinicia os recursos limitados
+func (h *PersonagemHandler) AvancarTempo(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req AvancarTempoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+	if req.Quantidade == 0 {
+		req.Quantidade = 1
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var resultado gin.H
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		resultado, err = avancarTempo(tx, personagem, req.Unidade, req.Quantidade)
+		return err
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao avançar o tempo")
+		return
+	}
+
+	resultado["unidade"] = req.Unidade
+	resultado["quantidade"] = req.Quantidade
+	h.Response.Success(c, resultado)
+}
+
+// AvancarTempoGrupo avança o tempo para uma lista de personagens, como o mestre passando
+// as rodadas de um combate. Todos precisam pertencer ao usuário.
+func (h *PersonagemHandler) AvancarTempoGrupo(c *gin.Context) {
+	var req AvancarTempoGrupoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+	if req.Quantidade == 0 {
+		req.Quantidade = 1
+	}
+
+	personagens := make([]*models.Personagem, 0, len(req.PersonagemIDs))
+	vistos := make(map[uint]bool)
+	for _, personagemID := range req.PersonagemIDs {
+		if vistos[personagemID] {
+			continue
+		}
+		vistos[personagemID] = true
+
+		personagem, err := h.findPersonagemByUser(c, int(personagemID))
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				h.Response.NotFound(c, "Personagem não encontrado")
+			} else {
+				h.Response.InternalError(c, "Erro ao buscar personagem")
+			}
+			return
+		}
+		personagens = append(personagens, personagem)
+	}
+
+	resultados := make([]gin.H, 0, len(personagens))
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, personagem := range personagens {
+			resultado, err := avancarTempo(tx, personagem, req.Unidade, req.Quantidade)
+			if err != nil {
+				return err
+			}
+			resultado["nome"] = personagem.Nome
+			resultados = append(resultados, resultado)
+		}
+		return nil
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao avançar o tempo")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"unidade":     req.Unidade,
+		"quantidade":  req.Quantidade,
+		"personagens": resultados,
+	})
+}
+
+// avancarTempo reduz a duração dos efeitos na unidade, encerra os de unidades menores
+// (o fim da cena encerra efeitos de rodada) e remove os expirados
+func avancarTempo(tx *gorm.DB, personagem *models.Personagem, unidade string, quantidade int) (gin.H, error) {
+	var expirados []models.PersonagemEfeito
+	if err := tx.Where("personagem_id = ? AND (unidade IN ? OR (unidade = ? AND restante <= ?))",
+		personagem.ID, models.UnidadesEncerradas(unidade), unidade, quantidade).
+		Order("id").Find(&expirados).Error; err != nil {
+		return nil, err
+	}
+
+	if len(expirados) > 0 {
+		ids := make([]uint, len(expirados))
+		for i, efeito := range expirados {
+			ids[i] = efeito.ID
+		}
+		if err := tx.Delete(&models.PersonagemEfeito{}, ids).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Model(&models.PersonagemEfeito{}).
+		Where("personagem_id = ? AND unidade = ?", personagem.ID, unidade).
+		Update("restante", gorm.Expr("restante - ?", quantidade)).Error; err != nil {
+		return nil, err
+	}
+
+	// Recursos por cena voltam no fim da cena; um novo dia renova também os diários
+	var recursosReiniciados int64
+	if unidade != models.UnidadeRodada {
+		var err error
+		recursosReiniciados, err = reiniciarRecursos(tx, personagem.ID, unidade, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var ativos []models.PersonagemEfeito
+	if err := tx.Where("personagem_id = ?", personagem.ID).Order("id").Find(&ativos).Error; err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"personagem_id":        personagem.ID,
+		"efeitos_expirados":    expirados,
+		"efeitos_ativos":       ativos,
+		"recursos_reiniciados": recursosReiniciados,
+	}, nil
+}
@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/middleware"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Ações do fluxo de aprovação
+const (
+	acaoEnviar      = "enviar"
+	acaoAprovar     = "aprovar"
+	acaoRejeitar    = "rejeitar"
+	acaoBloquear    = "bloquear"
+	acaoDesbloquear = "desbloquear"
+)
+
+// transicao descreve de quais estados uma ação parte, para qual leva e quem pode executá-la
+type transicao struct {
+	de    []string
+	para  string
+	papel string
+}
+
+var transicoes = map[string]transicao{
+	acaoEnviar:      {[]string{models.EstadoRascunho}, models.EstadoEnviado, models.AutorDono},
+	acaoAprovar:     {[]string{models.EstadoEnviado}, models.EstadoAprovado, models.AutorMestre},
+	acaoRejeitar:    {[]string{models.EstadoEnviado}, models.EstadoRascunho, models.AutorMestre},
+	acaoBloquear:    {[]string{models.EstadoAprovado}, models.EstadoBloqueado, models.AutorMestre},
+	acaoDesbloquear: {[]string{models.EstadoAprovado, models.EstadoBloqueado}, models.EstadoRascunho, models.AutorMestre},
+}
+
+var errSemMesa = errors.New("o personagem não está em uma mesa")
+
+// errTransicaoInvalida informa que a ação não vale para o estado atual da ficha
+type errTransicaoInvalida struct {
+	acao, estado string
+}
+
+func (e errTransicaoInvalida) Error() string {
+	return fmt.Sprintf("não é possível %s uma ficha no estado %s", e.acao, e.estado)
+}
+
+// TransicaoRequest traz o comentário do mestre (obrigatório ao rejeitar)
+type TransicaoRequest struct {
+	Comentario string `json:"comentario" binding:"max=2000"`
+}
+
+// SubirNivelRequest permite escolher o caminho da classe quando o novo nível exige
+type SubirNivelRequest struct {
+	CaminhoID *uint `json:"caminho_id"`
+}
+
+// mudarEstado aplica uma ação do fluxo de aprovação com a linha do personagem travada e
+// registra a transição no histórico
+func mudarEstado(tx *gorm.DB, personagemID uint, acao string, usuarioID *uint, comentario string) (*models.Personagem, error) {
+	t := transicoes[acao]
+
+	var personagem models.Personagem
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&personagem, personagemID).Error; err != nil {
+		return nil, err
+	}
+	if personagem.MesaID == nil {
+		return nil, errSemMesa
+	}
+
+	valido := false
+	for _, de := range t.de {
+		if personagem.Estado == de {
+			valido = true
+		}
+	}
+	if !valido {
+		return nil, errTransicaoInvalida{acao, personagem.Estado}
+	}
+
+	historico := models.PersonagemEstado{
+		PersonagemID: personagem.ID,
+		De:           personagem.Estado,
+		Para:         t.para,
+		UsuarioID:    usuarioID,
+		Papel:        t.papel,
+		Comentario:   comentario,
+	}
+	if err := tx.Create(&historico).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&personagem).Update("estado", t.para).Error; err != nil {
+		return nil, err
+	}
+
+	return &personagem, nil
+}
+
+// sairDaMesa move personagens para outra mesa (ou nenhuma) e os devolve a rascunho: a
+// aprovação valia apenas para a mesa anterior
+func sairDaMesa(tx *gorm.DB, personagemIDs []uint, mesaID *uint, usuarioID *uint) error {
+	if len(personagemIDs) == 0 {
+		return nil
+	}
+
+	var personagens []models.Personagem
+	if err := tx.Select("id", "estado").Where("id IN ? AND estado <> ?", personagemIDs, models.EstadoRascunho).
+		Find(&personagens).Error; err != nil {
+		return err
+	}
+	for _, p := range personagens {
+		historico := models.PersonagemEstado{
+			PersonagemID: p.ID,
+			De:           p.Estado,
+			Para:         models.EstadoRascunho,
+			UsuarioID:    usuarioID,
+			Papel:        models.AutorSistema,
+			Comentario:   "Personagem saiu da mesa",
+		}
+		if err := tx.Create(&historico).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&models.Personagem{}).Where("id IN ?", personagemIDs).
+		Updates(map[string]interface{}{"mesa_id": mesaID, "estado": models.EstadoRascunho}).Error
+}
+
+// responderErroEstado traduz os erros do fluxo de aprovação em respostas HTTP
+func (s *GenericService) responderErroEstado(c *gin.Context, err error) {
+	var transicaoInvalida errTransicaoInvalida
+	switch {
+	case errors.As(err, &transicaoInvalida):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errSemMesa):
+		s.Response.BadRequest(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		s.Response.NotFound(c, "Personagem não encontrado")
+	default:
+		s.Response.InternalError(c, "Erro ao atualizar estado da ficha")
+	}
+}
+
+// EnviarParaAprovacao envia a ficha para o mestre da mesa aprovar. A ficha fica travada
+// até o mestre aprovar ou rejeitar.
+func (h *PersonagemHandler) EnviarParaAprovacao(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		h.responderErroEstado(c, err)
+		return
+	}
+	if !middleware.EhDono(c, personagem) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Apenas o dono pode enviar a ficha para aprovação"})
+		return
+	}
+
+	// A ficha enviada precisa respeitar as regras da casa vigentes
+	regras, err := carregarRegras(h.DB, personagem.MesaID)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao buscar regras da mesa")
+		return
+	}
+	if personagem.MesaID != nil {
+		if err := validarEscolhasMesa(h.DB, &regras, personagem.RacaID, personagem.ClasseID, personagem.OrigemID, personagem.DivindadeID); err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	var req TransicaoRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	var atualizado *models.Personagem
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		atualizado, err = mudarEstado(tx, personagem.ID, acaoEnviar, personagem.UsuarioID, req.Comentario)
+		return err
+	})
+	if err != nil {
+		h.responderErroEstado(c, err)
+		return
+	}
+
+	h.Response.Success(c, gin.H{
+		"personagem_id": atualizado.ID,
+		"estado":        models.EstadoEnviado,
+	})
+}
+
+// GetHistoricoEstados lista as transições de estado da ficha, da mais antiga à mais recente
+func (h *PersonagemHandler) GetHistoricoEstados(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		h.responderErroEstado(c, err)
+		return
+	}
+
+	var historico []models.PersonagemEstado
+	if err := database.DB.Where("personagem_id = ?", id).Order("id").Find(&historico).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar histórico")
+		return
+	}
+
+	h.Response.Success(c, gin.H{
+		"estado":    personagem.Estado,
+		"historico": historico,
+	})
+}
+
+// SubirNivel avança um nível quando o XP permite. É um fluxo de jogo: funciona mesmo com a
+// ficha bloqueada pelo mestre.
+func (h *PersonagemHandler) SubirNivel(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req SubirNivelRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		h.responderErroEstado(c, err)
+		return
+	}
+
+	h.calculateProgressao(personagem)
+	if !personagem.PodeSubirNivel {
+		h.Response.BadRequest(c, fmt.Sprintf("XP insuficiente: o próximo nível exige %d XP", personagem.XPProximoNivel))
+		return
+	}
+
+	nivel := personagem.Nivel + 1
+	caminhoID, err := h.resolverCaminho(personagem.ClasseID, nivel, req.CaminhoID, personagem.CaminhoID, true)
+	if err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	// A condição no nível atual evita subir dois níveis com requisições simultâneas
+	result := database.DB.Model(&models.Personagem{}).
+		Where("id = ? AND nivel = ?", personagem.ID, personagem.Nivel).
+		Updates(map[string]interface{}{"nivel": nivel, "caminho_id": caminhoID})
+	if result.Error != nil {
+		h.Response.InternalError(c, "Erro ao subir de nível")
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "O nível do personagem mudou; tente novamente"})
+		return
+	}
+
+	personagem.Nivel = nivel
+	personagem.CaminhoID = caminhoID
+	personagem.Caminho = nil
+	h.calculatePersonagemStats(personagem)
+
+	resposta := h.progressaoResponse(personagem)
+	resposta["pv_total"] = personagem.PVTotal
+	resposta["pm_total"] = personagem.PMTotal
+	c.JSON(http.StatusOK, resposta)
+}
+
+// transicaoMestre aplica uma ação do mestre a um personagem da mesa
+func (h *MesaHandler) transicaoMestre(c *gin.Context, acao string, exigirComentario bool) {
+	mesa, membro, ok := h.findMesaMembro(c, true)
+	if !ok {
+		return
+	}
+	personagemID, err := strconv.ParseUint(c.Param("personagem_id"), 10, 32)
+	if err != nil {
+		h.Response.BadRequest(c, "ID do personagem inválido")
+		return
+	}
+
+	var req TransicaoRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+	}
+	if exigirComentario && req.Comentario == "" {
+		h.Response.BadRequest(c, "Informe um comentário explicando o que precisa mudar")
+		return
+	}
+
+	var personagem *models.Personagem
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Personagem{}).Where("id = ? AND mesa_id = ?", personagemID, mesa.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var err error
+		personagem, err = mudarEstado(tx, uint(personagemID), acao, &membro.UsuarioID, req.Comentario)
+		return err
+	})
+	if err != nil {
+		h.responderErroEstado(c, err)
+		return
+	}
+
+	h.Response.Success(c, gin.H{
+		"personagem_id": personagem.ID,
+		"estado":        transicoes[acao].para,
+		"comentario":    req.Comentario,
+	})
+}
+
+// AprovarPersonagem aprova uma ficha enviada
+func (h *MesaHandler) AprovarPersonagem(c *gin.Context) {
+	h.transicaoMestre(c, acaoAprovar, false)
+}
+
+// RejeitarPersonagem devolve uma ficha enviada para rascunho, com o comentário do mestre
+func (h *MesaHandler) RejeitarPersonagem(c *gin.Context) {
+	h.transicaoMestre(c, acaoRejeitar, true)
+}
+
+// BloquearPersonagem trava uma ficha aprovada: só fluxos de jogo continuam liberados
+func (h *MesaHandler) BloquearPersonagem(c *gin.Context) {
+	h.transicaoMestre(c, acaoBloquear, false)
+}
+
+// DesbloquearPersonagem devolve uma ficha aprovada ou bloqueada para rascunho, para ajustes e
+// novo envio
+func (h *MesaHandler) DesbloquearPersonagem(c *gin.Context) {
+	h.transicaoMestre(c, acaoDesbloquear, false)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ExperienciaRequest representa uma concessão de XP
+type ExperienciaRequest struct {
+	XP     int    `json:"xp" binding:"required,min=1,max=1000000"`
+	Motivo string `json:"motivo" binding:"max=200"`
+}
+
+// ExperienciaGrupoRequest representa uma concessão de XP para vários personagens
+type ExperienciaGrupoRequest struct {
+	PersonagemIDs []uint `json:"personagem_ids" binding:"required,min=1,max=50"`
+	XP            int    `json:"xp" binding:"required,min=1,max=1000000"`
+	Motivo        string `json:"motivo" binding:"max=200"`
+}
+
+// calculateProgressao preenche patamar, XP do próximo nível e se o personagem pode subir de nível
+func (h *PersonagemHandler) calculateProgressao(personagem *models.Personagem) {
+	personagem.Patamar = models.PatamarPorNivel(personagem.Nivel)
+
+	if personagem.Nivel >= models.NivelMaximo {
+		personagem.XPProximoNivel = 0
+		personagem.PodeSubirNivel = false
+		return
+	}
+
+	personagem.XPProximoNivel = models.XPParaNivel(personagem.Nivel + 1)
+	personagem.PodeSubirNivel = personagem.Experiencia >= personagem.XPProximoNivel
+}
+
+// progressaoResponse resume a progressão de um personagem após receber XP
+func (h *PersonagemHandler) progressaoResponse(personagem *models.Personagem) gin.H {
+	h.calculateProgressao(personagem)
+
+	return gin.H{
+		"personagem_id":    personagem.ID,
+		"nome":             personagem.Nome,
+		"nivel":            personagem.Nivel,
+		"experiencia":      personagem.Experiencia,
+		"patamar":          personagem.Patamar,
+		"xp_proximo_nivel": personagem.XPProximoNivel,
+		"pode_subir_nivel": personagem.PodeSubirNivel,
+		"nivel_por_xp":     models.NivelPorXP(personagem.Experiencia),
+	}
+}
+
+// ConcederExperiencia adiciona XP a um personagem do usuário
+func (h *PersonagemHandler) ConcederExperiencia(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req ExperienciaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	// Incremento atômico para não perder XP concedido em paralelo
+	if err := database.DB.Model(personagem).
+		Update("experiencia", gorm.Expr("experiencia + ?", req.XP)).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao conceder experiência")
+		return
+	}
+
+	if err := database.DB.First(personagem, personagem.ID).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao carregar personagem")
+		return
+	}
+
+	resposta := h.progressaoResponse(personagem)
+	resposta["xp_concedido"] = req.XP
+	resposta["motivo"] = req.Motivo
+
+	c.JSON(http.StatusOK, resposta)
+}
+
+// ConcederExperienciaGrupo adiciona a mesma quantidade de XP a uma lista de personagens.
+// Todos os personagens precisam pertencer ao usuário, senão nada é concedido.
+func (h *PersonagemHandler) ConcederExperienciaGrupo(c *gin.Context) {
+	var req ExperienciaGrupoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	personagens := make([]*models.Personagem, 0, len(req.PersonagemIDs))
+	vistos := make(map[uint]bool)
+	for _, personagemID := range req.PersonagemIDs {
+		if vistos[personagemID] {
+			continue
+		}
+		vistos[personagemID] = true
+
+		personagem, err := h.findPersonagemByUser(c, int(personagemID))
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				h.Response.NotFound(c, "Personagem não encontrado")
+			} else {
+				h.Response.InternalError(c, "Erro ao buscar personagem")
+			}
+			return
+		}
+		personagens = append(personagens, personagem)
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, personagem := range personagens {
+			if err := tx.Model(personagem).
+				Update("experiencia", gorm.Expr("experiencia + ?", req.XP)).Error; err != nil {
+				return err
+			}
+			if err := tx.First(personagem, personagem.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao conceder experiência")
+		return
+	}
+
+	resultados := make([]gin.H, 0, len(personagens))
+	for _, personagem := range personagens {
+		resultados = append(resultados, h.progressaoResponse(personagem))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"xp_concedido": req.XP,
+		"motivo":       req.Motivo,
+		"personagens":  resultados,
+	})
+}
@@ -0,0 +1,454 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// fracaoMateriaPrima: fabricar um item custa 1/3 do preço em matéria-prima
+	fracaoMateriaPrima = 1.0 / 3.0
+	// CDs de Ofício: itens simples (gerais, vestuário, ferramentas, comida) e alquímicos
+	cdFabricacaoSimples   = 15
+	cdFabricacaoAlquimico = 20
+	// Tempo de trabalho: consumíveis levam 1 dia, demais itens 1 semana
+	diasFabricacaoConsumivel = 1
+	diasFabricacaoOutros     = 7
+	// margemPerdaMateriaPrima: falhar por essa diferença ou mais desperdiça a matéria-prima
+	margemPerdaMateriaPrima = 5
+	// Pratos para cinco pessoas: -5 no teste e matéria-prima de cinco porções
+	penalidadeParaCinco = 5
+	porcoesParaCinco    = 5
+	// Frutos do Trabalho: até 5 itens gerais de até T$ 50 por patamar
+	maxItensFrutosDoTrabalho = 5
+	valorFrutosDoTrabalho    = 50.0
+)
+
+// recursoFrutosDoTrabalho é o recurso que limita o poder a um uso por aventura
+const recursoFrutosDoTrabalho = "Frutos do Trabalho"
+
+var errFabricacaoEncerrada = errors.New("fabricação já encerrada")
+
+// FabricacaoRequest representa o início de uma fabricação
+type FabricacaoRequest struct {
+	ItemCatalogoID uint `json:"item_catalogo_id" binding:"required,min=1"`
+	ParaCinco      bool `json:"para_cinco"`
+}
+
+// TrabalhoFabricacaoRequest registra dias de trabalho numa fabricação.
+// O d20 do teste de Ofício é sempre rolado pelo servidor.
+type TrabalhoFabricacaoRequest struct {
+	Dias int `json:"dias" binding:"omitempty,min=1,max=365"`
+}
+
+// FrutosDoTrabalhoRequest lista os itens gerais recebidos pelo poder Frutos do Trabalho
+type FrutosDoTrabalhoRequest struct {
+	Itens []struct {
+		ItemCatalogoID uint `json:"item_catalogo_id" binding:"required,min=1"`
+		Quantidade     int  `json:"quantidade" binding:"omitempty,min=1,max=5"`
+	} `json:"itens" binding:"required,min=1,dive"`
+}
+
+// parseFabricacaoID lê o parâmetro :fabricacao_id
+func parseFabricacaoID(c *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("fabricacao_id"), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// cdFabricacao retorna a CD de Ofício para fabricar um item da categoria
+func cdFabricacao(categoria string) int {
+	if categoria == models.CategoriaItemAlquimico {
+		return cdFabricacaoAlquimico
+	}
+	return cdFabricacaoSimples
+}
+
+// diasFabricacao retorna quantos dias de trabalho a categoria exige
+func diasFabricacao(categoria string) int {
+	switch categoria {
+	case models.CategoriaItemAlquimico, models.CategoriaItemAlimentacao:
+		return diasFabricacaoConsumivel
+	default:
+		return diasFabricacaoOutros
+	}
+}
+
+// GetFabricacoes lista as fabricações do personagem (filtro opcional ?status=)
+func (h *PersonagemHandler) GetFabricacoes(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	query := database.DB.Preload("ItemCatalogo").Where("personagem_id = ?", id)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var fabricacoes []models.PersonagemFabricacao
+	if err := query.Order("created_at DESC").Find(&fabricacoes).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar fabricações")
+		return
+	}
+
+	c.JSON(http.StatusOK, fabricacoes)
+}
+
+// IniciarFabricacao calcula custo, CD e tempo de um item do catálogo e debita a matéria-prima
+func (h *PersonagemHandler) IniciarFabricacao(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req FabricacaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var catalogo models.ItemCatalogo
+	if err := database.DB.First(&catalogo, req.ItemCatalogoID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Item não encontrado no catálogo")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar item do catálogo")
+		}
+		return
+	}
+
+	if req.ParaCinco && catalogo.Categoria != models.CategoriaItemAlimentacao {
+		h.Response.BadRequest(c, "Apenas pratos (alimentação) podem ser fabricados para cinco pessoas")
+		return
+	}
+
+	fabricacao := models.PersonagemFabricacao{
+		PersonagemID:      uint(id),
+		ItemCatalogoID:    catalogo.ID,
+		Nome:              catalogo.Nome,
+		Quantidade:        1,
+		ParaCinco:         req.ParaCinco,
+		CustoMateriaPrima: arredondarTibares(catalogo.Preco * fracaoMateriaPrima),
+		CD:                cdFabricacao(catalogo.Categoria),
+		DiasNecessarios:   diasFabricacao(catalogo.Categoria),
+		Status:            models.FabricacaoEmAndamento,
+	}
+	modificadores := []string{}
+
+	if req.ParaCinco {
+		fabricacao.Quantidade = porcoesParaCinco
+		// Água no Feijão: sem penalidade e sem matéria-prima adicional
+		if h.personagemPossuiPoder(uint(id), "Água no Feijão") {
+			modificadores = append(modificadores, "Água no Feijão")
+		} else {
+			fabricacao.Penalidade = penalidadeParaCinco
+			fabricacao.CustoMateriaPrima = arredondarTibares(fabricacao.CustoMateriaPrima * porcoesParaCinco)
+		}
+	}
+
+	var transacao *models.PersonagemTransacao
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&fabricacao).Error; err != nil {
+			return err
+		}
+		if fabricacao.CustoMateriaPrima == 0 {
+			return nil
+		}
+
+		var err error
+		motivo := fmt.Sprintf("Matéria-prima: %s", fabricacao.Nome)
+		transacao, err = registrarTransacao(tx, uint(id), -fabricacao.CustoMateriaPrima, motivo, nil)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrSaldoInsuficiente) {
+			h.Response.BadRequest(c, fmt.Sprintf("Saldo insuficiente: a matéria-prima custa T$ %.2f", fabricacao.CustoMateriaPrima))
+		} else {
+			h.Response.InternalError(c, "Erro ao iniciar fabricação")
+		}
+		return
+	}
+
+	h.Response.Created(c, gin.H{
+		"fabricacao":    fabricacao,
+		"transacao":     transacao,
+		"modificadores": modificadores,
+	})
+}
+
+// TrabalharFabricacao registra dias de trabalho. Quando o tempo necessário se completa, o
+// teste de Ofício é resolvido: sucesso entrega o item, falha por 5 ou mais perde a
+// matéria-prima, e falhas menores exigem recomeçar o trabalho.
+func (h *PersonagemHandler) TrabalharFabricacao(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+	fabricacaoID, err := parseFabricacaoID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID da fabricação inválido")
+		return
+	}
+
+	// Corpo opcional: sem corpo, registra 1 dia e o servidor rola o d20
+	var req TrabalhoFabricacaoRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+	}
+	if req.Dias == 0 {
+		req.Dias = 1
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	h.calcularTormenta(personagem)
+	oficio, treinado := h.totalPericia(personagem, "Ofício")
+
+	var fabricacao models.PersonagemFabricacao
+	var teste gin.H
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND personagem_id = ?", fabricacaoID, id).
+			First(&fabricacao).Error; err != nil {
+			return err
+		}
+		if fabricacao.Status != models.FabricacaoEmAndamento {
+			return errFabricacaoEncerrada
+		}
+
+		fabricacao.DiasTrabalhados += req.Dias
+		if fabricacao.DiasTrabalhados < fabricacao.DiasNecessarios {
+			return tx.Save(&fabricacao).Error
+		}
+
+		rolagem := rand.Intn(20) + 1
+		resultado := rolagem + oficio - fabricacao.Penalidade
+		fabricacao.UltimaRolagem = &rolagem
+		fabricacao.UltimoResultado = &resultado
+
+		teste = gin.H{
+			"rolagem":    rolagem,
+			"bonus":      oficio,
+			"penalidade": fabricacao.Penalidade,
+			"resultado":  resultado,
+			"cd":         fabricacao.CD,
+			"treinado":   treinado,
+			"sucesso":    resultado >= fabricacao.CD,
+		}
+
+		switch {
+		case resultado >= fabricacao.CD:
+			var catalogo models.ItemCatalogo
+			if err := tx.First(&catalogo, fabricacao.ItemCatalogoID).Error; err != nil {
+				return err
+			}
+			item, err := adicionarItemCatalogo(tx, uint(id), &catalogo, fabricacao.Quantidade, models.FonteItemFabricacao)
+			if err != nil {
+				return err
+			}
+			fabricacao.ItemID = &item.ID
+			fabricacao.Status = models.FabricacaoConcluida
+		case fabricacao.CD-resultado >= margemPerdaMateriaPrima:
+			fabricacao.Status = models.FabricacaoFalhou
+		default:
+			// Falha simples: a matéria-prima é mantida, mas o trabalho recomeça
+			fabricacao.DiasTrabalhados = 0
+		}
+
+		return tx.Save(&fabricacao).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			h.Response.NotFound(c, "Fabricação não encontrada")
+		case errors.Is(err, errFabricacaoEncerrada):
+			h.Response.BadRequest(c, "Esta fabricação já foi encerrada")
+		case errors.Is(err, errLimiteItens):
+			h.Response.BadRequest(c, fmt.Sprintf("máximo de %d itens por personagem", maxItensPorPersonagem))
+		default:
+			h.Response.InternalError(c, "Erro ao registrar trabalho na fabricação")
+		}
+		return
+	}
+
+	h.Response.Success(c, gin.H{
+		"fabricacao": fabricacao,
+		"teste":      teste,
+	})
+}
+
+// gastarUsoFrutosDoTrabalho consome o uso por aventura do poder. O recurso, mantido pelo
+// servidor, é criado no primeiro uso e só volta no reinício de "aventura".
+func gastarUsoFrutosDoTrabalho(tx *gorm.DB, personagemID uint, variaveis map[string]int) error {
+	// Trava o personagem para que dois pedidos simultâneos não criem o recurso duas vezes
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		First(&models.Personagem{}, personagemID).Error; err != nil {
+		return err
+	}
+
+	var recurso models.PersonagemRecurso
+	err := tx.Where("personagem_id = ? AND nome = ? AND sistema = ?",
+		personagemID, recursoFrutosDoTrabalho, true).First(&recurso).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		usado := 0
+		return tx.Create(&models.PersonagemRecurso{
+			PersonagemID: personagemID,
+			Nome:         recursoFrutosDoTrabalho,
+			Maximo:       "1",
+			Atual:        &usado,
+			Reinicio:     models.ReinicioAventura,
+			Descricao:    "Uso por aventura do poder Frutos do Trabalho",
+			Sistema:      true,
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	calcularRecurso(&recurso, variaveis)
+	if recurso.Disponivel < 1 {
+		return errRecursoInsuficiente
+	}
+	return tx.Model(&recurso).Update("atual", recurso.Disponivel-1).Error
+}
+
+// ReceberFrutosDoTrabalho entrega os itens gerais do poder de origem Frutos do Trabalho:
+// até 5 itens com valor total de T$ 50 por patamar, sem custo para o personagem, uma vez
+// por aventura.
+func (h *PersonagemHandler) ReceberFrutosDoTrabalho(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req FrutosDoTrabalhoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	if !h.personagemPossuiPoder(personagem.ID, "Frutos do Trabalho") {
+		h.Response.BadRequest(c, "O personagem não possui o poder Frutos do Trabalho")
+		return
+	}
+
+	limite := valorFrutosDoTrabalho * float64(models.IndicePatamar(personagem.Nivel))
+	totalItens := 0
+	totalValor := 0.0
+	catalogos := make([]models.ItemCatalogo, len(req.Itens))
+	for i, pedido := range req.Itens {
+		if pedido.Quantidade == 0 {
+			req.Itens[i].Quantidade = 1
+		}
+		if err := database.DB.First(&catalogos[i], pedido.ItemCatalogoID).Error; err != nil {
+			h.Response.BadRequest(c, fmt.Sprintf("Item %d não encontrado no catálogo", pedido.ItemCatalogoID))
+			return
+		}
+		if catalogos[i].Categoria != models.CategoriaItemGeral {
+			h.Response.BadRequest(c, fmt.Sprintf("%s não é um item geral", catalogos[i].Nome))
+			return
+		}
+		totalItens += req.Itens[i].Quantidade
+		totalValor += catalogos[i].Preco * float64(req.Itens[i].Quantidade)
+	}
+
+	if totalItens > maxItensFrutosDoTrabalho {
+		h.Response.BadRequest(c, fmt.Sprintf("Frutos do Trabalho concede no máximo %d itens", maxItensFrutosDoTrabalho))
+		return
+	}
+	if totalValor > limite {
+		h.Response.BadRequest(c, fmt.Sprintf("Valor total de T$ %.2f excede o limite de T$ %.2f do patamar", totalValor, limite))
+		return
+	}
+
+	h.calcularTormenta(personagem)
+	variaveis := h.variaveisFormulaRecurso(personagem)
+
+	itens := make([]*models.PersonagemItem, 0, len(req.Itens))
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := gastarUsoFrutosDoTrabalho(tx, personagem.ID, variaveis); err != nil {
+			return err
+		}
+		for i := range req.Itens {
+			item, err := adicionarItemCatalogo(tx, personagem.ID, &catalogos[i], req.Itens[i].Quantidade, models.FonteItemFabricacao)
+			if err != nil {
+				return err
+			}
+			itens = append(itens, item)
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errRecursoInsuficiente):
+			h.Response.BadRequest(c, "Frutos do Trabalho já foi usado nesta aventura")
+		case errors.Is(err, errLimiteItens):
+			h.Response.BadRequest(c, fmt.Sprintf("máximo de %d itens por personagem", maxItensPorPersonagem))
+		default:
+			h.Response.InternalError(c, "Erro ao adicionar itens")
+		}
+		return
+	}
+
+	h.Response.Created(c, gin.H{
+		"itens":       itens,
+		"valor_total": arredondarTibares(totalValor),
+		"limite":      limite,
+	})
+}
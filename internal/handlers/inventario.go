@@ -0,0 +1,510 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// camposEditaveisItem são as colunas que o jogador pode alterar num item do inventário.
+// Fonte, vínculos com o catálogo e com o kit de origem e melhorias/encantos são mantidos
+// pelo servidor: o catálogo define o preço de revenda e o efeito de consumíveis.
+var camposEditaveisItem = []string{
+	"nome", "tipo", "quantidade", "peso", "valor", "descricao",
+	"equipado", "container_id", "capacidade", "ordem", "categoria_arma",
+}
+
+var (
+	errContainerInvalido    = errors.New("container inválido")
+	errContainerCheio       = errors.New("capacidade do container excedida")
+	errContainerCircular    = errors.New("um container não pode ser guardado dentro de si mesmo")
+	errItemForaDoPersonagem = errors.New("item não pertence ao personagem")
+)
+
+// ItemRequest representa a criação ou edição de um item do inventário
+type ItemRequest struct {
+	Nome        string  `json:"nome" binding:"required,min=1,max=200"`
+	Tipo        string  `json:"tipo" binding:"omitempty,max=50"`
+	Quantidade  int     `json:"quantidade" binding:"omitempty,min=1,max=9999"`
+	Peso        float64 `json:"peso" binding:"min=0"`
+	Valor       float64 `json:"valor" binding:"min=0"`
+	Descricao   string  `json:"descricao"`
+	Equipado    bool    `json:"equipado"`
+	ContainerID *uint   `json:"container_id"`
+	Capacidade  float64 `json:"capacidade" binding:"min=0"`
+	// Só para armas; omitida, é deduzida pelo nome
+	CategoriaArma string `json:"categoria_arma" binding:"omitempty,oneof=corpo_a_corpo arremesso disparo"`
+}
+
+// OrdemItensRequest define a nova ordem dos itens do inventário
+type OrdemItensRequest struct {
+	ItemIDs []uint `json:"item_ids" binding:"required,min=1"`
+}
+
+// aplicar copia os campos editáveis da requisição para o item
+func (r *ItemRequest) aplicar(item *models.PersonagemItem) {
+	item.Nome = r.Nome
+	item.Tipo = r.Tipo
+	if item.Tipo == "" {
+		item.Tipo = "item"
+	}
+	item.Quantidade = r.Quantidade
+	if item.Quantidade == 0 {
+		item.Quantidade = 1
+	}
+	item.Peso = r.Peso
+	item.Valor = r.Valor
+	item.Descricao = r.Descricao
+	item.Equipado = r.Equipado
+	item.ContainerID = r.ContainerID
+	item.Capacidade = r.Capacidade
+	item.CategoriaArma = r.CategoriaArma
+	item.NormalizarCategoriaArma()
+}
+
+// proximaOrdem retorna a posição para um item adicionado ao fim do inventário
+func proximaOrdem(tx *gorm.DB, personagemID uint) int {
+	var ordem *int
+	tx.Model(&models.PersonagemItem{}).
+		Where("personagem_id = ?", personagemID).
+		Select("MAX(ordem)").
+		Scan(&ordem)
+	if ordem == nil {
+		return 0
+	}
+	return *ordem + 1
+}
+
+// validarContainer confere se o item pode ser guardado no container: mesmo personagem,
+// container com capacidade, sem ciclos e com espaço livre
+func validarContainer(tx *gorm.DB, item *models.PersonagemItem) error {
+	if item.ContainerID == nil {
+		return nil
+	}
+	if *item.ContainerID == item.ID {
+		return errContainerCircular
+	}
+
+	var container models.PersonagemItem
+	if err := tx.Where("id = ? AND personagem_id = ?", *item.ContainerID, item.PersonagemID).
+		First(&container).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errContainerInvalido
+		}
+		return err
+	}
+	if container.Capacidade <= 0 {
+		return errContainerInvalido
+	}
+
+	// Sobe a cadeia de containers para impedir que o item acabe dentro de si mesmo
+	if item.ID != 0 {
+		atual := container.ContainerID
+		for passos := 0; atual != nil && passos < maxItensPorPersonagem; passos++ {
+			if *atual == item.ID {
+				return errContainerCircular
+			}
+			var pai models.PersonagemItem
+			if err := tx.Select("id", "container_id").First(&pai, *atual).Error; err != nil {
+				break
+			}
+			atual = pai.ContainerID
+		}
+	}
+
+	var usados float64
+	if err := tx.Model(&models.PersonagemItem{}).
+		Where("container_id = ? AND id <> ?", container.ID, item.ID).
+		Select("COALESCE(SUM(peso * quantidade), 0)").
+		Scan(&usados).Error; err != nil {
+		return err
+	}
+	if usados+item.Peso*float64(item.Quantidade) > container.Capacidade {
+		return errContainerCheio
+	}
+
+	return nil
+}
+
+// calcularCarga preenche o espaço usado de cada container e a carga do personagem.
+// Itens guardados em containers ocupam a capacidade do container, não a carga.
+func (h *PersonagemHandler) calcularCarga(personagem *models.Personagem) {
+	usados := make(map[uint]float64)
+	carga := 0.0
+	for _, item := range personagem.Itens {
+		espacos := item.Peso * float64(item.Quantidade)
+		if item.ContainerID != nil {
+			usados[*item.ContainerID] += espacos
+		} else {
+			carga += espacos
+		}
+	}
+	for i := range personagem.Itens {
+		personagem.Itens[i].EspacosUsados = usados[personagem.Itens[i].ID]
+	}
+
+	// Limite de carga do T20: 10 + 2x Força, +5 com Mochileiro ou Costas Largas
+	maxima := 10 + 2*personagem.For
+	if h.personagemPossuiPoder(personagem.ID, "Mochileiro") {
+		maxima += 5
+	}
+	if h.personagemPossuiPoder(personagem.ID, "Costas Largas") {
+		maxima += 5
+	}
+
+	personagem.CargaUsada = carga
+	personagem.CargaMaxima = maxima
+}
+
+// respostaErroItem traduz erros de validação do inventário em respostas HTTP
+func (h *PersonagemHandler) respostaErroItem(c *gin.Context, err error, mensagem string) {
+	switch {
+	case errors.Is(err, errContainerInvalido):
+		h.Response.BadRequest(c, "Container não encontrado no inventário ou sem capacidade")
+	case errors.Is(err, errContainerCheio), errors.Is(err, errContainerCircular):
+		h.Response.BadRequest(c, err.Error())
+	case errors.Is(err, errLimiteItens):
+		h.Response.BadRequest(c, fmt.Sprintf("máximo de %d itens por personagem", maxItensPorPersonagem))
+	case errors.Is(err, errItemForaDoPersonagem):
+		h.Response.BadRequest(c, "Um ou mais itens não pertencem ao personagem")
+	default:
+		h.Response.InternalError(c, mensagem)
+	}
+}
+
+// GetItens lista o inventário do personagem na ordem definida pelo jogador
+func (h *PersonagemHandler) GetItens(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	if err := database.DB.Preload("Melhorias").Preload("Encantos").
+		Where("personagem_id = ?", id).
+		Order("ordem, id").
+		Find(&personagem.Itens).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar itens")
+		return
+	}
+
+	for i := range personagem.Itens {
+		personagem.Itens[i].CalcularModificacoes()
+	}
+	h.calcularCarga(personagem)
+
+	c.JSON(http.StatusOK, gin.H{
+		"itens":        personagem.Itens,
+		"carga_usada":  personagem.CargaUsada,
+		"carga_maxima": personagem.CargaMaxima,
+	})
+}
+
+// CreateItem adiciona um item ao fim do inventário
+func (h *PersonagemHandler) CreateItem(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req ItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	item := models.PersonagemItem{PersonagemID: uint(id), Fonte: models.FonteItemManual}
+	req.aplicar(&item)
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.PersonagemItem{}).Where("personagem_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= maxItensPorPersonagem {
+			return errLimiteItens
+		}
+		if err := validarContainer(tx, &item); err != nil {
+			return err
+		}
+
+		item.Ordem = proximaOrdem(tx, uint(id))
+		return tx.Omit(clause.Associations).Create(&item).Error
+	})
+	if err != nil {
+		h.respostaErroItem(c, err, "Erro ao adicionar item")
+		return
+	}
+
+	h.Response.Created(c, item)
+}
+
+// UpdateItem edita um item do inventário mantendo seu ID
+func (h *PersonagemHandler) UpdateItem(c *gin.Context) {
+	var req ItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	item, ok := h.findItemPersonagem(c)
+	if !ok {
+		return
+	}
+
+	req.aplicar(item)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := validarContainer(tx, item); err != nil {
+			return err
+		}
+
+		// Um container não pode encolher abaixo do que já guarda
+		var guardados float64
+		if err := tx.Model(&models.PersonagemItem{}).
+			Where("container_id = ?", item.ID).
+			Select("COALESCE(SUM(peso * quantidade), 0)").
+			Scan(&guardados).Error; err != nil {
+			return err
+		}
+		if guardados > item.Capacidade {
+			return errContainerCheio
+		}
+
+		return tx.Model(item).Select(camposEditaveisItem).Updates(item).Error
+	})
+	if err != nil {
+		h.respostaErroItem(c, err, "Erro ao atualizar item")
+		return
+	}
+
+	item.CalcularModificacoes()
+	h.Response.Success(c, item)
+}
+
+// DeleteItem remove um item do inventário. Itens guardados nele voltam para fora do container.
+func (h *PersonagemHandler) DeleteItem(c *gin.Context) {
+	item, ok := h.findItemPersonagem(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PersonagemItem{}).
+			Where("container_id = ?", item.ID).
+			Update("container_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.PersonagemItem{}, item.ID).Error
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao remover item")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ReordenarItens define a ordem dos itens pela posição na lista enviada.
+// Itens não listados mantêm a posição relativa depois dos listados.
+func (h *PersonagemHandler) ReordenarItens(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req OrdemItensRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	ids := uniqueIDs(req.ItemIDs)
+
+	var itens []models.PersonagemItem
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("personagem_id = ?", id).Order("ordem, id").Find(&itens).Error; err != nil {
+			return err
+		}
+
+		posicao := make(map[uint]int, len(ids))
+		for i, itemID := range ids {
+			posicao[itemID] = i
+		}
+		encontrados := 0
+		for _, item := range itens {
+			if _, ok := posicao[item.ID]; ok {
+				encontrados++
+			}
+		}
+		if encontrados != len(ids) {
+			return errItemForaDoPersonagem
+		}
+
+		proxima := len(ids)
+		for i := range itens {
+			ordem, ok := posicao[itens[i].ID]
+			if !ok {
+				ordem = proxima
+				proxima++
+			}
+			itens[i].Ordem = ordem
+			if err := tx.Model(&itens[i]).Update("ordem", ordem).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.respostaErroItem(c, err, "Erro ao reordenar itens")
+		return
+	}
+
+	h.Response.Success(c, gin.H{"message": "Ordem dos itens atualizada"})
+}
+
+// sincronizarItens aplica a lista completa de itens do PUT da ficha comparando com as linhas
+// existentes: itens conhecidos são atualizados no lugar (IDs estáveis), novos são criados e
+// os ausentes removidos. remover indica itens de kit que devem sair mesmo se enviados. Containers
+// passam pelas mesmas checagens de capacidade e ciclos das rotas de item.
+func sincronizarItens(tx *gorm.DB, personagemID uint, itens []models.PersonagemItem, remover func(models.PersonagemItem) bool) error {
+	var existentes []models.PersonagemItem
+	if err := tx.Where("personagem_id = ?", personagemID).Find(&existentes).Error; err != nil {
+		return err
+	}
+	existente := make(map[uint]models.PersonagemItem, len(existentes))
+	for _, item := range existentes {
+		existente[item.ID] = item
+	}
+
+	mantidos := make(map[uint]bool, len(itens))
+	for _, item := range itens {
+		if antigo, ok := existente[item.ID]; ok && item.ID != 0 && !remover(antigo) {
+			mantidos[item.ID] = true
+		}
+	}
+
+	// Containers só podem apontar para itens que continuam no inventário
+	pais := make(map[uint]*uint, len(itens))
+	capacidades := make(map[uint]float64, len(itens))
+	for i := range itens {
+		if itens[i].ContainerID != nil && !mantidos[*itens[i].ContainerID] {
+			itens[i].ContainerID = nil
+		}
+		if mantidos[itens[i].ID] {
+			pais[itens[i].ID] = itens[i].ContainerID
+			capacidades[itens[i].ID] = itens[i].Capacidade
+		}
+	}
+
+	// Mesmas regras de validarContainer, aplicadas à lista inteira
+	usados := make(map[uint]float64)
+	for i := range itens {
+		if itens[i].ContainerID == nil {
+			continue
+		}
+		if mantidos[itens[i].ID] && possuiCiclo(pais, itens[i].ID) {
+			return errContainerCircular
+		}
+		if capacidades[*itens[i].ContainerID] <= 0 {
+			return errContainerInvalido
+		}
+		usados[*itens[i].ContainerID] += itens[i].Peso * float64(itens[i].Quantidade)
+	}
+	for containerID, espacos := range usados {
+		if espacos > capacidades[containerID] {
+			return errContainerCheio
+		}
+	}
+
+	for _, item := range existentes {
+		if !mantidos[item.ID] {
+			if err := tx.Model(&models.PersonagemItem{}).
+				Where("container_id = ?", item.ID).
+				Update("container_id", nil).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&models.PersonagemItem{}, item.ID).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range itens {
+		item := itens[i]
+		item.PersonagemID = personagemID
+		item.Ordem = i
+		item.NormalizarCategoriaArma()
+
+		if mantidos[item.ID] {
+			antigo := existente[item.ID]
+			if err := tx.Model(&antigo).Select(camposEditaveisItem).Updates(&item).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		if item.ID != 0 && existente[item.ID].ID != 0 {
+			// Item de kit removido pela troca de origem/classe
+			continue
+		}
+		item.ID = 0
+		item.Fonte = models.FonteItemManual
+		item.OrigemItemID = nil
+		item.ItemCatalogoID = nil
+		if err := tx.Omit(clause.Associations).Create(&item).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// possuiCiclo verifica se seguir os containers a partir do item volta para ele mesmo
+func possuiCiclo(pais map[uint]*uint, itemID uint) bool {
+	atual := pais[itemID]
+	for passos := 0; atual != nil && passos <= len(pais); passos++ {
+		if *atual == itemID {
+			return true
+		}
+		atual = pais[*atual]
+	}
+	return false
+}
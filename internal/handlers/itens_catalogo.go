@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ItemCatalogoHandler struct {
+	*GenericService
+}
+
+func NewItemCatalogoHandler() *ItemCatalogoHandler {
+	return &ItemCatalogoHandler{
+		GenericService: NewGenericService(database.DB),
+	}
+}
+
+func (h *ItemCatalogoHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	itens := rg.Group("/itens-catalogo")
+	{
+		itens.GET("", h.GetAllItens)
+		itens.GET("/:id", h.GetItem)
+
+		escrita := escritaCatalogo(itens)
+		escrita.POST("", h.CreateItem)
+		escrita.PUT("/:id", h.UpdateItem)
+		escrita.DELETE("/:id", h.DeleteItem)
+	}
+}
+
+// GetAllItens lista o catálogo, com filtros opcionais por categoria e nome
+func (h *ItemCatalogoHandler) GetAllItens(c *gin.Context) {
+	query := database.DB.Order("categoria, nome")
+
+	if categoria := c.Query("categoria"); categoria != "" {
+		query = query.Where("categoria = ?", categoria)
+	}
+	if busca := c.Query("busca"); busca != "" {
+		query = query.Where("nome ILIKE ?", "%"+busca+"%")
+	}
+
+	var itens []models.ItemCatalogo
+	if err := query.Find(&itens).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar itens do catálogo")
+		return
+	}
+
+	c.JSON(http.StatusOK, itens)
+}
+
+func (h *ItemCatalogoHandler) GetItem(c *gin.Context) {
+	var item models.ItemCatalogo
+	h.GetByID(c, &item, "Item não encontrado")
+}
+
+func (h *ItemCatalogoHandler) CreateItem(c *gin.Context) {
+	var item models.ItemCatalogo
+	h.Create(c, &item)
+}
+
+func (h *ItemCatalogoHandler) UpdateItem(c *gin.Context) {
+	var item models.ItemCatalogo
+	h.Update(c, &item, "Item não encontrado")
+}
+
+func (h *ItemCatalogoHandler) DeleteItem(c *gin.Context) {
+	var item models.ItemCatalogo
+	h.Delete(c, &item, "Item não encontrado")
+}
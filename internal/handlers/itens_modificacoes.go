@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// preloadItens carrega o inventário com as melhorias e encantos de cada item
+func preloadItens(db *gorm.DB) *gorm.DB {
+	return db.Preload("Itens", func(db *gorm.DB) *gorm.DB {
+		return db.Order("ordem, id")
+	}).Preload("Itens.Melhorias").Preload("Itens.Encantos")
+}
+
+// parseItemID lê o parâmetro :item_id das rotas de inventário
+func parseItemID(c *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// findItemPersonagem busca um item do inventário de um personagem do usuário
+func (h *PersonagemHandler) findItemPersonagem(c *gin.Context) (*models.PersonagemItem, bool) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return nil, false
+	}
+	itemID, err := parseItemID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID do item inválido")
+		return nil, false
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return nil, false
+	}
+
+	var item models.PersonagemItem
+	if err := database.DB.Preload("Melhorias").Preload("Encantos").
+		Where("id = ? AND personagem_id = ?", itemID, id).
+		First(&item).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Item não encontrado no inventário")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar item")
+		}
+		return nil, false
+	}
+
+	return &item, true
+}
+
+// validarMelhorias aplica as regras de item superior: limite por item, tipo compatível
+// e pré-requisitos presentes no mesmo item
+func validarMelhorias(item *models.PersonagemItem, melhorias []models.Melhoria) error {
+	if len(melhorias) > models.MaxMelhoriasPorItem {
+		return fmt.Errorf("um item pode ter no máximo %d melhorias", models.MaxMelhoriasPorItem)
+	}
+
+	nomes := make(map[string]bool, len(melhorias))
+	for _, m := range melhorias {
+		nomes[m.Nome] = true
+	}
+
+	for _, m := range melhorias {
+		if !m.AplicavelA(item.Tipo) {
+			return fmt.Errorf("a melhoria %s não pode ser aplicada a itens do tipo %s", m.Nome, item.Tipo)
+		}
+		if m.PreRequisito != "" && !nomes[m.PreRequisito] {
+			return fmt.Errorf("a melhoria %s exige a melhoria %s", m.Nome, m.PreRequisito)
+		}
+	}
+
+	return nil
+}
+
+// validarEncantos aplica as regras de item mágico: limite por item, tipo compatível
+// e pré-requisitos presentes no mesmo item
+func validarEncantos(item *models.PersonagemItem, encantos []models.Encanto) error {
+	if len(encantos) > models.MaxEncantosPorItem {
+		return fmt.Errorf("um item pode ter no máximo %d encantos", models.MaxEncantosPorItem)
+	}
+
+	nomes := make(map[string]bool, len(encantos))
+	for _, e := range encantos {
+		nomes[e.Nome] = true
+	}
+
+	for _, e := range encantos {
+		if !e.AplicavelA(item.Tipo) {
+			return fmt.Errorf("o encanto %s não pode ser aplicado a itens do tipo %s", e.Nome, item.Tipo)
+		}
+		if e.PreRequisito != "" && !nomes[e.PreRequisito] {
+			return fmt.Errorf("o encanto %s exige o encanto %s", e.Nome, e.PreRequisito)
+		}
+	}
+
+	return nil
+}
+
+// SetMelhoriasItem substitui as melhorias de um item do inventário
+func (h *PersonagemHandler) SetMelhoriasItem(c *gin.Context) {
+	var request struct {
+		MelhoriaIDs []uint `json:"melhoria_ids"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	item, ok := h.findItemPersonagem(c)
+	if !ok {
+		return
+	}
+
+	var melhorias []models.Melhoria
+	if len(request.MelhoriaIDs) > 0 {
+		if err := database.DB.Where("id IN ?", request.MelhoriaIDs).Find(&melhorias).Error; err != nil {
+			h.Response.InternalError(c, "Erro ao buscar melhorias")
+			return
+		}
+		if len(melhorias) != len(uniqueIDs(request.MelhoriaIDs)) {
+			h.Response.BadRequest(c, "Uma ou mais melhorias não foram encontradas")
+			return
+		}
+	}
+
+	if err := validarMelhorias(item, melhorias); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("personagem_item_id = ?", item.ID).Delete(&models.PersonagemItemMelhoria{}).Error; err != nil {
+			return err
+		}
+		for _, m := range melhorias {
+			if err := tx.Create(&models.PersonagemItemMelhoria{PersonagemItemID: item.ID, MelhoriaID: m.ID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao salvar melhorias do item")
+		return
+	}
+
+	item.Melhorias = melhorias
+	item.CalcularModificacoes()
+	h.Response.Success(c, item)
+}
+
+// SetEncantosItem substitui os encantos de um item do inventário
+func (h *PersonagemHandler) SetEncantosItem(c *gin.Context) {
+	var request struct {
+		EncantoIDs []uint `json:"encanto_ids"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	item, ok := h.findItemPersonagem(c)
+	if !ok {
+		return
+	}
+
+	var encantos []models.Encanto
+	if len(request.EncantoIDs) > 0 {
+		if err := database.DB.Where("id IN ?", request.EncantoIDs).Find(&encantos).Error; err != nil {
+			h.Response.InternalError(c, "Erro ao buscar encantos")
+			return
+		}
+		if len(encantos) != len(uniqueIDs(request.EncantoIDs)) {
+			h.Response.BadRequest(c, "Um ou mais encantos não foram encontrados")
+			return
+		}
+	}
+
+	if err := validarEncantos(item, encantos); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("personagem_item_id = ?", item.ID).Delete(&models.PersonagemItemEncanto{}).Error; err != nil {
+			return err
+		}
+		for _, e := range encantos {
+			if err := tx.Create(&models.PersonagemItemEncanto{PersonagemItemID: item.ID, EncantoID: e.ID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao salvar encantos do item")
+		return
+	}
+
+	item.Encantos = encantos
+	item.CalcularModificacoes()
+	h.Response.Success(c, item)
+}
+
+// uniqueIDs remove IDs repetidos mantendo a ordem
+func uniqueIDs(ids []uint) []uint {
+	vistos := make(map[uint]bool, len(ids))
+	unicos := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !vistos[id] {
+			vistos[id] = true
+			unicos = append(unicos, id)
+		}
+	}
+	return unicos
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+	"tormenta20-builder/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UsoItemRequest representa o uso de unidades de um item consumível
+type UsoItemRequest struct {
+	Quantidade int `json:"quantidade" binding:"omitempty,min=1,max=999"`
+}
+
+// UsarItem gasta unidades de um consumível (poção, alquímico, munição...), aplica o efeito
+// definido no catálogo e registra o uso. O item é removido quando a quantidade chega a zero.
+func (h *PersonagemHandler) UsarItem(c *gin.Context) {
+	var req UsoItemRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+	}
+	if req.Quantidade == 0 {
+		req.Quantidade = 1
+	}
+
+	item, ok := h.findItemPersonagem(c)
+	if !ok {
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(item.PersonagemID))
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao buscar personagem")
+		return
+	}
+
+	var efeito *models.EfeitoItem
+	if item.ItemCatalogoID != nil {
+		var catalogo models.ItemCatalogo
+		if err := database.DB.First(&catalogo, *item.ItemCatalogoID).Error; err == nil {
+			efeito, err = catalogo.EfeitoDefinido()
+			if err != nil {
+				h.Response.InternalError(c, "Efeito do item inválido no catálogo")
+				return
+			}
+		}
+	}
+
+	if !item.Consumivel() && efeito == nil {
+		h.Response.BadRequest(c, "Este item não é consumível")
+		return
+	}
+	if req.Quantidade > item.Quantidade {
+		h.Response.BadRequest(c, fmt.Sprintf("O personagem possui apenas %d unidade(s) deste item", item.Quantidade))
+		return
+	}
+
+	// Rola o efeito uma vez por unidade usada
+	var rolagens []*services.ResultadoDados
+	total := 0
+	if efeito != nil && efeito.Dados != "" {
+		for i := 0; i < req.Quantidade; i++ {
+			resultado, err := services.RolarDados(efeito.Dados)
+			if err != nil {
+				h.Response.InternalError(c, "Efeito do item inválido no catálogo")
+				return
+			}
+			rolagens = append(rolagens, resultado)
+			total += resultado.Total
+		}
+	}
+
+	h.calculatePersonagemStats(personagem)
+
+	uso := models.PersonagemItemUso{
+		PersonagemID: personagem.ID,
+		ItemID:       &item.ID,
+		Nome:         item.Nome,
+		Quantidade:   req.Quantidade,
+	}
+	restante := item.Quantidade - req.Quantidade
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Decremento condicionado à quantidade atual evita gastar a mesma unidade duas vezes
+		result := tx.Model(&models.PersonagemItem{}).
+			Where("id = ? AND quantidade >= ?", item.ID, req.Quantidade).
+			Update("quantidade", gorm.Expr("quantidade - ?", req.Quantidade))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if restante == 0 {
+			if err := tx.Delete(&models.PersonagemItem{}, item.ID).Error; err != nil {
+				return err
+			}
+			uso.ItemID = nil
+		}
+
+		if efeito != nil {
+			uso.Efeito = efeito.Tipo
+			uso.Resultado = &total
+			if err := aplicarEfeito(tx, personagem, efeito, total, &uso); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&uso).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.Response.BadRequest(c, "Quantidade do item mudou durante o uso, tente novamente")
+		} else {
+			h.Response.InternalError(c, "Erro ao usar item")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"uso":      uso,
+		"restante": restante,
+		"rolagens": rolagens,
+		"pv_atual": personagem.PVAtual,
+		"pm_atual": personagem.PMAtual,
+		"pv_total": personagem.PVTotal,
+		"pm_total": personagem.PMTotal,
+	})
+}
+
+// aplicarEfeito aplica a cura de um consumível aos PV/PM atuais, limitada ao máximo do personagem
+func aplicarEfeito(tx *gorm.DB, personagem *models.Personagem, efeito *models.EfeitoItem, total int, uso *models.PersonagemItemUso) error {
+	var atual models.Personagem
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "pv_atual", "pm_atual").
+		First(&atual, personagem.ID).Error; err != nil {
+		return err
+	}
+
+	switch efeito.Tipo {
+	case models.EfeitoCuraPV:
+		novo := curar(atual.PVAtual, personagem.PVTotal, total)
+		personagem.PVAtual = &novo
+		uso.Detalhes = fmt.Sprintf("Recuperou %d PV (%d/%d)", total, novo, personagem.PVTotal)
+		return tx.Model(&atual).Update("pv_atual", novo).Error
+	case models.EfeitoCuraPM:
+		novo := curar(atual.PMAtual, personagem.PMTotal, total)
+		personagem.PMAtual = &novo
+		uso.Detalhes = fmt.Sprintf("Recuperou %d PM (%d/%d)", total, novo, personagem.PMTotal)
+		return tx.Model(&atual).Update("pm_atual", novo).Error
+	}
+
+	uso.Detalhes = fmt.Sprintf("Efeito %s: %d", efeito.Tipo, total)
+	return nil
+}
+
+// curar soma a cura ao valor atual (nil = cheio) sem passar do máximo
+func curar(atual *int, maximo, cura int) int {
+	valor := maximo
+	if atual != nil {
+		valor = *atual
+	}
+	valor += cura
+	if valor > maximo {
+		valor = maximo
+	}
+	return valor
+}
+
+// DanoRequest representa dano sofrido e PM gastos; valores negativos recuperam
+type DanoRequest struct {
+	PV int `json:"pv" binding:"min=-9999,max=9999"`
+	PM int `json:"pm" binding:"min=-9999,max=9999"`
+}
+
+// SofrerDano reduz os PV e PM atuais do personagem. PM não ficam negativos; PV podem ficar,
+// até o limiar de morte (-10 ou metade dos PV totais, o que for mais baixo). Recuperação
+// por valores negativos nunca passa do máximo.
+func (h *PersonagemHandler) SofrerDano(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req DanoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+	if req.PV == 0 && req.PM == 0 {
+		h.Response.BadRequest(c, "Informe pv ou pm")
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+	h.calculatePersonagemStats(personagem)
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var atual models.Personagem
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "pv_atual", "pm_atual").
+			First(&atual, personagem.ID).Error; err != nil {
+			return err
+		}
+
+		pv := alterarPontos(atual.PVAtual, personagem.PVTotal, -max(10, personagem.PVTotal/2), -req.PV)
+		pm := alterarPontos(atual.PMAtual, personagem.PMTotal, 0, -req.PM)
+		personagem.PVAtual = &pv
+		personagem.PMAtual = &pm
+		return tx.Model(&atual).Updates(map[string]interface{}{"pv_atual": pv, "pm_atual": pm}).Error
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao aplicar dano")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pv_atual": personagem.PVAtual,
+		"pm_atual": personagem.PMAtual,
+		"pv_total": personagem.PVTotal,
+		"pm_total": personagem.PMTotal,
+	})
+}
+
+// alterarPontos soma delta ao valor atual (nil = cheio), mantendo-o entre minimo e maximo
+func alterarPontos(atual *int, maximo, minimo, delta int) int {
+	valor := maximo
+	if atual != nil {
+		valor = *atual
+	}
+	return min(max(valor+delta, minimo), maximo)
+}
+
+// GetUsosItens lista o registro de uso de itens do personagem (?limite=, padrão 100)
+func (h *PersonagemHandler) GetUsosItens(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	limite, err := strconv.Atoi(c.DefaultQuery("limite", "100"))
+	if err != nil || limite < 1 || limite > 500 {
+		h.Response.BadRequest(c, "Limite inválido")
+		return
+	}
+
+	var usos []models.PersonagemItemUso
+	if err := database.DB.Where("personagem_id = ?", id).
+		Order("created_at DESC, id DESC").
+		Limit(limite).
+		Find(&usos).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar usos de itens")
+		return
+	}
+
+	c.JSON(http.StatusOK, usos)
+}
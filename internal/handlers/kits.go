@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"tormenta20-builder/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// resolverItensOrigem monta os itens iniciais da origem para o inventário do personagem.
+// Itens com opções exigem uma escolha em escolhas (chave = ID do item da origem).
+func (h *PersonagemHandler) resolverItensOrigem(origemID uint, escolhas map[uint]string) ([]models.PersonagemItem, error) {
+	var origemItens []models.OrigemItem
+	if err := h.DB.Where("origem_id = ?", origemID).Order("id").Find(&origemItens).Error; err != nil {
+		return nil, err
+	}
+
+	itens := make([]models.PersonagemItem, 0, len(origemItens))
+	for _, origemItem := range origemItens {
+		nome := origemItem.Nome
+
+		var opcoes []string
+		if origemItem.Opcoes != "" {
+			if err := json.Unmarshal([]byte(origemItem.Opcoes), &opcoes); err != nil {
+				return nil, fmt.Errorf("opções inválidas para o item de origem %s", origemItem.Nome)
+			}
+		}
+
+		if len(opcoes) > 0 || origemItem.EscolhaLivre {
+			escolha := strings.TrimSpace(escolhas[origemItem.ID])
+			if escolha == "" {
+				return nil, fmt.Errorf("escolha obrigatória para o item de origem %q (id %d)", origemItem.Nome, origemItem.ID)
+			}
+			if len(opcoes) > 0 {
+				opcao, ok := opcaoCorrespondente(opcoes, escolha)
+				if !ok {
+					return nil, fmt.Errorf("escolha inválida para %q: opções são %s", origemItem.Nome, strings.Join(opcoes, ", "))
+				}
+				escolha = opcao
+			}
+			if len(escolha) > 200 {
+				return nil, fmt.Errorf("escolha para %q excede 200 caracteres", origemItem.Nome)
+			}
+			nome = escolha
+		}
+
+		origemItemID := origemItem.ID
+		item := models.PersonagemItem{
+			Nome:         nome,
+			Tipo:         origemItem.Tipo,
+			Quantidade:   origemItem.Quantidade,
+			Descricao:    origemItem.Descricao,
+			Fonte:        models.FonteItemOrigem,
+			OrigemItemID: &origemItemID,
+		}
+		if item.Quantidade < 1 {
+			item.Quantidade = 1
+		}
+
+		h.vincularCatalogo(&item)
+		itens = append(itens, item)
+	}
+
+	return itens, nil
+}
+
+// resolverKitClasse monta o equipamento inicial da classe e retorna o dinheiro inicial.
+// Cada grupo de escolha usa o item em escolhas (chave = grupo, valor = ID do item do kit);
+// sem escolha, vale a opção padrão do grupo (a primeira cadastrada).
+func (h *PersonagemHandler) resolverKitClasse(classeID uint, escolhas map[int]uint) ([]models.PersonagemItem, float64, error) {
+	var classe models.Classe
+	if err := h.DB.Preload("KitItens", func(db *gorm.DB) *gorm.DB {
+		return db.Order("grupo, id")
+	}).First(&classe, classeID).Error; err != nil {
+		return nil, 0, err
+	}
+
+	grupos := make(map[int][]models.ClasseKitItem)
+	for _, kitItem := range classe.KitItens {
+		grupos[kitItem.Grupo] = append(grupos[kitItem.Grupo], kitItem)
+	}
+
+	for grupo := range escolhas {
+		if grupo == 0 || len(grupos[grupo]) == 0 {
+			return nil, 0, fmt.Errorf("grupo %d não existe no kit da classe %s", grupo, classe.Nome)
+		}
+	}
+
+	escolhidos := make(map[int]uint, len(grupos))
+	for grupo, opcoes := range grupos {
+		if grupo == 0 {
+			continue
+		}
+		escolhido, ok := escolhas[grupo]
+		if !ok {
+			escolhidos[grupo] = opcoes[0].ID
+			continue
+		}
+		valida := false
+		for _, opcao := range opcoes {
+			if opcao.ID == escolhido {
+				valida = true
+				break
+			}
+		}
+		if !valida {
+			return nil, 0, fmt.Errorf("escolha inválida para o grupo %d do kit da classe %s", grupo, classe.Nome)
+		}
+		escolhidos[grupo] = escolhido
+	}
+
+	itens := make([]models.PersonagemItem, 0, len(classe.KitItens))
+	for _, kitItem := range classe.KitItens {
+		if kitItem.Grupo != 0 && escolhidos[kitItem.Grupo] != kitItem.ID {
+			continue
+		}
+
+		item := models.PersonagemItem{
+			Nome:       kitItem.Nome,
+			Tipo:       kitItem.Tipo,
+			Quantidade: kitItem.Quantidade,
+			Descricao:  kitItem.Descricao,
+			Fonte:      models.FonteItemClasse,
+		}
+		if item.Quantidade < 1 {
+			item.Quantidade = 1
+		}
+		h.vincularCatalogo(&item)
+		itens = append(itens, item)
+	}
+
+	return itens, classe.DinheiroInicial, nil
+}
+
+// vincularCatalogo associa um item concedido à entrada do catálogo de mesmo nome,
+// herdando preço e espaços
+func (h *PersonagemHandler) vincularCatalogo(item *models.PersonagemItem) {
+	var catalogo models.ItemCatalogo
+	if err := h.DB.Where("nome = ?", item.Nome).First(&catalogo).Error; err == nil {
+		item.ItemCatalogoID = &catalogo.ID
+		item.Valor = catalogo.Preco
+		item.Peso = catalogo.Espacos
+	}
+}
+
+// concederItensIniciais grava itens de kit (origem ou classe) no inventário do personagem
+func concederItensIniciais(tx *gorm.DB, personagemID uint, itens []models.PersonagemItem) error {
+	if len(itens) == 0 {
+		return nil
+	}
+	for i := range itens {
+		itens[i].ID = 0
+		itens[i].PersonagemID = personagemID
+		itens[i].NormalizarCategoriaArma()
+	}
+	return tx.Create(&itens).Error
+}
+
+// opcaoCorrespondente encontra a opção escolhida sem diferenciar maiúsculas
+func opcaoCorrespondente(opcoes []string, escolha string) (string, bool) {
+	for _, opcao := range opcoes {
+		if strings.EqualFold(opcao, escolha) {
+			return opcao, true
+		}
+	}
+	return "", false
+}
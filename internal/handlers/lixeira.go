@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"tormenta20-builder/internal/config"
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/middleware"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PersonagemLixeira resume um personagem excluído
+type PersonagemLixeira struct {
+	ID         uint       `json:"id"`
+	Nome       string     `json:"nome"`
+	Nivel      int        `json:"nivel"`
+	Raca       string     `json:"raca"`
+	Classe     string     `json:"classe"`
+	ExcluidoEm time.Time  `json:"excluido_em"`
+	ApagaEm    *time.Time `json:"apaga_em"` // nil = a lixeira não é limpa
+}
+
+// GetLixeira lista os personagens excluídos do dono, dos mais recentes para os mais antigos
+func (h *PersonagemHandler) GetLixeira(c *gin.Context) {
+	dono, ok := middleware.EscopoDono(c)
+	if !ok {
+		c.JSON(http.StatusOK, []PersonagemLixeira{})
+		return
+	}
+
+	lixeira := []PersonagemLixeira{}
+	if err := database.DB.Table("personagens").
+		Select("personagens.id, personagens.nome, personagens.nivel, racas.nome AS raca, classes.nome AS classe, " +
+			"personagens.deleted_at AS excluido_em").
+		Joins("LEFT JOIN racas ON racas.id = personagens.raca_id").
+		Joins("LEFT JOIN classes ON classes.id = personagens.classe_id").
+		Scopes(dono).
+		Where("personagens.deleted_at IS NOT NULL").
+		Order("personagens.deleted_at DESC").
+		Scan(&lixeira).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar lixeira")
+		return
+	}
+
+	if retencao := config.GetLixeiraConfig().Retencao; retencao > 0 {
+		for i := range lixeira {
+			apagaEm := lixeira[i].ExcluidoEm.Add(retencao)
+			lixeira[i].ApagaEm = &apagaEm
+		}
+	}
+
+	c.JSON(http.StatusOK, lixeira)
+}
+
+// RestaurarPersonagem tira um personagem da lixeira. Ele volta sem mesa, como rascunho.
+func (h *PersonagemHandler) RestaurarPersonagem(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	dono, ok := middleware.EscopoDono(c)
+	if !ok {
+		h.Response.NotFound(c, "Personagem não encontrado na lixeira")
+		return
+	}
+
+	var personagem models.Personagem
+	if err := database.DB.Unscoped().Scopes(dono).
+		Where("personagens.id = ? AND personagens.deleted_at IS NOT NULL", id).
+		First(&personagem).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.Response.NotFound(c, "Personagem não encontrado na lixeira")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	if err := database.DB.Unscoped().Model(&personagem).Update("deleted_at", nil).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao restaurar personagem")
+		return
+	}
+
+	var restaurado models.Personagem
+	if err := database.DB.Scopes(preloadFicha).First(&restaurado, id).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao carregar personagem restaurado")
+		return
+	}
+	h.loadPersonagemPericias(&restaurado)
+	h.calculatePersonagemStats(&restaurado)
+
+	h.Response.Success(c, restaurado)
+}
+
+// LimparLixeira apaga de vez os personagens excluídos antes de limite. As tabelas filhas
+// (itens, perícias, versões...) saem em cascata pelas chaves estrangeiras.
+func LimparLixeira(db *gorm.DB, limite time.Time) (int64, error) {
+	result := db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", limite).Delete(&models.Personagem{})
+	return result.RowsAffected, result.Error
+}
+
+// IniciarLimpezaLixeira limpa a lixeira periodicamente (LIXEIRA_INTERVALO_MINUTOS) até o
+// contexto ser cancelado. Com LIXEIRA_RETENCAO_DIAS=0 a lixeira nunca é limpa.
+func IniciarLimpezaLixeira(ctx context.Context) {
+	cfg := config.GetLixeiraConfig()
+	if cfg.Retencao <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(cfg.Intervalo)
+	defer ticker.Stop()
+
+	for {
+		apagados, err := LimparLixeira(database.DB.WithContext(ctx), time.Now().Add(-cfg.Retencao))
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Erro ao limpar lixeira de personagens: %v", err)
+		} else if apagados > 0 {
+			log.Printf("Lixeira: %d personagem(ns) apagado(s) de vez", apagados)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// fracaoPrecoVenda: itens usados são vendidos pela metade do preço
+	fracaoPrecoVenda = 0.5
+	// bonusNegociacao: o poder de origem Negociação vende itens 10% mais caro
+	bonusNegociacao = 0.10
+	// maxItensPorPersonagem acompanha o limite validado no PUT da ficha
+	maxItensPorPersonagem = 50
+)
+
+var errLimiteItens = errors.New("limite de itens por personagem atingido")
+
+// CompraRequest representa a compra de um item do catálogo
+type CompraRequest struct {
+	ItemCatalogoID uint `json:"item_catalogo_id" binding:"required,min=1"`
+	Quantidade     int  `json:"quantidade" binding:"omitempty,min=1,max=999"`
+}
+
+// VendaRequest representa a venda de um item do inventário
+type VendaRequest struct {
+	ItemID     uint `json:"item_id" binding:"required,min=1"`
+	Quantidade int  `json:"quantidade" binding:"omitempty,min=1,max=9999"`
+}
+
+// tipoItemPorCategoria converte a categoria do catálogo no tipo usado em personagem_itens
+func tipoItemPorCategoria(categoria string) string {
+	if categoria == models.CategoriaItemGeral {
+		return "item"
+	}
+	return categoria
+}
+
+// precoVendaUnitario calcula quanto o personagem recebe por unidade vendida e quais
+// modificadores foram aplicados. Só itens do catálogo têm revenda, sempre pelo preço do
+// catálogo: o valor de itens manuais é livre, e melhorias e encantos não são pagos.
+func (h *PersonagemHandler) precoVendaUnitario(personagemID uint, item *models.PersonagemItem) (float64, []string, bool) {
+	if item.ItemCatalogoID == nil {
+		return 0, nil, false
+	}
+	var catalogo models.ItemCatalogo
+	if err := h.DB.First(&catalogo, *item.ItemCatalogoID).Error; err != nil {
+		return 0, nil, false
+	}
+
+	preco := catalogo.Preco * fracaoPrecoVenda
+	modificadores := []string{}
+
+	if h.personagemPossuiPoder(personagemID, "Negociação") {
+		preco *= 1 + bonusNegociacao
+		modificadores = append(modificadores, "Negociação (+10%)")
+	}
+
+	return arredondarTibares(preco), modificadores, true
+}
+
+// adicionarItemCatalogo coloca unidades de um item do catálogo no inventário. Itens do mesmo
+// catálogo e da mesma fonte são empilhados na mesma linha, exceto itens superiores ou mágicos.
+func adicionarItemCatalogo(tx *gorm.DB, personagemID uint, catalogo *models.ItemCatalogo, quantidade int, fonte string) (*models.PersonagemItem, error) {
+	var item models.PersonagemItem
+	err := gorm.ErrRecordNotFound
+	// Containers (mochilas) nunca são empilhados
+	if catalogo.Capacidade == 0 {
+		err = tx.Where("personagem_id = ? AND item_catalogo_id = ? AND fonte = ?", personagemID, catalogo.ID, fonte).
+			Where("NOT EXISTS (SELECT 1 FROM personagem_item_melhorias m WHERE m.personagem_item_id = personagem_itens.id)").
+			Where("NOT EXISTS (SELECT 1 FROM personagem_item_encantos e WHERE e.personagem_item_id = personagem_itens.id)").
+			First(&item).Error
+	}
+	switch {
+	case err == nil:
+		if err := tx.Model(&item).
+			Update("quantidade", gorm.Expr("quantidade + ?", quantidade)).Error; err != nil {
+			return nil, err
+		}
+		item.Quantidade += quantidade
+		return &item, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		var count int64
+		if err := tx.Model(&models.PersonagemItem{}).Where("personagem_id = ?", personagemID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count >= maxItensPorPersonagem {
+			return nil, errLimiteItens
+		}
+
+		item = models.PersonagemItem{
+			PersonagemID:   personagemID,
+			Nome:           catalogo.Nome,
+			Tipo:           tipoItemPorCategoria(catalogo.Categoria),
+			Quantidade:     quantidade,
+			Peso:           catalogo.Espacos,
+			Valor:          catalogo.Preco,
+			Descricao:      catalogo.Descricao,
+			ItemCatalogoID: &catalogo.ID,
+			Fonte:          fonte,
+			Capacidade:     catalogo.Capacidade,
+			Ordem:          proximaOrdem(tx, personagemID),
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return nil, err
+		}
+		return &item, nil
+	default:
+		return nil, err
+	}
+}
+
+// ComprarItem compra um item do catálogo, debitando o preço e adicionando-o ao inventário.
+// Itens do mesmo catálogo são empilhados na mesma linha.
+func (h *PersonagemHandler) ComprarItem(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req CompraRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+	if req.Quantidade == 0 {
+		req.Quantidade = 1
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var catalogo models.ItemCatalogo
+	if err := database.DB.First(&catalogo, req.ItemCatalogoID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Item não encontrado no catálogo")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar item do catálogo")
+		}
+		return
+	}
+
+	total := arredondarTibares(catalogo.Preco * float64(req.Quantidade))
+
+	var item *models.PersonagemItem
+	var transacao *models.PersonagemTransacao
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		item, err = adicionarItemCatalogo(tx, uint(id), &catalogo, req.Quantidade, models.FonteItemLoja)
+		if err != nil {
+			return err
+		}
+
+		motivo := fmt.Sprintf("Compra: %s (x%d)", catalogo.Nome, req.Quantidade)
+		transacao, err = registrarTransacao(tx, uint(id), -total, motivo, &item.ID)
+		if err != nil {
+			return err
+		}
+
+		return tx.First(item, item.ID).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSaldoInsuficiente):
+			h.Response.BadRequest(c, fmt.Sprintf("Saldo insuficiente: a compra custa T$ %.2f", total))
+		case errors.Is(err, errLimiteItens):
+			h.Response.BadRequest(c, fmt.Sprintf("máximo de %d itens por personagem", maxItensPorPersonagem))
+		default:
+			h.Response.InternalError(c, "Erro ao comprar item")
+		}
+		return
+	}
+
+	h.Response.Created(c, gin.H{
+		"item":      item,
+		"transacao": transacao,
+		"total":     total,
+	})
+}
+
+// VenderItem vende um item do inventário pela metade do preço (mais modificadores) e
+// credita o valor. O item é removido quando a quantidade chega a zero.
+func (h *PersonagemHandler) VenderItem(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req VendaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+	if req.Quantidade == 0 {
+		req.Quantidade = 1
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var item models.PersonagemItem
+	if err := database.DB.Where("id = ? AND personagem_id = ?", req.ItemID, id).First(&item).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Item não encontrado no inventário")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar item")
+		}
+		return
+	}
+
+	if req.Quantidade > item.Quantidade {
+		h.Response.BadRequest(c, fmt.Sprintf("O personagem possui apenas %d unidade(s) deste item", item.Quantidade))
+		return
+	}
+
+	precoUnitario, modificadores, ok := h.precoVendaUnitario(uint(id), &item)
+	if !ok {
+		h.Response.BadRequest(c, "Só itens do catálogo podem ser vendidos")
+		return
+	}
+	total := arredondarTibares(precoUnitario * float64(req.Quantidade))
+
+	var transacao *models.PersonagemTransacao
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Decremento condicionado à quantidade atual evita vender o mesmo item duas vezes
+		result := tx.Model(&models.PersonagemItem{}).
+			Where("id = ? AND quantidade >= ?", item.ID, req.Quantidade).
+			Update("quantidade", gorm.Expr("quantidade - ?", req.Quantidade))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		itemID := &item.ID
+		if req.Quantidade == item.Quantidade {
+			if err := tx.Delete(&models.PersonagemItem{}, item.ID).Error; err != nil {
+				return err
+			}
+			itemID = nil
+		}
+
+		motivo := fmt.Sprintf("Venda: %s (x%d)", item.Nome, req.Quantidade)
+		var err error
+		transacao, err = registrarTransacao(tx, uint(id), total, motivo, itemID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.Response.BadRequest(c, "Quantidade do item mudou durante a venda, tente novamente")
+		} else {
+			h.Response.InternalError(c, "Erro ao vender item")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transacao":      transacao,
+		"preco_unitario": precoUnitario,
+		"total":          total,
+		"modificadores":  modificadores,
+	})
+}
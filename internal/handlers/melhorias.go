@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MelhoriaHandler struct {
+	*GenericService
+}
+
+func NewMelhoriaHandler() *MelhoriaHandler {
+	return &MelhoriaHandler{
+		GenericService: NewGenericService(database.DB),
+	}
+}
+
+func (h *MelhoriaHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	melhorias := rg.Group("/melhorias")
+	{
+		melhorias.GET("", h.GetAllMelhorias)
+		melhorias.GET("/:id", h.GetMelhoria)
+
+		escrita := escritaCatalogo(melhorias)
+		escrita.POST("", h.CreateMelhoria)
+		escrita.PUT("/:id", h.UpdateMelhoria)
+		escrita.DELETE("/:id", h.DeleteMelhoria)
+	}
+
+	encantos := rg.Group("/encantos")
+	{
+		encantos.GET("", h.GetAllEncantos)
+		encantos.GET("/:id", h.GetEncanto)
+
+		escrita := escritaCatalogo(encantos)
+		escrita.POST("", h.CreateEncanto)
+		escrita.PUT("/:id", h.UpdateEncanto)
+		escrita.DELETE("/:id", h.DeleteEncanto)
+	}
+}
+
+// GetAllMelhorias lista as melhorias, com filtro opcional pelo tipo de item (?aplica_em=arma)
+func (h *MelhoriaHandler) GetAllMelhorias(c *gin.Context) {
+	query := database.DB.Order("nome")
+	if tipo := c.Query("aplica_em"); tipo != "" {
+		query = query.Where("aplica_em ILIKE ?", "%"+tipo+"%")
+	}
+
+	var melhorias []models.Melhoria
+	if err := query.Find(&melhorias).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar melhorias")
+		return
+	}
+
+	c.JSON(http.StatusOK, melhorias)
+}
+
+func (h *MelhoriaHandler) GetMelhoria(c *gin.Context) {
+	var melhoria models.Melhoria
+	h.GetByID(c, &melhoria, "Melhoria não encontrada")
+}
+
+func (h *MelhoriaHandler) CreateMelhoria(c *gin.Context) {
+	var melhoria models.Melhoria
+	h.Create(c, &melhoria)
+}
+
+func (h *MelhoriaHandler) UpdateMelhoria(c *gin.Context) {
+	var melhoria models.Melhoria
+	h.Update(c, &melhoria, "Melhoria não encontrada")
+}
+
+func (h *MelhoriaHandler) DeleteMelhoria(c *gin.Context) {
+	var melhoria models.Melhoria
+	h.Delete(c, &melhoria, "Melhoria não encontrada")
+}
+
+// GetAllEncantos lista os encantos, com filtro opcional pelo tipo de item (?aplica_em=armadura)
+func (h *MelhoriaHandler) GetAllEncantos(c *gin.Context) {
+	query := database.DB.Order("nome")
+	if tipo := c.Query("aplica_em"); tipo != "" {
+		query = query.Where("aplica_em ILIKE ?", "%"+tipo+"%")
+	}
+
+	var encantos []models.Encanto
+	if err := query.Find(&encantos).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar encantos")
+		return
+	}
+
+	c.JSON(http.StatusOK, encantos)
+}
+
+func (h *MelhoriaHandler) GetEncanto(c *gin.Context) {
+	var encanto models.Encanto
+	h.GetByID(c, &encanto, "Encanto não encontrado")
+}
+
+func (h *MelhoriaHandler) CreateEncanto(c *gin.Context) {
+	var encanto models.Encanto
+	h.Create(c, &encanto)
+}
+
+func (h *MelhoriaHandler) UpdateEncanto(c *gin.Context) {
+	var encanto models.Encanto
+	h.Update(c, &encanto, "Encanto não encontrado")
+}
+
+func (h *MelhoriaHandler) DeleteEncanto(c *gin.Context) {
+	var encanto models.Encanto
+	h.Delete(c, &encanto, "Encanto não encontrado")
+}
@@ -0,0 +1,503 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/middleware"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxMembrosMesa limita quantos usuários (mestre incluso) participam de uma mesa
+const maxMembrosMesa = 20
+
+var (
+	errMesaCheia       = errors.New("a mesa já está cheia")
+	errJaMembro        = errors.New("você já participa desta mesa")
+	errConviteInvalido = errors.New("código de convite inválido")
+)
+
+// MesaHandler gerencia mesas (campanhas), seus membros e o vínculo dos personagens
+type MesaHandler struct {
+	*GenericService
+}
+
+func NewMesaHandler() *MesaHandler {
+	return &MesaHandler{
+		GenericService: NewGenericService(database.DB),
+	}
+}
+
+// MesaRequest representa a criação ou edição de uma mesa
+type MesaRequest struct {
+	Nome        string `json:"nome" binding:"required,min=1,max=100"`
+	Descricao   string `json:"descricao" binding:"max=5000"`
+	MestreEdita bool   `json:"mestre_edita"`
+}
+
+// EntrarMesaRequest representa o uso de um código de convite
+type EntrarMesaRequest struct {
+	Codigo string `json:"codigo" binding:"required,min=8,max=20"`
+}
+
+// VincularMesaRequest vincula um personagem a uma mesa (nil desvincula)
+type VincularMesaRequest struct {
+	MesaID *uint `json:"mesa_id"`
+}
+
+// PersonagemGrupo é o resumo de um personagem no grupo da mesa
+type PersonagemGrupo struct {
+	ID        uint   `json:"id"`
+	Nome      string `json:"nome"`
+	Nivel     int    `json:"nivel"`
+	Raca      string `json:"raca"`
+	Classe    string `json:"classe"`
+	UsuarioID uint   `json:"usuario_id"`
+	Jogador   string `json:"jogador"`
+}
+
+func (h *MesaHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	mesas := rg.Group("/mesas", middleware.RequireAuth())
+	{
+		mesas.GET("", h.GetMesas)
+		mesas.POST("", h.CreateMesa)
+		mesas.POST("/entrar", h.EntrarMesa)
+		mesas.GET("/:id", h.GetMesa)
+		mesas.PUT("/:id", h.UpdateMesa)
+		mesas.DELETE("/:id", h.DeleteMesa)
+		mesas.POST("/:id/convite", h.RenovarConvite)
+		mesas.GET("/:id/personagens", h.GetGrupo)
+		mesas.GET("/:id/regras", h.GetRegras)
+		mesas.PUT("/:id/regras", h.UpdateRegras)
+		mesas.POST("/:id/personagens/:personagem_id/aprovar", h.AprovarPersonagem)
+		mesas.POST("/:id/personagens/:personagem_id/rejeitar", h.RejeitarPersonagem)
+		mesas.POST("/:id/personagens/:personagem_id/bloquear", h.BloquearPersonagem)
+		mesas.POST("/:id/personagens/:personagem_id/desbloquear", h.DesbloquearPersonagem)
+		mesas.DELETE("/:id/membros/:usuario_id", h.RemoverMembro)
+	}
+
+	rg.PUT("/personagens/:id/mesa", middleware.RequireAuth(), middleware.CarregarPersonagem(), middleware.FichaEditavel(), h.VincularPersonagem)
+}
+
+// findMembro retorna a participação do usuário logado na mesa
+func (h *MesaHandler) findMembro(c *gin.Context, mesaID uint) (*models.MesaMembro, error) {
+	usuarioID, _ := middleware.GetUsuarioID(c)
+	var membro models.MesaMembro
+	err := h.DB.Where("mesa_id = ? AND usuario_id = ?", mesaID, usuarioID).First(&membro).Error
+	return &membro, err
+}
+
+// findMesaMembro carrega a mesa se o usuário logado participa dela, respondendo 404 caso
+// contrário. Com apenasMestre, jogadores recebem 403.
+func (h *MesaHandler) findMesaMembro(c *gin.Context, apenasMestre bool) (*models.Mesa, *models.MesaMembro, bool) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return nil, nil, false
+	}
+
+	membro, err := h.findMembro(c, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.Response.NotFound(c, "Mesa não encontrada")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar mesa")
+		}
+		return nil, nil, false
+	}
+	if apenasMestre && membro.Papel != models.PapelMesaMestre {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Apenas o mestre pode fazer isso"})
+		return nil, nil, false
+	}
+
+	var mesa models.Mesa
+	if err := h.DB.First(&mesa, id).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar mesa")
+		return nil, nil, false
+	}
+	if membro.Papel != models.PapelMesaMestre {
+		mesa.CodigoConvite = ""
+	}
+
+	return &mesa, membro, true
+}
+
+// carregarMembros busca os membros da mesa com o nome de cada usuário
+func (h *MesaHandler) carregarMembros(mesa *models.Mesa) error {
+	return h.DB.Table("mesa_membros").
+		Select("mesa_membros.*, usuarios.nome").
+		Joins("JOIN usuarios ON usuarios.id = mesa_membros.usuario_id").
+		Where("mesa_membros.mesa_id = ?", mesa.ID).
+		Order("mesa_membros.id").
+		Find(&mesa.Membros).Error
+}
+
+// GetMesas lista as mesas das quais o usuário participa
+func (h *MesaHandler) GetMesas(c *gin.Context) {
+	usuarioID, _ := middleware.GetUsuarioID(c)
+
+	var mesas []models.Mesa
+	if err := h.DB.Where("id IN (?)", h.DB.Model(&models.MesaMembro{}).Select("mesa_id").Where("usuario_id = ?", usuarioID)).
+		Order("id").Find(&mesas).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar mesas")
+		return
+	}
+
+	for i := range mesas {
+		if err := h.carregarMembros(&mesas[i]); err != nil {
+			h.Response.InternalError(c, "Erro ao buscar mesas")
+			return
+		}
+		if !ehMestre(mesas[i].Membros, usuarioID) {
+			mesas[i].CodigoConvite = ""
+		}
+	}
+
+	c.JSON(http.StatusOK, mesas)
+}
+
+// ehMestre indica se o usuário é mestre entre os membros informados
+func ehMestre(membros []models.MesaMembro, usuarioID uint) bool {
+	for _, m := range membros {
+		if m.UsuarioID == usuarioID && m.Papel == models.PapelMesaMestre {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMesa retorna a mesa com seus membros
+func (h *MesaHandler) GetMesa(c *gin.Context) {
+	mesa, _, ok := h.findMesaMembro(c, false)
+	if !ok {
+		return
+	}
+
+	if err := h.carregarMembros(mesa); err != nil {
+		h.Response.InternalError(c, "Erro ao buscar membros")
+		return
+	}
+
+	h.Response.Success(c, mesa)
+}
+
+// CreateMesa cria uma mesa com o usuário logado como mestre
+func (h *MesaHandler) CreateMesa(c *gin.Context) {
+	var req MesaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	codigo, err := gerarCodigo()
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao criar mesa")
+		return
+	}
+
+	usuarioID, _ := middleware.GetUsuarioID(c)
+	mesa := models.Mesa{
+		Nome:          strings.TrimSpace(req.Nome),
+		Descricao:     req.Descricao,
+		CodigoConvite: codigo,
+		MestreEdita:   req.MestreEdita,
+	}
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Membros").Create(&mesa).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.MesaMembro{MesaID: mesa.ID, UsuarioID: usuarioID, Papel: models.PapelMesaMestre}).Error
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao criar mesa")
+		return
+	}
+
+	if err := h.carregarMembros(&mesa); err != nil {
+		h.Response.InternalError(c, "Erro ao buscar membros")
+		return
+	}
+
+	h.Response.Created(c, mesa)
+}
+
+// UpdateMesa altera nome, descrição e a permissão de edição do mestre
+func (h *MesaHandler) UpdateMesa(c *gin.Context) {
+	mesa, _, ok := h.findMesaMembro(c, true)
+	if !ok {
+		return
+	}
+
+	var req MesaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	mesa.Nome = strings.TrimSpace(req.Nome)
+	mesa.Descricao = req.Descricao
+	mesa.MestreEdita = req.MestreEdita
+	if err := h.DB.Model(mesa).Select("nome", "descricao", "mestre_edita").Updates(mesa).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao atualizar mesa")
+		return
+	}
+
+	h.Response.Success(c, mesa)
+}
+
+// DeleteMesa encerra a mesa; os personagens continuam com seus donos, desvinculados e de
+// volta a rascunho
+func (h *MesaHandler) DeleteMesa(c *gin.Context) {
+	mesa, membro, ok := h.findMesaMembro(c, true)
+	if !ok {
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var personagemIDs []uint
+		if err := tx.Model(&models.Personagem{}).Where("mesa_id = ?", mesa.ID).Pluck("id", &personagemIDs).Error; err != nil {
+			return err
+		}
+		if err := sairDaMesa(tx, personagemIDs, nil, &membro.UsuarioID); err != nil {
+			return err
+		}
+		return tx.Delete(mesa).Error
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao deletar mesa")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RenovarConvite gera um novo código de convite; o anterior deixa de funcionar
+func (h *MesaHandler) RenovarConvite(c *gin.Context) {
+	mesa, _, ok := h.findMesaMembro(c, true)
+	if !ok {
+		return
+	}
+
+	codigo, err := gerarCodigo()
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao gerar código")
+		return
+	}
+	if err := h.DB.Model(mesa).Update("codigo_convite", codigo).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao gerar código")
+		return
+	}
+
+	h.Response.Success(c, gin.H{"codigo_convite": codigo})
+}
+
+// EntrarMesa usa um código de convite para entrar na mesa como jogador
+func (h *MesaHandler) EntrarMesa(c *gin.Context) {
+	var req EntrarMesaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	usuarioID, _ := middleware.GetUsuarioID(c)
+	var mesa models.Mesa
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("codigo_convite = ?", normalizarCodigo(req.Codigo)).
+			First(&mesa).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errConviteInvalido
+			}
+			return err
+		}
+
+		var membros int64
+		if err := tx.Model(&models.MesaMembro{}).Where("mesa_id = ?", mesa.ID).Count(&membros).Error; err != nil {
+			return err
+		}
+		var jaMembro int64
+		if err := tx.Model(&models.MesaMembro{}).Where("mesa_id = ? AND usuario_id = ?", mesa.ID, usuarioID).Count(&jaMembro).Error; err != nil {
+			return err
+		}
+		switch {
+		case jaMembro > 0:
+			return errJaMembro
+		case membros >= maxMembrosMesa:
+			return errMesaCheia
+		}
+
+		return tx.Create(&models.MesaMembro{MesaID: mesa.ID, UsuarioID: usuarioID, Papel: models.PapelMesaJogador}).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errConviteInvalido), errors.Is(err, errJaMembro), errors.Is(err, errMesaCheia):
+			h.Response.BadRequest(c, err.Error())
+		default:
+			h.Response.InternalError(c, "Erro ao entrar na mesa")
+		}
+		return
+	}
+
+	mesa.CodigoConvite = ""
+	if err := h.carregarMembros(&mesa); err != nil {
+		h.Response.InternalError(c, "Erro ao buscar membros")
+		return
+	}
+
+	h.Response.Success(c, mesa)
+}
+
+// RemoverMembro tira um jogador da mesa. O mestre remove qualquer jogador; o jogador pode
+// sair por conta própria. Os personagens do jogador são desvinculados da mesa e voltam a rascunho.
+func (h *MesaHandler) RemoverMembro(c *gin.Context) {
+	mesa, membro, ok := h.findMesaMembro(c, false)
+	if !ok {
+		return
+	}
+	alvoID, err := strconv.ParseUint(c.Param("usuario_id"), 10, 32)
+	if err != nil {
+		h.Response.BadRequest(c, "ID do usuário inválido")
+		return
+	}
+
+	if uint(alvoID) != membro.UsuarioID && membro.Papel != models.PapelMesaMestre {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Apenas o mestre pode remover outros jogadores"})
+		return
+	}
+	if uint(alvoID) == membro.UsuarioID && membro.Papel == models.PapelMesaMestre {
+		h.Response.BadRequest(c, "O mestre não pode sair da mesa; delete a mesa para encerrá-la")
+		return
+	}
+
+	var removidos int64
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("mesa_id = ? AND usuario_id = ? AND papel = ?", mesa.ID, alvoID, models.PapelMesaJogador).
+			Delete(&models.MesaMembro{})
+		if result.Error != nil {
+			return result.Error
+		}
+		removidos = result.RowsAffected
+		if removidos == 0 {
+			return nil
+		}
+		var personagemIDs []uint
+		if err := tx.Model(&models.Personagem{}).Where("mesa_id = ? AND usuario_id = ?", mesa.ID, alvoID).
+			Pluck("id", &personagemIDs).Error; err != nil {
+			return err
+		}
+		return sairDaMesa(tx, personagemIDs, nil, &membro.UsuarioID)
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao remover membro")
+		return
+	}
+	if removidos == 0 {
+		h.Response.NotFound(c, "Jogador não encontrado na mesa")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetGrupo lista os personagens vinculados à mesa (o grupo), visível a todos os membros.
+// As fichas completas ficam em /personagens/:id, acessíveis ao dono e ao mestre.
+func (h *MesaHandler) GetGrupo(c *gin.Context) {
+	mesa, _, ok := h.findMesaMembro(c, false)
+	if !ok {
+		return
+	}
+
+	grupo := []PersonagemGrupo{}
+	if err := h.DB.Table("personagens").
+		Select("personagens.id, personagens.nome, personagens.nivel, racas.nome AS raca, classes.nome AS classe, "+
+			"personagens.usuario_id, usuarios.nome AS jogador").
+		Joins("LEFT JOIN racas ON racas.id = personagens.raca_id").
+		Joins("LEFT JOIN classes ON classes.id = personagens.classe_id").
+		Joins("JOIN usuarios ON usuarios.id = personagens.usuario_id").
+		Where("personagens.mesa_id = ? AND personagens.deleted_at IS NULL", mesa.ID).
+		Order("personagens.nome").
+		Scan(&grupo).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar personagens da mesa")
+		return
+	}
+
+	c.JSON(http.StatusOK, grupo)
+}
+
+// VincularPersonagem vincula um personagem da conta a uma mesa da qual o dono participa,
+// ou o desvincula com mesa_id nulo. Só o dono decide; o mestre não move fichas.
+func (h *MesaHandler) VincularPersonagem(c *gin.Context) {
+	personagem, ok := middleware.GetPersonagem(c)
+	if !ok {
+		h.Response.NotFound(c, "Personagem não encontrado")
+		return
+	}
+	if !middleware.EhDono(c, personagem) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Apenas o dono pode vincular o personagem a uma mesa"})
+		return
+	}
+
+	var req VincularMesaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	// Revincular à mesma mesa não mexe no estado da ficha
+	mesmaMesa := req.MesaID != nil && personagem.MesaID != nil && *req.MesaID == *personagem.MesaID
+
+	if req.MesaID != nil {
+		if _, err := h.findMembro(c, *req.MesaID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				h.Response.BadRequest(c, "Você não participa desta mesa")
+			} else {
+				h.Response.InternalError(c, "Erro ao buscar mesa")
+			}
+			return
+		}
+
+		// O personagem precisa respeitar as regras da casa da nova mesa
+		regras, err := carregarRegras(h.DB, req.MesaID)
+		if err != nil {
+			h.Response.InternalError(c, "Erro ao buscar regras da mesa")
+			return
+		}
+		if err := validarEscolhasMesa(h.DB, &regras, personagem.RacaID, personagem.ClasseID, personagem.OrigemID, personagem.DivindadeID); err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+		total := personagem.For + personagem.Des + personagem.Con + personagem.Int + personagem.Sab + personagem.Car
+		if err := validarAtributosMesa(&regras, total); err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+		// Quem chega de fora não pode estar acima do nível em que a mesa começa
+		if !mesmaMesa && personagem.Nivel > regras.NivelInicial {
+			h.Response.BadRequest(c, fmt.Sprintf("personagens desta mesa começam no nível %d", regras.NivelInicial))
+			return
+		}
+	}
+
+	if !mesmaMesa {
+		err := h.DB.Transaction(func(tx *gorm.DB) error {
+			return sairDaMesa(tx, []uint{personagem.ID}, req.MesaID, personagem.UsuarioID)
+		})
+		if err != nil {
+			h.Response.InternalError(c, "Erro ao vincular personagem")
+			return
+		}
+	}
+
+	h.Response.Success(c, gin.H{
+		"personagem_id": personagem.ID,
+		"mesa_id":       req.MesaID,
+	})
+}
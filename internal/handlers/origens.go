@@ -22,9 +22,11 @@ func (h *OrigemHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	{
 		origens.GET("", h.GetAllOrigens)
 		origens.GET("/:id", h.GetOrigem)
-		origens.POST("", h.CreateOrigem)
-		origens.PUT("/:id", h.UpdateOrigem)
-		origens.DELETE("/:id", h.DeleteOrigem)
+
+		escrita := escritaCatalogo(origens)
+		escrita.POST("", h.CreateOrigem)
+		escrita.PUT("/:id", h.UpdateOrigem)
+		escrita.DELETE("/:id", h.DeleteOrigem)
 	}
 }
 
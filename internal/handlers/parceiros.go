@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxParceirosPorPersonagem limita quantos parceiros um personagem pode manter na ficha
+const maxParceirosPorPersonagem = 10
+
+// periciasAjudante é quantas perícias o jogador escolhe para um ajudante
+const periciasAjudante = 2
+
+var (
+	errPericiasParceiro = errors.New("escolha as perícias beneficiadas pelo parceiro")
+	errPericiasAjudante = fmt.Errorf("o ajudante beneficia exatamente %d perícias", periciasAjudante)
+)
+
+// normalizadorTipoParceiro remove acentos para aceitar "guardião" e "guardiao"
+var normalizadorTipoParceiro = strings.NewReplacer("ã", "a", "á", "a", "â", "a", "é", "e", "ê", "e", "í", "i", "ó", "o", "ô", "o", "ú", "u", "ç", "c")
+
+// ParceiroRequest representa a criação ou edição de um parceiro. Bônus omitidos
+// são preenchidos pela tabela de tipos de parceiro de acordo com o patamar.
+type ParceiroRequest struct {
+	Nome         string   `json:"nome" binding:"required,min=1,max=100"`
+	Tipo         string   `json:"tipo" binding:"required,min=1,max=30"`
+	Patamar      string   `json:"patamar" binding:"omitempty,oneof=iniciante veterano mestre"`
+	Fonte        string   `json:"fonte" binding:"max=100"`
+	Ativo        *bool    `json:"ativo"`
+	BonusAtaque  *int     `json:"bonus_ataque" binding:"omitempty,min=0,max=20"`
+	BonusDano    *int     `json:"bonus_dano" binding:"omitempty,min=0,max=20"`
+	BonusDefesa  *int     `json:"bonus_defesa" binding:"omitempty,min=0,max=20"`
+	BonusPericia *int     `json:"bonus_pericia" binding:"omitempty,min=0,max=20"`
+	Pericias     []string `json:"pericias" binding:"max=10,dive,min=1,max=100"`
+	Descricao    *string  `json:"descricao"`
+}
+
+// aplicar preenche o parceiro com a requisição e os bônus padrão do tipo/patamar
+func (r *ParceiroRequest) aplicar(db *gorm.DB, parceiro *models.PersonagemParceiro) error {
+	parceiro.Nome = strings.TrimSpace(r.Nome)
+	parceiro.Tipo = normalizadorTipoParceiro.Replace(strings.ToLower(strings.TrimSpace(r.Tipo)))
+	parceiro.Patamar = r.Patamar
+	if parceiro.Patamar == "" {
+		parceiro.Patamar = models.PatamarParceiroIniciante
+	}
+	parceiro.Fonte = r.Fonte
+	parceiro.Ativo = r.Ativo == nil || *r.Ativo
+
+	// Tipos fora da tabela (parceiros da mesa) usam apenas os bônus enviados
+	var padrao models.ParceiroTipo
+	err := db.Where("tipo = ? AND patamar = ?", parceiro.Tipo, parceiro.Patamar).First(&padrao).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	parceiro.BonusAtaque = valorOuPadrao(r.BonusAtaque, padrao.BonusAtaque)
+	parceiro.BonusDano = valorOuPadrao(r.BonusDano, padrao.BonusDano)
+	parceiro.BonusDefesa = valorOuPadrao(r.BonusDefesa, padrao.BonusDefesa)
+	parceiro.BonusPericia = valorOuPadrao(r.BonusPericia, padrao.BonusPericia)
+	parceiro.Descricao = padrao.Descricao
+	if r.Descricao != nil {
+		parceiro.Descricao = *r.Descricao
+	}
+
+	pericias := uniqueStrings(r.Pericias)
+	// Tipos da tabela sem perícias fixas (ajudante) deixam o jogador escolher duas
+	if padrao.ID != 0 && padrao.Pericias == nil && len(pericias) != periciasAjudante {
+		return errPericiasAjudante
+	}
+	if len(pericias) == 0 && padrao.Pericias != nil {
+		if err := json.Unmarshal([]byte(*padrao.Pericias), &pericias); err != nil {
+			return err
+		}
+	}
+	if len(pericias) == 0 && parceiro.BonusPericia > 0 {
+		return errPericiasParceiro
+	}
+	if len(pericias) > 0 {
+		var count int64
+		if err := db.Model(&models.Pericia{}).Where("nome IN ?", pericias).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(pericias) {
+			return fmt.Errorf("perícia inválida para o parceiro: %s", strings.Join(pericias, ", "))
+		}
+	}
+
+	periciasJSON, err := json.Marshal(pericias)
+	if err != nil {
+		return err
+	}
+	if pericias == nil {
+		periciasJSON = []byte("[]")
+	}
+	parceiro.Pericias = string(periciasJSON)
+	return nil
+}
+
+// valorOuPadrao retorna o valor enviado ou, se omitido, o padrão da tabela
+func valorOuPadrao(valor *int, padrao int) int {
+	if valor != nil {
+		return *valor
+	}
+	return padrao
+}
+
+// uniqueStrings remove nomes repetidos ou vazios mantendo a ordem
+func uniqueStrings(valores []string) []string {
+	vistos := make(map[string]bool, len(valores))
+	var result []string
+	for _, v := range valores {
+		v = strings.TrimSpace(v)
+		if v == "" || vistos[v] {
+			continue
+		}
+		vistos[v] = true
+		result = append(result, v)
+	}
+	return result
+}
+
+// carregarParceiros busca os parceiros do personagem se ainda não foram carregados
+func (h *PersonagemHandler) carregarParceiros(personagem *models.Personagem) {
+	if personagem.Parceiros != nil || personagem.ID == 0 {
+		return
+	}
+	var parceiros []models.PersonagemParceiro
+	if err := h.DB.Where("personagem_id = ?", personagem.ID).Order("id").Find(&parceiros).Error; err == nil {
+		personagem.Parceiros = parceiros
+	}
+}
+
+// GetTiposParceiro lista os tipos de parceiro com os bônus de cada patamar
+func (h *PersonagemHandler) GetTiposParceiro(c *gin.Context) {
+	var tipos []models.ParceiroTipo
+	if err := h.DB.Order("tipo, id").Find(&tipos).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar tipos de parceiro")
+		return
+	}
+
+	c.JSON(http.StatusOK, tipos)
+}
+
+// GetParceiros lista os parceiros de um personagem
+func (h *PersonagemHandler) GetParceiros(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var parceiros []models.PersonagemParceiro
+	if err := database.DB.Where("personagem_id = ?", id).Order("id").Find(&parceiros).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar parceiros")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"parceiros": parceiros,
+		"bonus":     models.CalcularBonusParceiros(parceiros),
+	})
+}
+
+// CreateParceiro adiciona um parceiro ao personagem
+func (h *PersonagemHandler) CreateParceiro(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req ParceiroRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.PersonagemParceiro{}).Where("personagem_id = ?", id).Count(&count).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao adicionar parceiro")
+		return
+	}
+	if count >= maxParceirosPorPersonagem {
+		h.Response.BadRequest(c, fmt.Sprintf("máximo de %d parceiros por personagem", maxParceirosPorPersonagem))
+		return
+	}
+
+	parceiro := models.PersonagemParceiro{PersonagemID: uint(id)}
+	if err := req.aplicar(database.DB, &parceiro); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := garantirVersaoBase(c, parceiro.PersonagemID); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+	if err := database.DB.Create(&parceiro).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao adicionar parceiro")
+		return
+	}
+	if err := registrarVersao(c, parceiro.PersonagemID, models.VersaoEdicao); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+
+	h.Response.Created(c, parceiro)
+}
+
+// UpdateParceiro substitui os dados de um parceiro; bônus omitidos voltam ao padrão do patamar
+func (h *PersonagemHandler) UpdateParceiro(c *gin.Context) {
+	var req ParceiroRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	parceiro, ok := h.findParceiroPersonagem(c)
+	if !ok {
+		return
+	}
+
+	if err := req.aplicar(database.DB, parceiro); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := garantirVersaoBase(c, parceiro.PersonagemID); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+	if err := database.DB.Save(parceiro).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao atualizar parceiro")
+		return
+	}
+	if err := registrarVersao(c, parceiro.PersonagemID, models.VersaoEdicao); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+
+	h.Response.Success(c, parceiro)
+}
+
+// DeleteParceiro remove um parceiro do personagem
+func (h *PersonagemHandler) DeleteParceiro(c *gin.Context) {
+	parceiro, ok := h.findParceiroPersonagem(c)
+	if !ok {
+		return
+	}
+
+	if err := garantirVersaoBase(c, parceiro.PersonagemID); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+	if err := database.DB.Delete(&models.PersonagemParceiro{}, parceiro.ID).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao remover parceiro")
+		return
+	}
+	if err := registrarVersao(c, parceiro.PersonagemID, models.VersaoEdicao); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// findParceiroPersonagem busca um parceiro de um personagem do usuário
+func (h *PersonagemHandler) findParceiroPersonagem(c *gin.Context) (*models.PersonagemParceiro, bool) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return nil, false
+	}
+	parceiroID, err := strconv.ParseUint(c.Param("parceiro_id"), 10, 32)
+	if err != nil {
+		h.Response.BadRequest(c, "ID do parceiro inválido")
+		return nil, false
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return nil, false
+	}
+
+	var parceiro models.PersonagemParceiro
+	if err := database.DB.Where("id = ? AND personagem_id = ?", parceiroID, id).First(&parceiro).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Parceiro não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar parceiro")
+		}
+		return nil, false
+	}
+
+	return &parceiro, true
+}
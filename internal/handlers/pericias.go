@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"tormenta20-builder/internal/middleware"
 	"tormenta20-builder/internal/models"
 
 	"github.com/gin-gonic/gin"
@@ -128,11 +129,12 @@ func (h *PericiasHandler) GetPericiasOrigem(c *gin.Context) {
 
 // POST /personagens/:id/pericias - Atualiza perícias do personagem
 func (h *PericiasHandler) UpdatePericiasPersonagem(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+	carregado, ok := middleware.GetPersonagem(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Personagem não encontrado"})
 		return
 	}
+	id := carregado.ID
 
 	var request struct {
 		PericiasIDs []uint `json:"pericias_ids"`
@@ -282,11 +284,12 @@ func (h *PericiasHandler) UpdatePericiasPersonagem(c *gin.Context) {
 
 // GET /personagens/:id/pericias - Busca perícias selecionadas do personagem
 func (h *PericiasHandler) GetPericiasPersonagem(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+	carregado, ok := middleware.GetPersonagem(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Personagem não encontrado"})
 		return
 	}
+	id := carregado.ID
 
 	// Buscar personagem com suas perícias
 	var personagem models.Personagem
@@ -310,3 +313,21 @@ func (h *PericiasHandler) GetPericiasPersonagem(c *gin.Context) {
 		"pericias_ids":  periciasIds,
 	})
 }
+
+// POST /pericias - Cria uma perícia (apenas administradores)
+func (h *PericiasHandler) CreatePericia(c *gin.Context) {
+	var pericia models.Pericia
+	NewGenericService(h.db).Create(c, &pericia)
+}
+
+// PUT /pericias/:id - Atualiza uma perícia (apenas administradores)
+func (h *PericiasHandler) UpdatePericia(c *gin.Context) {
+	var pericia models.Pericia
+	NewGenericService(h.db).Update(c, &pericia, "Perícia não encontrada")
+}
+
+// DELETE /pericias/:id - Remove uma perícia (apenas administradores)
+func (h *PericiasHandler) DeletePericia(c *gin.Context) {
+	var pericia models.Pericia
+	NewGenericService(h.db).Delete(c, &pericia, "Perícia não encontrada")
+}
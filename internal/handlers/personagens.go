@@ -3,8 +3,10 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"strings"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jung-kurt/gofpdf"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // PersonagemRequest representa os dados recebidos do frontend
@@ -34,10 +37,12 @@ type PersonagemRequest struct {
 	ClasseID     uint   `json:"classe_id" validate:"required,min=1"`
 	OrigemID     uint   `json:"origem_id" validate:"required,min=1"`
 	DivindadeID  *uint  `json:"divindade_id"`
+	CaminhoID    *uint  `json:"caminho_id"`
 	EscolhasRaca string `json:"escolhas_raca"`
+	Experiencia  *int   `json:"experiencia"` // só na criação; depois, use a concessão de XP
 
 	// Campos extras
-	Dinheiro  *float64               `json:"dinheiro"`
+	Dinheiro  *float64               `json:"dinheiro"` // só na criação; depois, use o livro-caixa
 	Anotacoes *string                `json:"anotacoes"`
 	Historico *string                `json:"historico"`
 	Itens     []models.PersonagemItem `json:"itens"`
@@ -50,6 +55,14 @@ type PersonagemRequest struct {
 
 	BeneficiosOrigemPericias []uint `json:"beneficios_origem_pericias"`
 	BeneficiosOrigemPoderes  []uint `json:"beneficios_origem_poderes"`
+
+	// Escolhas dos itens iniciais da origem (chave = ID do item da origem)
+	EscolhasItensOrigem map[uint]string `json:"escolhas_itens_origem"`
+	// Escolhas do kit de classe (chave = grupo, valor = ID do item do kit)
+	EscolhasKitClasse map[int]uint `json:"escolhas_kit_classe"`
+
+	// Mesa em que o personagem é criado (só na criação; depois use PUT /personagens/:id/mesa)
+	MesaID *uint `json:"mesa_id"`
 }
 
 type PersonagemHandler struct {
@@ -62,18 +75,13 @@ func NewPersonagemHandler() *PersonagemHandler {
 	}
 }
 
-// validateRequest valida todos os campos do PersonagemRequest server-side.
-// Nao confia em NADA que venha do frontend.
-func (h *PersonagemHandler) validateRequest(req *PersonagemRequest) error {
+// validateRequest valida todos os campos do PersonagemRequest server-side, seguindo as
+// regras da mesa do personagem (ou as padrão). Nao confia em NADA que venha do frontend.
+func (h *PersonagemHandler) validateRequest(req *PersonagemRequest, regras *models.MesaRegras) error {
 	// 1. Validar atributos: recebemos valores FINAIS (base + racial).
 	// Point-buy base max = 4, racial max = +2, entao maximo razoavel = 6.
-	// Soma total: point-buy 10 pontos base + raciais (~3) = nunca passa de ~30.
-	totalAtributos := req.For + req.Des + req.Con + req.Int + req.Sab + req.Car
-	if totalAtributos > 30 {
-		return fmt.Errorf("soma total de atributos (%d) excede o limite permitido", totalAtributos)
-	}
-	if totalAtributos < -6 {
-		return fmt.Errorf("soma total de atributos inválida")
+	if err := validarAtributosMesa(regras, req.For+req.Des+req.Con+req.Int+req.Sab+req.Car); err != nil {
+		return err
 	}
 
 	// 2. Validar que raca, classe e origem existem
@@ -92,8 +100,14 @@ func (h *PersonagemHandler) validateRequest(req *PersonagemRequest) error {
 			return fmt.Errorf("divindade com ID %d não encontrada", *req.DivindadeID)
 		}
 	}
+	if err := validarEscolhasMesa(database.DB, regras, req.RacaID, req.ClasseID, req.OrigemID, req.DivindadeID); err != nil {
+		return err
+	}
 
-	// 3. Validar dinheiro >= 0
+	// 3. Validar experiência e dinheiro >= 0
+	if req.Experiencia != nil && *req.Experiencia < 0 {
+		return fmt.Errorf("experiência não pode ser negativa")
+	}
 	if req.Dinheiro != nil && *req.Dinheiro < 0 {
 		return fmt.Errorf("dinheiro não pode ser negativo")
 	}
@@ -112,12 +126,18 @@ func (h *PersonagemHandler) validateRequest(req *PersonagemRequest) error {
 		if item.Valor < 0 {
 			return fmt.Errorf("item %d: valor não pode ser negativo", i+1)
 		}
+		if item.Capacidade < 0 {
+			return fmt.Errorf("item %d: capacidade não pode ser negativa", i+1)
+		}
+		if item.CategoriaArma != "" && !models.CategoriaArmaValida(item.CategoriaArma) {
+			return fmt.Errorf("item %d: categoria de arma inválida", i+1)
+		}
 		if len(item.Nome) == 0 || len(item.Nome) > 200 {
 			return fmt.Errorf("item %d: nome deve ter 1-200 caracteres", i+1)
 		}
 	}
-	if len(req.Itens) > 50 {
-		return fmt.Errorf("máximo de 50 itens por personagem")
+	if len(req.Itens) > maxItensPorPersonagem {
+		return fmt.Errorf("máximo de %d itens por personagem", maxItensPorPersonagem)
 	}
 
 	// 5. Validar limite de pericias por classe
@@ -149,77 +169,129 @@ func (h *PersonagemHandler) validateRequest(req *PersonagemRequest) error {
 	return nil
 }
 
-// findPersonagemByUser busca um personagem que pertence ao usuário (por sessão OU IP)
-func (h *PersonagemHandler) findPersonagemByUser(c *gin.Context, personagemID int) (*models.Personagem, error) {
-	sessionID, userIP := middleware.GetUserIdentification(c)
-
-	var personagem models.Personagem
-	query := database.DB
-
-	if sessionID != "" && userIP != "" {
-		// Se tem ambos, busca por qualquer um dos dois (para lidar com sessões que mudam)
-		query = query.Where("id = ? AND (user_session_id = ? OR user_ip = ?)", personagemID, sessionID, userIP)
-	} else if sessionID != "" {
-		query = query.Where("id = ? AND user_session_id = ?", personagemID, sessionID)
-	} else if userIP != "" {
-		query = query.Where("id = ? AND user_ip = ?", personagemID, userIP)
-	} else {
-		return nil, gorm.ErrRecordNotFound
-	}
+// preloadFicha carrega as relações exibidas na ficha completa do personagem
+func preloadFicha(db *gorm.DB) *gorm.DB {
+	return db.Preload("Raca").Preload("Raca.Habilidades").Preload("Classe").Preload("Classe.Habilidades").Preload("Origem").Preload("Origem.Itens").Preload("Divindade").Preload("Caminho").Preload("Parceiros").Preload("Efeitos").Scopes(preloadItens)
+}
 
-	err := query.First(&personagem).Error
-	return &personagem, err
+// findPersonagemByUser busca um personagem que o usuário pode acessar (ver middleware.BuscarPersonagem)
+func (h *PersonagemHandler) findPersonagemByUser(c *gin.Context, personagemID int) (*models.Personagem, error) {
+	return middleware.BuscarPersonagem(c, uint(personagemID))
 }
 
 func (h *PersonagemHandler) RegisterRoutes(rg *gin.RouterGroup) {
-	personagens := rg.Group("/personagens")
+	// Todas as rotas /personagens/:id/* passam pela verificação de acesso ao personagem
+	personagens := rg.Group("/personagens", middleware.CarregarPersonagem())
+	// Alterações na construção da ficha (inclusive o inventário) só valem em rascunho; as
+	// demais rotas de escrita são fluxos de jogo e continuam liberadas
+	edicao := personagens.Group("", middleware.FichaEditavel())
 	{
 		personagens.GET("", h.GetAllPersonagens)
+		personagens.GET("/lixeira", h.GetLixeira)
 		personagens.GET("/:id", h.GetPersonagem)
 		personagens.POST("", h.CreatePersonagem)
-		personagens.PUT("/:id", h.UpdatePersonagem)
+		edicao.PUT("/:id", h.UpdatePersonagem)
 		personagens.DELETE("/:id", h.DeletePersonagem)
 		personagens.POST("/calculate", h.CalculateStats)
 		personagens.GET("/:id/export-pdf", h.ExportToPDF)
 		personagens.GET("/:id/test", h.TestRoute) // Rota de teste
 		// Novos endpoints para poderes
-		personagens.POST("/:id/poderes-divinos", h.SavePoderesDivinos)
-		personagens.POST("/:id/poderes-classe", h.SavePoderesClasse)
+		edicao.POST("/:id/poderes-divinos", h.SavePoderesDivinos)
+		edicao.POST("/:id/poderes-classe", h.SavePoderesClasse)
 		personagens.GET("/:id/poderes-divinos", h.GetPoderesDivinos)
 		personagens.GET("/:id/poderes-classe", h.GetPoderesClasse)
 		// Endpoint para escolhas raciais
-		personagens.POST("/:id/escolhas-raca", h.SaveEscolhasRaca)
+		edicao.POST("/:id/escolhas-raca", h.SaveEscolhasRaca)
 		personagens.GET("/:id/escolhas-raca", h.GetEscolhasRaca)
 		// Endpoint de debug para ver TODOS os personagens (sem filtro de usuário)
 		// personagens.GET("/debug/all", h.GetAllPersonagensDebug)
 		personagens.GET("/:id/beneficios-origem", h.GetBeneficiosOrigem)
-
-	}
+		// Experiência
+		personagens.POST("/experiencia", h.ConcederExperienciaGrupo)
+		personagens.POST("/:id/experiencia", h.ConcederExperiencia)
+		// Livro-caixa (dinheiro)
+		personagens.GET("/:id/transacoes", h.GetTransacoes)
+		personagens.POST("/:id/transacoes/credito", h.CreditarDinheiro)
+		personagens.POST("/:id/transacoes/debito", h.DebitarDinheiro)
+		// Loja (catálogo de equipamento)
+		personagens.POST("/:id/loja/comprar", h.ComprarItem)
+		personagens.POST("/:id/loja/vender", h.VenderItem)
+
+		edicao.PUT("/:id/itens/:item_id/melhorias", h.SetMelhoriasItem)
+		edicao.PUT("/:id/itens/:item_id/encantos", h.SetEncantosItem)
+		personagens.GET("/:id/itens", h.GetItens)
+		edicao.POST("/:id/itens", h.CreateItem)
+		edicao.PUT("/:id/itens/ordem", h.ReordenarItens)
+		edicao.PUT("/:id/itens/:item_id", h.UpdateItem)
+		edicao.DELETE("/:id/itens/:item_id", h.DeleteItem)
+		personagens.POST("/:id/itens/:item_id/usar", h.UsarItem)
+		personagens.GET("/:id/itens/usos", h.GetUsosItens)
+
+		personagens.GET("/:id/fabricacoes", h.GetFabricacoes)
+		personagens.POST("/:id/fabricacoes", h.IniciarFabricacao)
+		personagens.POST("/:id/fabricacoes/frutos-do-trabalho", h.ReceberFrutosDoTrabalho)
+		personagens.POST("/:id/fabricacoes/:fabricacao_id/trabalhar", h.TrabalharFabricacao)
+
+		personagens.GET("/:id/parceiros", h.GetParceiros)
+		edicao.POST("/:id/parceiros", h.CreateParceiro)
+		edicao.PUT("/:id/parceiros/:parceiro_id", h.UpdateParceiro)
+		edicao.DELETE("/:id/parceiros/:parceiro_id", h.DeleteParceiro)
+
+		personagens.POST("/recursos/reiniciar", h.ReiniciarRecursosGrupo)
+		personagens.GET("/:id/recursos", h.GetRecursos)
+		edicao.POST("/:id/recursos", h.CreateRecurso)
+		personagens.POST("/:id/recursos/reiniciar", h.ReiniciarRecursos)
+		edicao.PUT("/:id/recursos/:recurso_id", h.UpdateRecurso)
+		edicao.DELETE("/:id/recursos/:recurso_id", h.DeleteRecurso)
+		personagens.POST("/:id/recursos/:recurso_id/gastar", h.GastarRecurso)
+		personagens.POST("/:id/recursos/:recurso_id/recuperar", h.RecuperarRecurso)
+
+		personagens.POST("/tempo/avancar", h.AvancarTempoGrupo)
+		personagens.POST("/:id/tempo/avancar", h.AvancarTempo)
+		personagens.GET("/:id/efeitos", h.GetEfeitos)
+		personagens.POST("/:id/efeitos", h.CreateEfeito)
+		personagens.DELETE("/:id/efeitos/:efeito_id", h.DeleteEfeito)
+
+		personagens.GET("/:id/compartilhamentos", h.GetCompartilhamentos)
+		personagens.POST("/:id/compartilhar", h.Compartilhar)
+		personagens.DELETE("/:id/compartilhamentos/:compartilhamento_id", h.RevogarCompartilhamento)
+
+		personagens.GET("/:id/regras", h.GetRegrasPersonagem)
+		personagens.POST("/:id/pv/rolar", h.RolarPV)
+		personagens.POST("/:id/dano", h.SofrerDano)
+
+		personagens.POST("/:id/subir-nivel", h.SubirNivel)
+		personagens.POST("/:id/enviar", h.EnviarParaAprovacao)
+		personagens.GET("/:id/estados", h.GetHistoricoEstados)
+
+		personagens.GET("/:id/versoes", h.GetVersoes)
+		personagens.GET("/:id/versoes/:versao", h.GetVersao)
+		personagens.GET("/:id/versoes/:versao/diff", h.GetDiffVersoes)
+		edicao.POST("/:id/versoes/:versao/restaurar", h.RestaurarVersao)
+
+	}
+	// Fora do grupo: CarregarPersonagem não enxerga personagens na lixeira
+	rg.POST("/personagens/:id/restaurar", h.RestaurarPersonagem)
+	rg.GET("/parceiros/tipos", h.GetTiposParceiro)
+
+	// Links públicos de leitura: o token substitui a identificação do dono
+	rg.GET("/compartilhado/:token", h.GetCompartilhado)
+	rg.GET("/compartilhado/:token/pdf", h.ExportCompartilhadoPDF)
 }
 
 func (h *PersonagemHandler) GetAllPersonagens(c *gin.Context) {
 	var personagens []models.Personagem
 
-	// Obtém identificação do usuário
-	sessionID, userIP := middleware.GetUserIdentification(c)
-
 	// Constrói query para filtrar personagens do usuário
-	query := database.DB.Preload("Raca").Preload("Classe").Preload("Origem").Preload("Divindade").Preload("Itens")
-
-	if sessionID != "" && userIP != "" {
-		// Se tem ambos, busca por qualquer um dos dois (para lidar com sessões que mudam)
-		query = query.Where("user_session_id = ? OR user_ip = ?", sessionID, userIP)
-	} else if sessionID != "" {
-		// Prioriza session ID se disponível
-		query = query.Where("user_session_id = ?", sessionID)
-	} else if userIP != "" {
-		// Fallback para IP se não há session
-		query = query.Where("user_ip = ?", userIP)
-	} else {
+	query := database.DB.Preload("Raca").Preload("Classe").Preload("Origem").Preload("Divindade").Preload("Caminho").Preload("Parceiros").Preload("Efeitos").Scopes(preloadItens)
+
+	dono, ok := middleware.EscopoDono(c)
+	if !ok {
 		// Se não há identificação, retorna vazio (não deve acontecer com middleware)
 		c.JSON(http.StatusOK, []models.Personagem{})
 		return
 	}
+	query = query.Scopes(dono)
 
 	if err := query.Find(&personagens).Error; err != nil {
 		h.Response.InternalError(c, "Erro ao buscar personagens: "+err.Error())
@@ -243,24 +315,18 @@ func (h *PersonagemHandler) GetPersonagem(c *gin.Context) {
 	}
 
 	var personagem models.Personagem
-	sessionID, userIP := middleware.GetUserIdentification(c)
-
-	// Constrói query para buscar personagem do usuário
-	query := database.DB.Preload("Raca").Preload("Raca.Habilidades").Preload("Classe").Preload("Classe.Habilidades").Preload("Origem").Preload("Origem.Itens").Preload("Divindade").Preload("Itens")
-
-	if sessionID != "" && userIP != "" {
-		// Se tem ambos, busca por qualquer um dos dois (para lidar com sessões que mudam)
-		query = query.Where("id = ? AND (user_session_id = ? OR user_ip = ?)", id, sessionID, userIP)
-	} else if sessionID != "" {
-		query = query.Where("id = ? AND user_session_id = ?", id, sessionID)
-	} else if userIP != "" {
-		query = query.Where("id = ? AND user_ip = ?", id, userIP)
-	} else {
-		h.Response.NotFound(c, "Personagem não encontrado")
+
+	// Verifica o acesso (dono ou mestre da mesa) antes de carregar a ficha completa
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
 		return
 	}
 
-	if err := query.First(&personagem).Error; err != nil {
+	if err := database.DB.Scopes(preloadFicha).First(&personagem, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			h.Response.NotFound(c, "Personagem não encontrado")
 		} else {
@@ -285,8 +351,52 @@ func (h *PersonagemHandler) CreatePersonagem(c *gin.Context) {
 		return
 	}
 
+	// Personagens criados numa mesa seguem as regras dela; só membros podem criar na mesa
+	if req.MesaID != nil {
+		usuarioID, ok := middleware.GetUsuarioID(c)
+		var membros int64
+		if ok {
+			database.DB.Model(&models.MesaMembro{}).Where("mesa_id = ? AND usuario_id = ?", *req.MesaID, usuarioID).Count(&membros)
+		}
+		if membros == 0 {
+			h.Response.BadRequest(c, "Você não participa desta mesa")
+			return
+		}
+	}
+	regras, err := carregarRegras(database.DB, req.MesaID)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao buscar regras da mesa")
+		return
+	}
+	if req.MesaID != nil && req.Nivel != regras.NivelInicial {
+		h.Response.BadRequest(c, fmt.Sprintf("personagens desta mesa começam no nível %d", regras.NivelInicial))
+		return
+	}
+
 	// Validacao server-side completa - NUNCA confiar no frontend
-	if err := h.validateRequest(&req); err != nil {
+	if err := h.validateRequest(&req, &regras); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	// Itens iniciais da origem são concedidos pelo servidor
+	itensOrigem, err := h.resolverItensOrigem(req.OrigemID, req.EscolhasItensOrigem)
+	if err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+	itensClasse, dinheiroClasse, err := h.resolverKitClasse(req.ClasseID, req.EscolhasKitClasse)
+	if err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+	// Dinheiro inicial da mesa substitui o da classe e não pode ser trocado pelo jogador
+	if regras.DinheiroInicial != nil {
+		dinheiroClasse = *regras.DinheiroInicial
+		req.Dinheiro = nil
+	}
+	caminhoID, err := h.resolverCaminho(req.ClasseID, req.Nivel, req.CaminhoID, nil, true)
+	if err != nil {
 		h.Response.BadRequest(c, err.Error())
 		return
 	}
@@ -304,6 +414,8 @@ func (h *PersonagemHandler) CreatePersonagem(c *gin.Context) {
 		ClasseID:    req.ClasseID,
 		OrigemID:    req.OrigemID,
 		DivindadeID: req.DivindadeID,
+		CaminhoID:   caminhoID,
+		MesaID:      req.MesaID,
 	}
 
 	if req.EscolhasRaca == "" {
@@ -312,10 +424,14 @@ func (h *PersonagemHandler) CreatePersonagem(c *gin.Context) {
 		personagem.EscolhasRaca = req.EscolhasRaca
 	}
 
-	// Campos extras
-	if req.Dinheiro != nil {
-		personagem.Dinheiro = *req.Dinheiro
+	// Experiência: se não informada, começa no mínimo do nível escolhido
+	if req.Experiencia != nil {
+		personagem.Experiencia = *req.Experiencia
+	} else {
+		personagem.Experiencia = models.XPParaNivel(req.Nivel)
 	}
+
+	// Campos extras (dinheiro entra pelo livro-caixa após a criação)
 	if req.Anotacoes != nil {
 		personagem.Anotacoes = *req.Anotacoes
 	}
@@ -334,12 +450,15 @@ func (h *PersonagemHandler) CreatePersonagem(c *gin.Context) {
 		personagem.AtributosLivres = "[]"
 	}
 
-	sessionID, userIP := middleware.GetUserIdentification(c)
-	if sessionID != "" {
+	if sessionID := middleware.GetUserSessionID(c); sessionID != "" {
 		personagem.UserSessionID = &sessionID
 	}
-	if userIP != "" {
-		personagem.UserIP = &userIP
+	if ipHash := middleware.GetUserIPHash(c); ipHash != "" {
+		personagem.UserIPHash = &ipHash
+	}
+	if usuarioID, ok := middleware.GetUsuarioID(c); ok {
+		personagem.UsuarioID = &usuarioID
+		personagem.CreatedByType = "usuario"
 	}
 
 	if err := database.DB.Create(&personagem).Error; err != nil {
@@ -347,6 +466,30 @@ func (h *PersonagemHandler) CreatePersonagem(c *gin.Context) {
 		return
 	}
 
+	// Dinheiro inicial da classe vira a primeira transação do livro-caixa; um valor
+	// informado na requisição substitui o saldo inicial
+	if dinheiroClasse > 0 || (req.Dinheiro != nil && *req.Dinheiro > 0) {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if dinheiroClasse > 0 {
+				descricao := "Dinheiro inicial da classe"
+				if regras.DinheiroInicial != nil {
+					descricao = "Dinheiro inicial da mesa"
+				}
+				if _, err := registrarTransacao(tx, personagem.ID, dinheiroClasse, descricao, nil); err != nil {
+					return err
+				}
+			}
+			if req.Dinheiro != nil && *req.Dinheiro > 0 {
+				return ajustarSaldo(tx, personagem.ID, *req.Dinheiro, "Dinheiro inicial")
+			}
+			return nil
+		})
+		if err != nil {
+			h.Response.InternalError(c, "Erro ao registrar dinheiro inicial")
+			return
+		}
+	}
+
 	// Processar perícias selecionadas
 	if len(req.PericiasSelecionadas) > 0 {
 		var pericias []models.Pericia
@@ -382,16 +525,35 @@ func (h *PersonagemHandler) CreatePersonagem(c *gin.Context) {
 		}
 	}
 
-	// Salvar itens do inventario
-	if len(req.Itens) > 0 {
-		for i := range req.Itens {
-			req.Itens[i].PersonagemID = personagem.ID
-			req.Itens[i].ID = 0
+	// Salvar itens do inventario (itens de kit enviados pelo cliente são ignorados)
+	itensManuais := make([]models.PersonagemItem, 0, len(req.Itens))
+	for _, item := range req.Itens {
+		if item.Fonte == models.FonteItemOrigem || item.Fonte == models.FonteItemClasse {
+			continue
 		}
-		database.DB.Create(&req.Itens)
+		item.PersonagemID = personagem.ID
+		item.ID = 0
+		item.OrigemItemID = nil
+		itensManuais = append(itensManuais, item)
+	}
+	if len(itensManuais) > 0 {
+		database.DB.Omit(clause.Associations).Create(&itensManuais)
+	}
+	if err := concederItensIniciais(database.DB, personagem.ID, itensOrigem); err != nil {
+		h.Response.InternalError(c, "Erro ao conceder itens da origem")
+		return
+	}
+	if err := concederItensIniciais(database.DB, personagem.ID, itensClasse); err != nil {
+		h.Response.InternalError(c, "Erro ao conceder kit da classe")
+		return
 	}
 
-	if err := database.DB.Preload("Raca").Preload("Classe").Preload("Origem").Preload("Divindade").Preload("Itens").First(&personagem, personagem.ID).Error; err != nil {
+	if err := registrarVersao(c, personagem.ID, models.VersaoCriacao); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+
+	if err := database.DB.Preload("Raca").Preload("Classe").Preload("Origem").Preload("Divindade").Preload("Caminho").Preload("Parceiros").Preload("Efeitos").Scopes(preloadItens).First(&personagem, personagem.ID).Error; err != nil {
 		h.Response.InternalError(c, "Erro ao carregar personagem criado")
 		return
 	}
@@ -402,6 +564,15 @@ func (h *PersonagemHandler) CreatePersonagem(c *gin.Context) {
 	h.Response.Created(c, personagem)
 }
 
+// camposEditaveisPersonagem são as colunas que o PUT da ficha grava. Estado, mesa, PV/PM
+// atuais, dinheiro e XP mudam por fluxos próprios e não podem ser sobrescritos com o valor
+// lido no início da requisição.
+var camposEditaveisPersonagem = []string{
+	"nome", "nivel", "for", "des", "con", "int", "sab", "car",
+	"raca_id", "classe_id", "origem_id", "divindade_id", "caminho_id",
+	"escolhas_raca", "atributos_livres", "anotacoes", "historico", "updated_at",
+}
+
 func (h *PersonagemHandler) UpdatePersonagem(c *gin.Context) {
 	id, err := parseID(c)
 	if err != nil {
@@ -425,8 +596,53 @@ func (h *PersonagemHandler) UpdatePersonagem(c *gin.Context) {
 		return
 	}
 
+	regras, err := carregarRegras(database.DB, personagem.MesaID)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao buscar regras da mesa")
+		return
+	}
+
 	// Validacao server-side completa - NUNCA confiar no frontend
-	if err := h.validateRequest(&req); err != nil {
+	if err := h.validateRequest(&req, &regras); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	// Troca de origem substitui o kit de itens da origem anterior
+	trocouOrigem := personagem.OrigemID != req.OrigemID
+	var itensOrigem []models.PersonagemItem
+	if trocouOrigem {
+		itensOrigem, err = h.resolverItensOrigem(req.OrigemID, req.EscolhasItensOrigem)
+		if err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+	}
+	// Troca de classe substitui os itens do kit da classe anterior e ajusta o saldo pela
+	// diferença entre os dinheiros iniciais (sem efeito quando a mesa fixa o dinheiro inicial)
+	trocouClasse := personagem.ClasseID != req.ClasseID
+	var itensClasse []models.PersonagemItem
+	var diferencaDinheiroClasse float64
+	if trocouClasse {
+		var dinheiroClasse float64
+		itensClasse, dinheiroClasse, err = h.resolverKitClasse(req.ClasseID, req.EscolhasKitClasse)
+		if err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+		if regras.DinheiroInicial == nil {
+			var classeAnterior models.Classe
+			if err := h.DB.Select("id", "dinheiro_inicial").First(&classeAnterior, personagem.ClasseID).Error; err != nil {
+				h.Response.InternalError(c, "Erro ao buscar classe anterior")
+				return
+			}
+			diferencaDinheiroClasse = dinheiroClasse - classeAnterior.DinheiroInicial
+		}
+	}
+	// Fichas anteriores aos caminhos só precisam escolher um ao mudar de classe ou nível
+	exigirCaminho := trocouClasse || personagem.Nivel != req.Nivel
+	caminhoID, err := h.resolverCaminho(req.ClasseID, req.Nivel, req.CaminhoID, personagem.CaminhoID, exigirCaminho)
+	if err != nil {
 		h.Response.BadRequest(c, err.Error())
 		return
 	}
@@ -443,6 +659,8 @@ func (h *PersonagemHandler) UpdatePersonagem(c *gin.Context) {
 	personagem.ClasseID = req.ClasseID
 	personagem.OrigemID = req.OrigemID
 	personagem.DivindadeID = req.DivindadeID
+	personagem.CaminhoID = caminhoID
+	personagem.Caminho = nil
 
 	if req.EscolhasRaca == "" {
 		personagem.EscolhasRaca = "{}"
@@ -451,9 +669,6 @@ func (h *PersonagemHandler) UpdatePersonagem(c *gin.Context) {
 	}
 
 	// Campos extras
-	if req.Dinheiro != nil {
-		personagem.Dinheiro = *req.Dinheiro
-	}
 	if req.Anotacoes != nil {
 		personagem.Anotacoes = *req.Anotacoes
 	}
@@ -472,19 +687,49 @@ func (h *PersonagemHandler) UpdatePersonagem(c *gin.Context) {
 		personagem.AtributosLivres = "[]"
 	}
 
-	if err := database.DB.Save(&personagem).Error; err != nil {
-		h.Response.InternalError(c, "Erro ao atualizar personagem")
+	if err := garantirVersaoBase(c, personagem.ID); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
 		return
 	}
 
-	// Atualizar itens: remove antigos, insere novos
-	database.DB.Where("personagem_id = ?", id).Delete(&models.PersonagemItem{})
-	if len(req.Itens) > 0 {
-		for i := range req.Itens {
-			req.Itens[i].PersonagemID = uint(id)
-			req.Itens[i].ID = 0
+	// Só as colunas editáveis são gravadas: dinheiro, XP, PV/PM atuais, estado e mesa
+	// enviados ou lidos antes desfariam o que outros fluxos gravaram nesse meio-tempo.
+	// Atualizar itens: sincroniza com as linhas existentes mantendo IDs estáveis; itens de
+	// kit saem quando a origem ou a classe muda. Um inventário inválido desfaz o PUT inteiro.
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(personagem).Select(camposEditaveisPersonagem).Updates(personagem).Error; err != nil {
+			return err
+		}
+		if diferencaDinheiroClasse != 0 {
+			_, err := registrarTransacao(tx, uint(id), diferencaDinheiroClasse, "Troca de classe: dinheiro inicial do kit", nil)
+			// O saldo já gasto não fica negativo: o débito para em zero
+			if errors.Is(err, ErrSaldoInsuficiente) {
+				err = ajustarSaldo(tx, uint(id), 0, "Troca de classe: dinheiro inicial do kit")
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return sincronizarItens(tx, uint(id), req.Itens, func(item models.PersonagemItem) bool {
+			return (trocouOrigem && item.Fonte == models.FonteItemOrigem) ||
+				(trocouClasse && item.Fonte == models.FonteItemClasse)
+		})
+	})
+	if err != nil {
+		h.respostaErroItem(c, err, "Erro ao atualizar personagem")
+		return
+	}
+	if trocouOrigem {
+		if err := concederItensIniciais(database.DB, uint(id), itensOrigem); err != nil {
+			h.Response.InternalError(c, "Erro ao conceder itens da origem")
+			return
+		}
+	}
+	if trocouClasse {
+		if err := concederItensIniciais(database.DB, uint(id), itensClasse); err != nil {
+			h.Response.InternalError(c, "Erro ao conceder kit da classe")
+			return
 		}
-		database.DB.Create(&req.Itens)
 	}
 
 	// Processar perícias
@@ -524,8 +769,13 @@ func (h *PersonagemHandler) UpdatePersonagem(c *gin.Context) {
 		}
 	}
 
+	if err := registrarVersao(c, personagem.ID, models.VersaoEdicao); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+
 	// Recarregar com itens
-	database.DB.Preload("Itens").First(personagem, id)
+	database.DB.Scopes(preloadItens).First(personagem, id)
 
 	h.loadPersonagemPericias(personagem)
 	h.calculatePersonagemStats(personagem)
@@ -533,6 +783,8 @@ func (h *PersonagemHandler) UpdatePersonagem(c *gin.Context) {
 	h.Response.Success(c, personagem)
 }
 
+// DeletePersonagem move o personagem para a lixeira (exclusão lógica). Ele sai da mesa ao
+// ser excluído e pode ser restaurado até a limpeza periódica apagá-lo de vez.
 func (h *PersonagemHandler) DeletePersonagem(c *gin.Context) {
 	id, err := parseID(c)
 	if err != nil {
@@ -541,21 +793,15 @@ func (h *PersonagemHandler) DeletePersonagem(c *gin.Context) {
 	}
 
 	var personagem models.Personagem
-	sessionID, userIP := middleware.GetUserIdentification(c)
 
 	// Verifica se o personagem existe e pertence ao usuário
 	query := database.DB
-	if sessionID != "" && userIP != "" {
-		// Se tem ambos, busca por qualquer um dos dois (para lidar com sessões que mudam)
-		query = query.Where("id = ? AND (user_session_id = ? OR user_ip = ?)", id, sessionID, userIP)
-	} else if sessionID != "" {
-		query = query.Where("id = ? AND user_session_id = ?", id, sessionID)
-	} else if userIP != "" {
-		query = query.Where("id = ? AND user_ip = ?", id, userIP)
-	} else {
+	dono, ok := middleware.EscopoDono(c)
+	if !ok {
 		h.Response.NotFound(c, "Personagem não encontrado")
 		return
 	}
+	query = query.Scopes(dono).Where("personagens.id = ?", id)
 
 	if err := query.First(&personagem).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -566,7 +812,15 @@ func (h *PersonagemHandler) DeletePersonagem(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Delete(&personagem).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if personagem.MesaID != nil {
+			if err := sairDaMesa(tx, []uint{personagem.ID}, nil, personagem.UsuarioID); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&personagem).Error
+	})
+	if err != nil {
 		h.Response.InternalError(c, "Erro ao deletar personagem")
 		return
 	}
@@ -581,16 +835,18 @@ func (h *PersonagemHandler) DeletePersonagem(c *gin.Context) {
 // Defesa = 10 + mod DES (sem armadura)
 func (h *PersonagemHandler) CalculateStats(c *gin.Context) {
 	var personagemData struct {
-		Nivel        int `json:"nivel"`
-		Forca        int `json:"forca"`
-		Destreza     int `json:"destreza"`
-		Constituicao int `json:"constituicao"`
-		Inteligencia int `json:"inteligencia"`
-		Sabedoria    int `json:"sabedoria"`
-		Carisma      int `json:"carisma"`
-		RacaID       int `json:"raca_id"`
-		ClasseID     int `json:"classe_id"`
-		OrigemID     int `json:"origem_id"`
+		Nivel        int   `json:"nivel"`
+		Forca        int   `json:"forca"`
+		Destreza     int   `json:"destreza"`
+		Constituicao int   `json:"constituicao"`
+		Inteligencia int   `json:"inteligencia"`
+		Sabedoria    int   `json:"sabedoria"`
+		Carisma      int   `json:"carisma"`
+		RacaID       int   `json:"raca_id"`
+		ClasseID     int   `json:"classe_id"`
+		OrigemID     int   `json:"origem_id"`
+		CaminhoID    *uint `json:"caminho_id"`
+		MesaID       *uint `json:"mesa_id"`
 	}
 
 	if err := c.ShouldBindJSON(&personagemData); err != nil {
@@ -598,6 +854,30 @@ func (h *PersonagemHandler) CalculateStats(c *gin.Context) {
 		return
 	}
 
+	// Com mesa_id, o cálculo segue as regras da casa (orçamento de pontos e PV por nível)
+	if personagemData.MesaID != nil {
+		usuarioID, ok := middleware.GetUsuarioID(c)
+		var membros int64
+		if ok {
+			database.DB.Model(&models.MesaMembro{}).Where("mesa_id = ? AND usuario_id = ?", *personagemData.MesaID, usuarioID).Count(&membros)
+		}
+		if membros == 0 {
+			h.Response.BadRequest(c, "Você não participa desta mesa")
+			return
+		}
+	}
+	regras, err := carregarRegras(database.DB, personagemData.MesaID)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao buscar regras da mesa")
+		return
+	}
+	totalAtributos := personagemData.Forca + personagemData.Destreza + personagemData.Constituicao +
+		personagemData.Inteligencia + personagemData.Sabedoria + personagemData.Carisma
+	if err := validarAtributosMesa(&regras, totalAtributos); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
 	var classe models.Classe
 	if err := database.DB.First(&classe, personagemData.ClasseID).Error; err != nil {
 		h.Response.NotFound(c, "Classe não encontrada")
@@ -618,11 +898,10 @@ func (h *PersonagemHandler) CalculateStats(c *gin.Context) {
 		pmPrimeiroNivel = classe.PMPorNivel
 	}
 
-	// PV = pvPrimeiroNivel + modCON + (pvPorNivel + modCON) * (nivel - 1)
+	// PV = pvPrimeiroNivel + modCON + (pvPorNivel + modCON) * (nivel - 1). Numa mesa que rola
+	// PV ainda não há rolagens, e os níveis seguintes usam o valor fixo (mesma média).
 	pvTotal := pvPrimeiroNivel + modCon
-	if personagemData.Nivel > 1 {
-		pvTotal += (classe.PVPorNivel + modCon) * (personagemData.Nivel - 1)
-	}
+	pvTotal += pvNiveisSeguintes(&models.Personagem{Nivel: personagemData.Nivel}, &regras, classe.PVPorNivel, modCon)
 
 	// PM = pmPrimeiroNivel + pmPorNivel * (nivel - 1)
 	pmTotal := pmPrimeiroNivel
@@ -641,10 +920,29 @@ func (h *PersonagemHandler) CalculateStats(c *gin.Context) {
 		pmTotal = 0
 	}
 
+	// Atributo-chave e CD de magia seguem o caminho escolhido, se houver
+	magia := models.Personagem{
+		Nivel:     personagemData.Nivel,
+		Int:       personagemData.Inteligencia,
+		Sab:       personagemData.Sabedoria,
+		Car:       personagemData.Carisma,
+		CaminhoID: personagemData.CaminhoID,
+	}
+	if personagemData.CaminhoID != nil {
+		if _, err := h.resolverCaminho(classe.ID, personagemData.Nivel, personagemData.CaminhoID, nil, true); err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+	}
+	h.aplicarCaminho(&magia, classe.AtributoMagia)
+
 	stats := gin.H{
-		"pv_total": pvTotal,
-		"pm_total": pmTotal,
-		"defesa":   defesa,
+		"pv_total":       pvTotal,
+		"pm_total":       pmTotal,
+		"defesa":         defesa,
+		"atributo_chave": magia.AtributoChave,
+		"cd_magia":       magia.CDMagia,
+		"pv_por_nivel":   regras.PVPorNivel,
 	}
 
 	c.JSON(http.StatusOK, stats)
@@ -673,6 +971,13 @@ func (h *PersonagemHandler) ExportToPDF(c *gin.Context) {
 	h.loadPersonagemCompleteData(personagem)
 	h.calculatePersonagemStats(personagem)
 
+	h.enviarPDF(c, personagem)
+}
+
+// enviarPDF gera a ficha em PDF conforme os parâmetros da query e a envia para download
+func (h *PersonagemHandler) enviarPDF(c *gin.Context, personagem *models.Personagem) {
+	var err error
+
 	// Garantir que todos os campos obrigatórios estejam preenchidos
 	if personagem.Nome == "" {
 		personagem.Nome = "Personagem Sem Nome"
@@ -690,9 +995,6 @@ func (h *PersonagemHandler) ExportToPDF(c *gin.Context) {
 		personagem.Divindade = &models.Divindade{Nome: "-"}
 	}
 
-	// Logar personagem antes de gerar PDF
-	fmt.Printf("[DEBUG] ExportToPDF - Personagem: %+v\n", personagem)
-
 	// Obter parâmetros da query
 	layout := c.DefaultQuery("layout", "single")
 	editable := c.DefaultQuery("editable", "false") == "true"
@@ -720,7 +1022,7 @@ func (h *PersonagemHandler) ExportToPDF(c *gin.Context) {
 	}
 
 	if err != nil {
-		fmt.Printf("[ERROR] Erro ao gerar PDF: %v\n", err)
+		log.Printf("Erro ao gerar PDF do personagem %d: %v", personagem.ID, err)
 		h.Response.InternalError(c, "Erro ao gerar PDF: "+err.Error())
 		return
 	}
@@ -772,6 +1074,11 @@ func (h *PersonagemHandler) SavePoderesDivinos(c *gin.Context) {
 		return
 	}
 
+	if err := garantirVersaoBase(c, personagem.ID); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+
 	// Remover poderes divinos existentes
 	if err := database.DB.Where("personagem_id = ?", id).Delete(&models.PersonagemPoderDivino{}).Error; err != nil {
 		h.Response.InternalError(c, "Erro ao remover poderes existentes")
@@ -792,6 +1099,11 @@ func (h *PersonagemHandler) SavePoderesDivinos(c *gin.Context) {
 		}
 	}
 
+	if err := registrarVersao(c, personagem.ID, models.VersaoEdicao); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":        "Poderes divinos salvos com sucesso",
 		"poderes_salvos": len(request.PoderesIDs),
@@ -827,6 +1139,11 @@ func (h *PersonagemHandler) SavePoderesClasse(c *gin.Context) {
 		return
 	}
 
+	if err := garantirVersaoBase(c, personagem.ID); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+
 	// Remover poderes de classe existentes
 	if err := database.DB.Where("personagem_id = ?", id).Delete(&models.PersonagemPoderClasse{}).Error; err != nil {
 		h.Response.InternalError(c, "Erro ao remover poderes existentes")
@@ -847,6 +1164,11 @@ func (h *PersonagemHandler) SavePoderesClasse(c *gin.Context) {
 		}
 	}
 
+	if err := registrarVersao(c, personagem.ID, models.VersaoEdicao); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":        "Poderes de classe salvos com sucesso",
 		"poderes_salvos": len(request.PoderesIDs),
@@ -902,6 +1224,93 @@ func (h *PersonagemHandler) loadPersonagemPericias(personagem *models.Personagem
 	personagem.Pericias = pericias
 }
 
+// personagemPossuiPoder verifica se o personagem escolheu um poder pelo nome,
+// seja como benefício de origem, poder de classe ou poder divino
+func (h *PersonagemHandler) personagemPossuiPoder(personagemID uint, nome string) bool {
+	if personagemID == 0 {
+		return false
+	}
+
+	var count int64
+	err := h.DB.Table("poderes").
+		Where("poderes.nome = ?", nome).
+		Where(`poderes.id IN (SELECT poder_id FROM personagem_beneficio_poderes WHERE personagem_id = ?)
+			OR poderes.id IN (SELECT poder_id FROM personagem_poderes_classe WHERE personagem_id = ?)
+			OR poderes.id IN (SELECT poder_id FROM personagem_poderes_divinos WHERE personagem_id = ?)`,
+			personagemID, personagemID, personagemID).
+		Count(&count).Error
+
+	return err == nil && count > 0
+}
+
+// valorAtributo retorna o valor final de um atributo pela sigla usada nas perícias (FOR, DES...).
+// Carisma já considera a perda pelos poderes da Tormenta.
+func (h *PersonagemHandler) valorAtributo(personagem *models.Personagem, sigla string) int {
+	switch strings.ToUpper(sigla) {
+	case "FOR":
+		return personagem.For
+	case "DES":
+		return personagem.Des
+	case "CON":
+		return personagem.Con
+	case "INT":
+		return personagem.Int
+	case "SAB":
+		return personagem.Sab
+	case "CAR":
+		return personagem.CarismaEfetivo()
+	}
+	return 0
+}
+
+// bonusTreinamento retorna o bônus de perícia treinada: +2 (1º-6º), +4 (7º-14º), +6 (15º+)
+func bonusTreinamento(nivel int) int {
+	switch {
+	case nivel >= 15:
+		return 6
+	case nivel >= 7:
+		return 4
+	default:
+		return 2
+	}
+}
+
+// totalPericia calcula o bônus total de uma perícia: metade do nível + atributo + treinamento.
+// Retorna também se o personagem é treinado nela.
+func (h *PersonagemHandler) totalPericia(personagem *models.Personagem, nome string) (int, bool) {
+	var pericia models.Pericia
+	if err := h.DB.Where("nome = ?", nome).First(&pericia).Error; err != nil {
+		return personagem.Nivel / 2, false
+	}
+
+	var count int64
+	h.DB.Table("pericias").
+		Where("pericias.id = ?", pericia.ID).
+		Where(`pericias.id IN (SELECT pericia_id FROM personagem_pericias WHERE personagem_id = ?)
+			OR pericias.id IN (SELECT pericia_id FROM personagem_beneficio_pericias WHERE personagem_id = ?)`,
+			personagem.ID, personagem.ID).
+		Count(&count)
+	treinado := count > 0
+
+	total := personagem.Nivel/2 + h.valorAtributo(personagem, pericia.Atributo)
+	if treinado {
+		total += bonusTreinamento(personagem.Nivel)
+	}
+
+	for _, deformidade := range personagem.Tormenta.PericiasDeformidade {
+		if deformidade == pericia.Nome {
+			total += bonusDeformidade
+		}
+	}
+
+	h.carregarParceiros(personagem)
+	total += models.CalcularBonusParceiros(personagem.Parceiros).Pericias[pericia.Nome]
+
+	h.carregarEfeitos(personagem)
+	total += models.CalcularBonusEfeitos(personagem.Efeitos).Pericia(pericia.Nome)
+	return total, treinado
+}
+
 // calculatePersonagemStats calcula e preenche os stats (PV, PM, Defesa) de um personagem.
 // IMPORTANTE: personagem.Con/Des ja armazena valores FINAIS (base + racial), nao adicionar racial novamente.
 // Regras T20:
@@ -909,11 +1318,15 @@ func (h *PersonagemHandler) loadPersonagemPericias(personagem *models.Personagem
 //   Niveis 2+: PV += pvPorNivel + modCON por nivel
 //   Total: pvPrimeiroNivel + modCON + (pvPorNivel + modCON) * (nivel - 1)
 func (h *PersonagemHandler) calculatePersonagemStats(personagem *models.Personagem) {
+	h.calculateProgressao(personagem)
+	h.calcularTormenta(personagem)
+
 	if personagem.ClasseID == 0 {
 		return
 	}
 
 	var pvPrimeiroNivel, pvPorNivel, pmPrimeiroNivel, pmPorNivel int
+	var atributoMagia string
 	if personagem.Classe.ID == 0 {
 		var classe models.Classe
 		if err := h.DB.First(&classe, personagem.ClasseID).Error; err == nil {
@@ -921,13 +1334,16 @@ func (h *PersonagemHandler) calculatePersonagemStats(personagem *models.Personag
 			pvPorNivel = classe.PVPorNivel
 			pmPrimeiroNivel = classe.PMPrimeiroNivel
 			pmPorNivel = classe.PMPorNivel
+			atributoMagia = classe.AtributoMagia
 		}
 	} else {
 		pvPrimeiroNivel = personagem.Classe.PVPrimeiroNivel
 		pvPorNivel = personagem.Classe.PVPorNivel
 		pmPrimeiroNivel = personagem.Classe.PMPrimeiroNivel
 		pmPorNivel = personagem.Classe.PMPorNivel
+		atributoMagia = personagem.Classe.AtributoMagia
 	}
+	h.aplicarCaminho(personagem, atributoMagia)
 
 	// Fallback: se pv_primeiro_nivel nao estiver preenchido, usar pv_por_nivel
 	if pvPrimeiroNivel == 0 {
@@ -941,10 +1357,17 @@ func (h *PersonagemHandler) calculatePersonagemStats(personagem *models.Personag
 	modCon := personagem.Con
 	modDes := personagem.Des
 
-	// PV = pvPrimeiroNivel + modCON + (pvPorNivel + modCON) * (nivel - 1)
+	// PV = pvPrimeiroNivel + modCON + (pvPorNivel + modCON) * (nivel - 1); em mesas que
+	// rolam PV, as rolagens salvas substituem pvPorNivel
+	regras := models.RegrasPadrao()
+	if personagem.MesaID != nil {
+		if r, err := carregarRegras(h.DB, personagem.MesaID); err == nil {
+			regras = r
+		}
+	}
 	pvTotal := pvPrimeiroNivel + modCon
 	if personagem.Nivel > 1 {
-		pvTotal += (pvPorNivel + modCon) * (personagem.Nivel - 1)
+		pvTotal += pvNiveisSeguintes(personagem, &regras, pvPorNivel, modCon)
 	}
 
 	// PM = pmPrimeiroNivel + (pmPorNivel) * (nivel - 1)
@@ -956,6 +1379,58 @@ func (h *PersonagemHandler) calculatePersonagemStats(personagem *models.Personag
 	// Defesa = 10 + mod DES
 	defesa := 10 + modDes
 
+	// Parceiros ativos: o maior bônus de cada tipo vale para Defesa, ataque e dano
+	h.carregarParceiros(personagem)
+	bonusParceiros := models.CalcularBonusParceiros(personagem.Parceiros)
+	defesa += bonusParceiros.Defesa
+
+	// Efeitos ativos (magias, habilidades): somam em Defesa, ataques, dano, PV e PM
+	h.carregarEfeitos(personagem)
+	bonusEfeitos := models.CalcularBonusEfeitos(personagem.Efeitos)
+	defesa += bonusEfeitos.Defesa
+	pvTotal += bonusEfeitos.PV
+	pmTotal += bonusEfeitos.PM
+
+	// Itens superiores e mágicos: bônus de Defesa e ataques dos itens equipados. A categoria
+	// da arma escolhe a perícia (Luta ou Pontaria) e se a Força soma no dano.
+	ataques := make([]models.Ataque, 0)
+	pericias := make(map[string]int)
+	for i := range personagem.Itens {
+		item := &personagem.Itens[i]
+		item.CalcularModificacoes()
+		if !item.Equipado {
+			continue
+		}
+		defesa += item.BonusDefesa
+		if item.Tipo != "arma" {
+			continue
+		}
+
+		pericia := item.PericiaAtaque()
+		if _, ok := pericias[pericia]; !ok {
+			pericias[pericia], _ = h.totalPericia(personagem, pericia)
+		}
+		dano := item.BonusDano + bonusParceiros.Dano + bonusEfeitos.Dano
+		if item.SomaForcaNoDano() {
+			dano += personagem.For
+		}
+		ataques = append(ataques, models.Ataque{
+			ItemID:       item.ID,
+			Nome:         item.Nome,
+			Pericia:      pericia,
+			BonusAtaque:  pericias[pericia] + item.BonusAtaque + bonusParceiros.Ataque + bonusEfeitos.Ataque,
+			BonusDano:    dano,
+			MargemAmeaca: item.MargemAmeaca,
+		})
+	}
+	personagem.Ataques = ataques
+	h.calcularCarga(personagem)
+
+	// Coração Heroico: +3 PM, e mais +3 PM a cada novo patamar
+	if h.personagemPossuiPoder(personagem.ID, "Coração Heroico") {
+		pmTotal += 3 * models.IndicePatamar(personagem.Nivel)
+	}
+
 	if pvTotal < 1 {
 		pvTotal = 1
 	}
@@ -1005,6 +1480,12 @@ func (h *PersonagemHandler) loadPersonagemCompleteData(personagem *models.Person
 		}
 	}
 
+	// Carregar inventário com melhorias e encantos
+	var itens []models.PersonagemItem
+	if err := h.DB.Preload("Melhorias").Preload("Encantos").Where("personagem_id = ?", personagem.ID).Order("ordem, id").Find(&itens).Error; err == nil {
+		personagem.Itens = itens
+	}
+
 	// Calcular stats
 	h.calculatePersonagemStats(personagem)
 }
@@ -1121,12 +1602,22 @@ func (h *PersonagemHandler) SaveEscolhasRaca(c *gin.Context) {
 		return
 	}
 
+	if err := garantirVersaoBase(c, personagem.ID); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+
 	// Atualizar o campo escolhas_raca
 	if err := database.DB.Model(&personagem).Update("escolhas_raca", string(escolhasJSON)).Error; err != nil {
 		h.Response.InternalError(c, "Erro ao salvar escolhas raciais")
 		return
 	}
 
+	if err := registrarVersao(c, personagem.ID, models.VersaoEdicao); err != nil {
+		h.Response.InternalError(c, "Erro ao gravar versão do personagem")
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":       "Escolhas raciais salvas com sucesso",
 		"personagem_id": id,
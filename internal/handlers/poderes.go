@@ -26,6 +26,11 @@ func (h *PoderHandler) RegisterRoutes(rg *gin.RouterGroup) {
 		poderes.GET("", h.GetAllPoderes)
 		poderes.GET("/origem/:origem_id", h.GetPoderesPorOrigem)
 		poderes.GET("/tipo/:tipo", h.GetPoderesPorTipo)
+
+		escrita := escritaCatalogo(poderes)
+		escrita.POST("", h.CreatePoder)
+		escrita.PUT("/:id", h.UpdatePoder)
+		escrita.DELETE("/:id", h.DeletePoder)
 	}
 
 	habilidades := rg.Group("/habilidades-especiais")
@@ -99,3 +104,18 @@ func (h *PoderHandler) GetHabilidadesEspeciaisRaca(c *gin.Context) {
 
 	c.JSON(http.StatusOK, habilidades)
 }
+
+func (h *PoderHandler) CreatePoder(c *gin.Context) {
+	var poder models.Poder
+	h.Create(c, &poder)
+}
+
+func (h *PoderHandler) UpdatePoder(c *gin.Context) {
+	var poder models.Poder
+	h.Update(c, &poder, "Poder não encontrado")
+}
+
+func (h *PoderHandler) DeletePoder(c *gin.Context) {
+	var poder models.Poder
+	h.Delete(c, &poder, "Poder não encontrado")
+}
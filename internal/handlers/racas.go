@@ -22,9 +22,11 @@ func (h *RacaHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	{
 		racas.GET("", h.GetAllRacas)
 		racas.GET("/:id", h.GetRaca)
-		racas.POST("", h.CreateRaca)
-		racas.PUT("/:id", h.UpdateRaca)
-		racas.DELETE("/:id", h.DeleteRaca)
+
+		escrita := escritaCatalogo(racas)
+		escrita.POST("", h.CreateRaca)
+		escrita.PUT("/:id", h.UpdateRaca)
+		escrita.DELETE("/:id", h.DeleteRaca)
 	}
 }
 
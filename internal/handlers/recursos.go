@@ -0,0 +1,475 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/middleware"
+	"tormenta20-builder/internal/models"
+	"tormenta20-builder/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxRecursosPorPersonagem limita quantos recursos limitados um personagem pode acompanhar
+const maxRecursosPorPersonagem = 50
+
+// variaveisRecurso são as variáveis aceitas na fórmula do máximo de um recurso
+var variaveisRecurso = []string{"FOR", "DES", "CON", "INT", "SAB", "CAR", "NIVEL"}
+
+var (
+	errRecursoInsuficiente = errors.New("usos insuficientes do recurso")
+	errRecursoSistema      = errors.New("recurso controlado pelo servidor: só volta no reinício de aventura")
+)
+
+// RecursoRequest representa a criação ou edição de um recurso limitado
+type RecursoRequest struct {
+	Nome      string `json:"nome" binding:"required,min=1,max=100"`
+	Maximo    string `json:"maximo" binding:"required,min=1,max=100"`
+	Reinicio  string `json:"reinicio" binding:"required,oneof=cena dia descanso aventura"`
+	ItemID    *uint  `json:"item_id"`
+	Descricao string `json:"descricao"`
+}
+
+// QuantidadeRecursoRequest representa o gasto ou a recuperação de usos de um recurso.
+// Sem quantidade, gastar usa 1 e recuperar enche o recurso.
+type QuantidadeRecursoRequest struct {
+	Quantidade int `json:"quantidade" binding:"omitempty,min=1,max=999"`
+}
+
+// ReiniciarRecursosRequest representa o fim de uma cena, dia, descanso ou aventura
+type ReiniciarRecursosRequest struct {
+	Gatilho string `json:"gatilho" binding:"required,oneof=cena dia descanso aventura"`
+}
+
+// ReiniciarRecursosGrupoRequest reinicia os recursos de vários personagens (ex: o mestre encerra a cena)
+type ReiniciarRecursosGrupoRequest struct {
+	PersonagemIDs []uint `json:"personagem_ids" binding:"required,min=1,max=50"`
+	Gatilho       string `json:"gatilho" binding:"required,oneof=cena dia descanso aventura"`
+}
+
+// variaveisFormulaRecurso monta as variáveis das fórmulas a partir do personagem.
+// O Carisma já considera a perda pelos poderes da Tormenta.
+func (h *PersonagemHandler) variaveisFormulaRecurso(personagem *models.Personagem) map[string]int {
+	variaveis := make(map[string]int, len(variaveisRecurso))
+	for _, sigla := range variaveisRecurso {
+		variaveis[sigla] = h.valorAtributo(personagem, sigla)
+	}
+	variaveis["NIVEL"] = personagem.Nivel
+	return variaveis
+}
+
+// calcularRecursos preenche o máximo e os usos disponíveis dos recursos. Fórmulas que
+// deixaram de ser válidas resultam em máximo zero em vez de erro na ficha.
+func (h *PersonagemHandler) calcularRecursos(personagem *models.Personagem, recursos []models.PersonagemRecurso) {
+	variaveis := h.variaveisFormulaRecurso(personagem)
+	for i := range recursos {
+		calcularRecurso(&recursos[i], variaveis)
+	}
+}
+
+// calcularRecurso avalia a fórmula do máximo de um recurso
+func calcularRecurso(recurso *models.PersonagemRecurso, variaveis map[string]int) {
+	maximo, err := services.AvaliarFormula(recurso.Maximo, variaveis)
+	if err != nil {
+		maximo = 0
+	}
+	recurso.AplicarMaximo(maximo)
+}
+
+// aplicar preenche o recurso com a requisição, validando a fórmula e o item de cargas
+func (r *RecursoRequest) aplicar(db *gorm.DB, recurso *models.PersonagemRecurso) error {
+	maximo := strings.TrimSpace(r.Maximo)
+	if err := services.ValidarFormula(maximo, variaveisRecurso); err != nil {
+		return fmt.Errorf("máximo inválido: %s (variáveis aceitas: %s)", err.Error(), strings.Join(variaveisRecurso, ", "))
+	}
+
+	if r.ItemID != nil {
+		var count int64
+		if err := db.Model(&models.PersonagemItem{}).
+			Where("id = ? AND personagem_id = ?", *r.ItemID, recurso.PersonagemID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("item com ID %d não pertence ao personagem", *r.ItemID)
+		}
+	}
+
+	// Mudar o gatilho ou a fórmula não reinicia os usos já gastos
+	recurso.Nome = strings.TrimSpace(r.Nome)
+	recurso.Maximo = maximo
+	recurso.Reinicio = r.Reinicio
+	recurso.ItemID = r.ItemID
+	recurso.Descricao = r.Descricao
+	return nil
+}
+
+// GetRecursos lista os recursos limitados de um personagem com os usos disponíveis
+func (h *PersonagemHandler) GetRecursos(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var recursos []models.PersonagemRecurso
+	if err := database.DB.Where("personagem_id = ?", id).Order("id").Find(&recursos).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar recursos")
+		return
+	}
+
+	h.calcularTormenta(personagem)
+	h.calcularRecursos(personagem, recursos)
+
+	c.JSON(http.StatusOK, recursos)
+}
+
+// CreateRecurso adiciona um recurso limitado ao personagem
+func (h *PersonagemHandler) CreateRecurso(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req RecursoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.PersonagemRecurso{}).Where("personagem_id = ?", id).Count(&count).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao adicionar recurso")
+		return
+	}
+	if count >= maxRecursosPorPersonagem {
+		h.Response.BadRequest(c, fmt.Sprintf("máximo de %d recursos por personagem", maxRecursosPorPersonagem))
+		return
+	}
+
+	recurso := models.PersonagemRecurso{PersonagemID: uint(id)}
+	if err := req.aplicar(database.DB, &recurso); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := database.DB.Create(&recurso).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao adicionar recurso")
+		return
+	}
+
+	h.calcularTormenta(personagem)
+	calcularRecurso(&recurso, h.variaveisFormulaRecurso(personagem))
+
+	h.Response.Created(c, recurso)
+}
+
+// UpdateRecurso substitui os dados de um recurso, mantendo os usos já gastos
+func (h *PersonagemHandler) UpdateRecurso(c *gin.Context) {
+	var req RecursoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	personagem, recurso, ok := h.findRecursoPersonagem(c)
+	if !ok {
+		return
+	}
+	if recurso.Sistema {
+		c.JSON(http.StatusConflict, gin.H{"error": errRecursoSistema.Error()})
+		return
+	}
+
+	if err := req.aplicar(database.DB, recurso); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := database.DB.Save(recurso).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao atualizar recurso")
+		return
+	}
+
+	h.calcularTormenta(personagem)
+	calcularRecurso(recurso, h.variaveisFormulaRecurso(personagem))
+
+	h.Response.Success(c, recurso)
+}
+
+// DeleteRecurso remove um recurso do personagem
+func (h *PersonagemHandler) DeleteRecurso(c *gin.Context) {
+	_, recurso, ok := h.findRecursoPersonagem(c)
+	if !ok {
+		return
+	}
+	if recurso.Sistema {
+		c.JSON(http.StatusConflict, gin.H{"error": errRecursoSistema.Error()})
+		return
+	}
+
+	if err := database.DB.Delete(&models.PersonagemRecurso{}, recurso.ID).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao remover recurso")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GastarRecurso gasta usos ou cargas de um recurso (padrão 1). A linha é travada para que
+// dois gastos simultâneos não usem o mesmo uso.
+func (h *PersonagemHandler) GastarRecurso(c *gin.Context) {
+	h.alterarRecurso(c, func(recurso *models.PersonagemRecurso, quantidade int) (*int, error) {
+		if quantidade == 0 {
+			quantidade = 1
+		}
+		if recurso.Disponivel < quantidade {
+			return nil, errRecursoInsuficiente
+		}
+		atual := recurso.Disponivel - quantidade
+		return &atual, nil
+	})
+}
+
+// RecuperarRecurso devolve usos a um recurso; sem quantidade, o recurso volta ao máximo.
+// Recursos do servidor só voltam pelo reinício.
+func (h *PersonagemHandler) RecuperarRecurso(c *gin.Context) {
+	h.alterarRecurso(c, func(recurso *models.PersonagemRecurso, quantidade int) (*int, error) {
+		if recurso.Sistema {
+			return nil, errRecursoSistema
+		}
+		if quantidade == 0 || recurso.Disponivel+quantidade >= recurso.MaximoValor {
+			return nil, nil
+		}
+		atual := recurso.Disponivel + quantidade
+		return &atual, nil
+	})
+}
+
+// alterarRecurso aplica um gasto ou recuperação ao valor atual do recurso dentro de uma
+// transação. novoAtual recebe o recurso já calculado e retorna o novo valor (nil = cheio).
+func (h *PersonagemHandler) alterarRecurso(c *gin.Context, novoAtual func(*models.PersonagemRecurso, int) (*int, error)) {
+	var req QuantidadeRecursoRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.Response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	personagem, recurso, ok := h.findRecursoPersonagem(c)
+	if !ok {
+		return
+	}
+
+	h.calcularTormenta(personagem)
+	variaveis := h.variaveisFormulaRecurso(personagem)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(recurso, recurso.ID).Error; err != nil {
+			return err
+		}
+		calcularRecurso(recurso, variaveis)
+
+		atual, err := novoAtual(recurso, req.Quantidade)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(recurso).Update("atual", atual).Error; err != nil {
+			return err
+		}
+		recurso.Atual = atual
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			h.Response.NotFound(c, "Recurso não encontrado")
+		case errors.Is(err, errRecursoInsuficiente):
+			h.Response.BadRequest(c, fmt.Sprintf("%s tem apenas %d uso(s) disponível(is)", recurso.Nome, recurso.Disponivel))
+		case errors.Is(err, errRecursoSistema):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.Response.InternalError(c, "Erro ao atualizar recurso")
+		}
+		return
+	}
+
+	calcularRecurso(recurso, variaveis)
+	h.Response.Success(c, recurso)
+}
+
+// ReiniciarRecursos encerra uma cena, dia, descanso ou aventura para o personagem,
+// enchendo os recursos que se renovam com esse gatilho
+func (h *PersonagemHandler) ReiniciarRecursos(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req ReiniciarRecursosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	reiniciados, err := reiniciarRecursos(database.DB, personagem.ID, req.Gatilho, reiniciaRecursosSistema(c, personagem))
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao reiniciar recursos")
+		return
+	}
+
+	h.Response.Success(c, gin.H{
+		"personagem_id": personagem.ID,
+		"gatilho":       req.Gatilho,
+		"reiniciados":   reiniciados,
+	})
+}
+
+// ReiniciarRecursosGrupo reinicia os recursos de uma lista de personagens, como quando o
+// mestre encerra a cena ou a aventura. Todos precisam pertencer ao usuário.
+func (h *PersonagemHandler) ReiniciarRecursosGrupo(c *gin.Context) {
+	var req ReiniciarRecursosGrupoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	personagens := make([]*models.Personagem, 0, len(req.PersonagemIDs))
+	vistos := make(map[uint]bool)
+	for _, personagemID := range req.PersonagemIDs {
+		if vistos[personagemID] {
+			continue
+		}
+		vistos[personagemID] = true
+
+		personagem, err := h.findPersonagemByUser(c, int(personagemID))
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				h.Response.NotFound(c, "Personagem não encontrado")
+			} else {
+				h.Response.InternalError(c, "Erro ao buscar personagem")
+			}
+			return
+		}
+		personagens = append(personagens, personagem)
+	}
+
+	resultados := make([]gin.H, 0, len(personagens))
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, personagem := range personagens {
+			reiniciados, err := reiniciarRecursos(tx, personagem.ID, req.Gatilho, reiniciaRecursosSistema(c, personagem))
+			if err != nil {
+				return err
+			}
+			resultados = append(resultados, gin.H{
+				"personagem_id": personagem.ID,
+				"nome":          personagem.Nome,
+				"reiniciados":   reiniciados,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao reiniciar recursos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"gatilho":     req.Gatilho,
+		"personagens": resultados,
+	})
+}
+
+// reiniciarRecursos enche os recursos do personagem renovados pelo gatilho e retorna quantos
+// mudaram. Sem incluirSistema, os recursos mantidos pelo servidor ficam como estão.
+func reiniciarRecursos(tx *gorm.DB, personagemID uint, gatilho string, incluirSistema bool) (int64, error) {
+	query := tx.Model(&models.PersonagemRecurso{}).
+		Where("personagem_id = ? AND reinicio IN ? AND atual IS NOT NULL", personagemID, models.GatilhosReiniciados(gatilho))
+	if !incluirSistema {
+		query = query.Where("sistema = ?", false)
+	}
+	result := query.Update("atual", gorm.Expr("NULL"))
+	return result.RowsAffected, result.Error
+}
+
+// reiniciaRecursosSistema indica se o reinício pedido renova os recursos do servidor: numa
+// mesa, só o mestre encerra a aventura deles; sem mesa, o dono.
+func reiniciaRecursosSistema(c *gin.Context, personagem *models.Personagem) bool {
+	return personagem.MesaID == nil || !middleware.EhDono(c, personagem)
+}
+
+// findRecursoPersonagem busca um recurso de um personagem do usuário
+func (h *PersonagemHandler) findRecursoPersonagem(c *gin.Context) (*models.Personagem, *models.PersonagemRecurso, bool) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return nil, nil, false
+	}
+	recursoID, err := strconv.ParseUint(c.Param("recurso_id"), 10, 32)
+	if err != nil {
+		h.Response.BadRequest(c, "ID do recurso inválido")
+		return nil, nil, false
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return nil, nil, false
+	}
+
+	var recurso models.PersonagemRecurso
+	if err := database.DB.Where("id = ? AND personagem_id = ?", recursoID, id).First(&recurso).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Recurso não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar recurso")
+		}
+		return nil, nil, false
+	}
+
+	return personagem, &recurso, true
+}
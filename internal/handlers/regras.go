@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+	"tormenta20-builder/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errPVFixo = errors.New("a mesa do personagem não usa PV rolado")
+
+// MesaRegrasRequest substitui as regras da casa de uma mesa. Campos omitidos voltam ao padrão.
+type MesaRegrasRequest struct {
+	PontosAtributos  *int     `json:"pontos_atributos" binding:"omitempty,min=0,max=30"`
+	LivrosPermitidos []string `json:"livros_permitidos" binding:"max=20,dive,min=1,max=50"`
+	RacasBanidas     []uint   `json:"racas_banidas" binding:"max=100"`
+	ClassesBanidas   []uint   `json:"classes_banidas" binding:"max=100"`
+	PVPorNivel       string   `json:"pv_por_nivel" binding:"omitempty,oneof=fixo rolado"`
+	NivelInicial     int      `json:"nivel_inicial" binding:"omitempty,min=1,max=20"`
+	DinheiroInicial  *float64 `json:"dinheiro_inicial" binding:"omitempty,min=0,max=1000000"`
+}
+
+// carregarRegras retorna as regras efetivas da mesa: as configuradas ou, sem mesa ou sem
+// configuração, as regras padrão
+func carregarRegras(db *gorm.DB, mesaID *uint) (models.MesaRegras, error) {
+	regras := models.RegrasPadrao()
+	if mesaID == nil {
+		return regras, nil
+	}
+	regras.MesaID = *mesaID
+
+	err := db.Where("mesa_id = ?", *mesaID).First(&regras).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return regras, nil
+	}
+	return regras, err
+}
+
+// validarAtributosMesa confere a soma dos atributos finais (base + racial). Com o point-buy
+// padrão de 10 pontos e os bônus raciais a soma não passa de 30; mesas com outro orçamento de
+// pontos deslocam o limite na mesma medida.
+func validarAtributosMesa(regras *models.MesaRegras, total int) error {
+	if total > 30+regras.PontosAtributos-models.PontosAtributosPadrao {
+		return fmt.Errorf("soma total de atributos (%d) excede o limite permitido", total)
+	}
+	if total < -6 {
+		return fmt.Errorf("soma total de atributos inválida")
+	}
+	return nil
+}
+
+// validarEscolhasMesa confere raça, classe, origem e divindade contra as regras da mesa
+// (raças e classes banidas, livros permitidos)
+func validarEscolhasMesa(db *gorm.DB, regras *models.MesaRegras, racaID, classeID, origemID uint, divindadeID *uint) error {
+	if regras.RacaBanida(racaID) {
+		return fmt.Errorf("raça banida nas regras da mesa")
+	}
+	if regras.ClasseBanida(classeID) {
+		return fmt.Errorf("classe banida nas regras da mesa")
+	}
+	if len(regras.Livros()) == 0 {
+		return nil
+	}
+
+	type escolha struct {
+		tabela string
+		id     uint
+		nome   string
+	}
+	escolhas := []escolha{
+		{"racas", racaID, "raça"},
+		{"classes", classeID, "classe"},
+		{"origens", origemID, "origem"},
+	}
+	if divindadeID != nil && *divindadeID > 0 {
+		escolhas = append(escolhas, escolha{"divindades", *divindadeID, "divindade"})
+	}
+
+	for _, e := range escolhas {
+		var livros []string
+		if err := db.Table(e.tabela).Where("id = ?", e.id).Pluck("livro", &livros).Error; err != nil {
+			return fmt.Errorf("erro ao verificar o livro da %s", e.nome)
+		}
+		if len(livros) > 0 && !regras.LivroPermitido(livros[0]) {
+			return fmt.Errorf("a %s é do livro %q, que não é permitido nesta mesa", e.nome, livros[0])
+		}
+	}
+	return nil
+}
+
+// pvNiveisSeguintes soma os PV ganhos do 2º nível em diante: o valor fixo da classe ou,
+// em mesas que rolam PV, as rolagens salvas (níveis ainda sem rolagem usam o valor fixo)
+func pvNiveisSeguintes(personagem *models.Personagem, regras *models.MesaRegras, pvPorNivel, modCon int) int {
+	var rolagens []int
+	if regras.PVPorNivel == models.PVRolado && personagem.PVRolagens != "" {
+		json.Unmarshal([]byte(personagem.PVRolagens), &rolagens)
+	}
+
+	total := 0
+	for nivel := 2; nivel <= personagem.Nivel; nivel++ {
+		pv := pvPorNivel
+		if i := nivel - 2; i < len(rolagens) {
+			pv = rolagens[i]
+		}
+		total += pv + modCon
+	}
+	return total
+}
+
+// GetRegras retorna as regras efetivas da mesa
+func (h *MesaHandler) GetRegras(c *gin.Context) {
+	mesa, _, ok := h.findMesaMembro(c, false)
+	if !ok {
+		return
+	}
+
+	regras, err := carregarRegras(h.DB, &mesa.ID)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao buscar regras da mesa")
+		return
+	}
+
+	h.Response.Success(c, regras)
+}
+
+// UpdateRegras substitui as regras da casa. Vale para validações e cálculos daqui em diante;
+// fichas já existentes não são alteradas.
+func (h *MesaHandler) UpdateRegras(c *gin.Context) {
+	mesa, _, ok := h.findMesaMembro(c, true)
+	if !ok {
+		return
+	}
+
+	var req MesaRegrasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	regras := models.RegrasPadrao()
+	regras.MesaID = mesa.ID
+	if req.PontosAtributos != nil {
+		regras.PontosAtributos = *req.PontosAtributos
+	}
+	if req.PVPorNivel != "" {
+		regras.PVPorNivel = req.PVPorNivel
+	}
+	if req.NivelInicial > 0 {
+		regras.NivelInicial = req.NivelInicial
+	}
+	regras.DinheiroInicial = req.DinheiroInicial
+
+	if livros := uniqueStrings(req.LivrosPermitidos); len(livros) > 0 {
+		livrosJSON, _ := json.Marshal(livros)
+		regras.LivrosPermitidos = string(livrosJSON)
+	}
+	if len(req.RacasBanidas) > 0 {
+		racasJSON, _ := json.Marshal(req.RacasBanidas)
+		regras.RacasBanidas = string(racasJSON)
+	}
+	if len(req.ClassesBanidas) > 0 {
+		classesJSON, _ := json.Marshal(req.ClassesBanidas)
+		regras.ClassesBanidas = string(classesJSON)
+	}
+
+	if err := h.DB.Save(&regras).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao salvar regras da mesa")
+		return
+	}
+
+	h.Response.Success(c, regras)
+}
+
+// GetRegrasPersonagem retorna as regras que valem para o personagem (as da mesa ou as padrão)
+func (h *PersonagemHandler) GetRegrasPersonagem(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	regras, err := carregarRegras(h.DB, personagem.MesaID)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao buscar regras da mesa")
+		return
+	}
+
+	h.Response.Success(c, regras)
+}
+
+// RolarPV rola os PV dos níveis que ainda não têm rolagem, para personagens de mesas que
+// rolam PV. O dado tem a mesma média do valor fixo da classe (1d(2×PV-1)) e rolagens já
+// feitas não podem ser refeitas.
+func (h *PersonagemHandler) RolarPV(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var personagem models.Personagem
+	var novas []*services.ResultadoDados
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&personagem, id).Error; err != nil {
+			return err
+		}
+		regras, err := carregarRegras(tx, personagem.MesaID)
+		if err != nil {
+			return err
+		}
+		if regras.PVPorNivel != models.PVRolado {
+			return errPVFixo
+		}
+
+		var classe models.Classe
+		if err := tx.First(&classe, personagem.ClasseID).Error; err != nil {
+			return err
+		}
+		faces := 2*classe.PVPorNivel - 1
+		if faces < 1 {
+			faces = 1
+		}
+
+		var rolagens []int
+		json.Unmarshal([]byte(personagem.PVRolagens), &rolagens)
+		for len(rolagens) < personagem.Nivel-1 {
+			resultado, err := services.RolarDados(fmt.Sprintf("1d%d", faces))
+			if err != nil {
+				return err
+			}
+			novas = append(novas, resultado)
+			rolagens = append(rolagens, resultado.Total)
+		}
+		if len(novas) == 0 {
+			return nil
+		}
+
+		rolagensJSON, err := json.Marshal(rolagens)
+		if err != nil {
+			return err
+		}
+		personagem.PVRolagens = string(rolagensJSON)
+		return tx.Model(&personagem).Update("pv_rolagens", personagem.PVRolagens).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errPVFixo):
+			h.Response.BadRequest(c, err.Error())
+		default:
+			h.Response.InternalError(c, "Erro ao rolar PV")
+		}
+		return
+	}
+
+	h.calculatePersonagemStats(&personagem)
+
+	h.Response.Success(c, gin.H{
+		"rolagens":    novas,
+		"pv_rolagens": json.RawMessage(personagem.PVRolagens),
+		"pv_total":    personagem.PVTotal,
+	})
+}
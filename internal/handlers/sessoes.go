@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"tormenta20-builder/internal/config"
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/middleware"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// alfabetoCodigo evita caracteres ambíguos (0/O, 1/I/L) para facilitar a digitação
+	alfabetoCodigo = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	tamanhoCodigo  = 8
+
+	// maxCodigosPendentes limita códigos ativos por sessão
+	maxCodigosPendentes = 5
+	// maxCodigosPorIPHora limita códigos gerados a partir do mesmo IP (pelo hash) por hora
+	maxCodigosPorIPHora = 20
+)
+
+var (
+	errCodigoInvalido = errors.New("código inválido ou expirado")
+	errMesmaSessao    = errors.New("este dispositivo já usa a sessão do código")
+)
+
+// SessaoHandler vincula dispositivos a uma sessão anônima por códigos de transferência
+type SessaoHandler struct {
+	*GenericService
+}
+
+func NewSessaoHandler() *SessaoHandler {
+	return &SessaoHandler{
+		GenericService: NewGenericService(database.DB),
+	}
+}
+
+// VincularSessaoRequest representa o uso de um código de transferência
+type VincularSessaoRequest struct {
+	Codigo string `json:"codigo" binding:"required,min=8,max=20"`
+}
+
+func (h *SessaoHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	sessao := rg.Group("/sessao")
+	{
+		sessao.POST("/transferencias", h.CriarCodigoTransferencia)
+		sessao.POST("/vincular", h.VincularSessao)
+	}
+}
+
+// gerarCodigo sorteia um código de transferência com crypto/rand
+func gerarCodigo() (string, error) {
+	var b strings.Builder
+	limite := big.NewInt(int64(len(alfabetoCodigo)))
+	for i := 0; i < tamanhoCodigo; i++ {
+		n, err := rand.Int(rand.Reader, limite)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(alfabetoCodigo[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// normalizarCodigo aceita o código com minúsculas, espaços ou hífens
+func normalizarCodigo(codigo string) string {
+	codigo = strings.ToUpper(codigo)
+	return strings.NewReplacer(" ", "", "-", "").Replace(codigo)
+}
+
+// CriarCodigoTransferencia gera um código de uso único para que outro dispositivo
+// assuma a sessão anônima atual. Só quem já possui a sessão consegue gerar o código.
+func (h *SessaoHandler) CriarCodigoTransferencia(c *gin.Context) {
+	sessionID := middleware.GetUserSessionID(c)
+	if sessionID == "" {
+		h.Response.BadRequest(c, "Nenhuma sessão para transferir")
+		return
+	}
+
+	agora := time.Now()
+	var pendentes int64
+	if err := h.DB.Model(&models.SessaoTransferencia{}).
+		Where("user_session_id = ? AND usado_em IS NULL AND expira_em > ?", sessionID, agora).
+		Count(&pendentes).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao gerar código")
+		return
+	}
+	if pendentes >= maxCodigosPendentes {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Muitos códigos ativos para esta sessão; use ou aguarde expirarem"})
+		return
+	}
+
+	var ipHash *string
+	if hash := middleware.GetUserIPHash(c); hash != "" {
+		ipHash = &hash
+		var recentes int64
+		if err := h.DB.Model(&models.SessaoTransferencia{}).
+			Where("user_ip_hash = ? AND created_at > ?", hash, agora.Add(-time.Hour)).
+			Count(&recentes).Error; err != nil {
+			h.Response.InternalError(c, "Erro ao gerar código")
+			return
+		}
+		if recentes >= maxCodigosPorIPHora {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Muitos códigos gerados; tente novamente mais tarde"})
+			return
+		}
+	}
+
+	codigo, err := gerarCodigo()
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao gerar código")
+		return
+	}
+
+	transferencia := models.SessaoTransferencia{
+		CodigoHash:    middleware.HashToken(codigo),
+		UserSessionID: sessionID,
+		UserIPHash:    ipHash,
+		ExpiraEm:      agora.Add(config.GetAuthConfig().TransferCodeTTL),
+	}
+	if err := h.DB.Create(&transferencia).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao gerar código")
+		return
+	}
+
+	h.Response.Created(c, gin.H{
+		"codigo":    codigo,
+		"expira_em": transferencia.ExpiraEm,
+	})
+}
+
+// VincularSessao usa um código de transferência: este dispositivo passa a usar a sessão de
+// origem e os personagens anônimos que ele já tinha são levados junto
+func (h *SessaoHandler) VincularSessao(c *gin.Context) {
+	var req VincularSessaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	sessionID := middleware.GetUserSessionID(c)
+	codigoHash := middleware.HashToken(normalizarCodigo(req.Codigo))
+
+	var transferencia models.SessaoTransferencia
+	var movidos int64
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		agora := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("codigo_hash = ? AND usado_em IS NULL AND expira_em > ?", codigoHash, agora).
+			First(&transferencia).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errCodigoInvalido
+			}
+			return err
+		}
+		if transferencia.UserSessionID == sessionID {
+			return errMesmaSessao
+		}
+
+		if err := tx.Model(&transferencia).Update("usado_em", agora).Error; err != nil {
+			return err
+		}
+
+		if sessionID == "" {
+			return nil
+		}
+		// Personagens na lixeira vão junto, para continuarem restauráveis
+		result := tx.Unscoped().Model(&models.Personagem{}).
+			Where("usuario_id IS NULL AND user_session_id = ?", sessionID).
+			Update("user_session_id", transferencia.UserSessionID)
+		movidos = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errCodigoInvalido), errors.Is(err, errMesmaSessao):
+			h.Response.BadRequest(c, err.Error())
+		default:
+			h.Response.InternalError(c, "Erro ao vincular dispositivo")
+		}
+		return
+	}
+
+	middleware.SetSessionCookie(c, transferencia.UserSessionID)
+	c.Header(middleware.UserSessionHeader, transferencia.UserSessionID)
+
+	h.Response.Success(c, gin.H{
+		"user_session_id":     transferencia.UserSessionID,
+		"personagens_movidos": movidos,
+	})
+}
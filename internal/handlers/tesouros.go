@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/middleware"
+	"tormenta20-builder/internal/models"
+	"tormenta20-builder/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TesouroHandler gera tesouros aleatórios pelas tabelas de ND do T20
+type TesouroHandler struct {
+	*GenericService
+	personagens *PersonagemHandler
+}
+
+func NewTesouroHandler() *TesouroHandler {
+	return &TesouroHandler{
+		GenericService: NewGenericService(database.DB),
+		personagens:    NewPersonagemHandler(),
+	}
+}
+
+func (h *TesouroHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	tesouros := rg.Group("/tesouros")
+	{
+		tesouros.GET("/tabela", h.GetTabela)
+		tesouros.POST("/gerar", h.GerarTesouro)
+	}
+}
+
+// GerarTesouroRequest representa uma rolagem de tesouro. Seed torna o resultado reproduzível;
+// com PersonagemID o tesouro é depositado no inventário e no dinheiro do personagem.
+type GerarTesouroRequest struct {
+	ND           string `json:"nd" binding:"required,max=5"`
+	Tipo         string `json:"tipo" binding:"required,oneof=dinheiro riqueza itens"`
+	Seed         *int64 `json:"seed"`
+	PersonagemID *uint  `json:"personagem_id"`
+}
+
+// RiquezaGerada é um objeto de valor sorteado na tabela de riquezas
+type RiquezaGerada struct {
+	Nome      string  `json:"nome"`
+	Categoria string  `json:"categoria"`
+	Valor     float64 `json:"valor"`
+}
+
+// ItemGerado é um item do catálogo sorteado, com as melhorias de itens superiores
+type ItemGerado struct {
+	ItemCatalogoID uint              `json:"item_catalogo_id"`
+	Nome           string            `json:"nome"`
+	Categoria      string            `json:"categoria"`
+	Quantidade     int               `json:"quantidade"`
+	Valor          float64           `json:"valor"`
+	Melhorias      []models.Melhoria `json:"melhorias,omitempty"`
+
+	catalogo models.ItemCatalogo
+}
+
+// TesouroGerado é o resultado de uma rolagem de tesouro
+type TesouroGerado struct {
+	ND         string          `json:"nd"`
+	Tipo       string          `json:"tipo"`
+	Seed       int64           `json:"seed"`
+	Rolagem    int             `json:"rolagem"`
+	Resultado  string          `json:"resultado"`
+	Tibares    float64         `json:"tibares"`
+	Riquezas   []RiquezaGerada `json:"riquezas"`
+	Itens      []ItemGerado    `json:"itens"`
+	ValorTotal float64         `json:"valor_total"`
+}
+
+// GetTabela retorna a tabela de tesouro de um ND (?nd=), ou todas as faixas se omitido
+func (h *TesouroHandler) GetTabela(c *gin.Context) {
+	query := h.DB.Order("id")
+	if nd := c.Query("nd"); nd != "" {
+		query = query.Where("nd = ?", nd)
+	}
+
+	var tabela []models.TesouroTabela
+	if err := query.Find(&tabela).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar tabela de tesouro")
+		return
+	}
+
+	h.Response.Success(c, tabela)
+}
+
+// GerarTesouro rola o tesouro do ND e, se pedido, deposita no personagem. Só o mestre da
+// mesa do personagem deposita, e a rolagem depositada nunca usa seed do cliente.
+func (h *TesouroHandler) GerarTesouro(c *gin.Context) {
+	var req GerarTesouroRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	var personagem *models.Personagem
+	if req.PersonagemID != nil {
+		if req.Seed != nil {
+			h.Response.BadRequest(c, "seed só é aceita em rolagens sem personagem_id")
+			return
+		}
+		var err error
+		personagem, err = h.personagens.findPersonagemByUser(c, int(*req.PersonagemID))
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				h.Response.NotFound(c, "Personagem não encontrado")
+			} else {
+				h.Response.InternalError(c, "Erro ao buscar personagem")
+			}
+			return
+		}
+		ehMestre, err := h.ehMestreDaMesa(c, personagem)
+		if err != nil {
+			h.Response.InternalError(c, "Erro ao verificar mestre da mesa")
+			return
+		}
+		if !ehMestre {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Só o mestre da mesa do personagem pode depositar tesouro"})
+			return
+		}
+	}
+
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
+	tesouro, err := h.rolarTesouro(rand.New(rand.NewSource(seed)), req.ND, req.Tipo)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.Response.BadRequest(c, fmt.Sprintf("Não há tabela de tesouro para ND %s", req.ND))
+		} else {
+			h.Response.InternalError(c, "Erro ao gerar tesouro")
+		}
+		return
+	}
+	tesouro.Seed = seed
+
+	if personagem == nil {
+		h.Response.Success(c, tesouro)
+		return
+	}
+
+	var transacao *models.PersonagemTransacao
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transacao, err = depositarTesouro(tx, personagem.ID, tesouro)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, errLimiteItens) {
+			h.Response.BadRequest(c, fmt.Sprintf("máximo de %d itens por personagem", maxItensPorPersonagem))
+		} else {
+			h.Response.InternalError(c, "Erro ao depositar tesouro no personagem")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tesouro":       tesouro,
+		"personagem_id": personagem.ID,
+		"transacao":     transacao,
+	})
+}
+
+// ehMestreDaMesa indica se o usuário logado é mestre da mesa do personagem
+func (h *TesouroHandler) ehMestreDaMesa(c *gin.Context, personagem *models.Personagem) (bool, error) {
+	usuarioID, ok := middleware.GetUsuarioID(c)
+	if !ok || personagem.MesaID == nil {
+		return false, nil
+	}
+	var total int64
+	err := h.DB.Model(&models.MesaMembro{}).
+		Where("mesa_id = ? AND usuario_id = ? AND papel = ?", *personagem.MesaID, usuarioID, models.PapelMesaMestre).
+		Count(&total).Error
+	return total > 0, err
+}
+
+// rolarTesouro faz a rolagem de d% na tabela do ND e resolve a linha sorteada
+func (h *TesouroHandler) rolarTesouro(gerador *rand.Rand, nd, tipo string) (*TesouroGerado, error) {
+	rolagem := gerador.Intn(100) + 1
+
+	var linha models.TesouroTabela
+	if err := h.DB.Where("nd = ? AND tipo = ? AND ? BETWEEN faixa_min AND faixa_max", nd, tipo, rolagem).
+		First(&linha).Error; err != nil {
+		return nil, err
+	}
+
+	tesouro := &TesouroGerado{
+		ND:        nd,
+		Tipo:      tipo,
+		Rolagem:   rolagem,
+		Resultado: linha.Resultado,
+		Riquezas:  []RiquezaGerada{},
+		Itens:     []ItemGerado{},
+	}
+
+	quantidade := 0
+	if linha.Dados != "" {
+		resultado, err := services.RolarDadosCom(gerador, linha.Dados)
+		if err != nil {
+			return nil, err
+		}
+		quantidade = resultado.Total
+	}
+
+	switch linha.Resultado {
+	case models.ResultadoTesouroTibares:
+		tesouro.Tibares = float64(quantidade * linha.Multiplicador)
+	case models.ResultadoTesouroRiqueza:
+		for i := 0; i < quantidade; i++ {
+			riqueza, err := h.rolarRiqueza(gerador, linha.Categoria)
+			if err != nil {
+				return nil, err
+			}
+			tesouro.Riquezas = append(tesouro.Riquezas, *riqueza)
+		}
+	case models.ResultadoTesouroItem, models.ResultadoTesouroSuperior:
+		for i := 0; i < quantidade; i++ {
+			item, err := h.sortearItem(gerador, linha.Categoria, linha.Resultado == models.ResultadoTesouroSuperior)
+			if err != nil {
+				return nil, err
+			}
+			if item != nil {
+				tesouro.Itens = append(tesouro.Itens, *item)
+			}
+		}
+	}
+
+	tesouro.ValorTotal = tesouro.Tibares
+	for _, riqueza := range tesouro.Riquezas {
+		tesouro.ValorTotal += riqueza.Valor
+	}
+	for _, item := range tesouro.Itens {
+		tesouro.ValorTotal += item.Valor * float64(item.Quantidade)
+	}
+	tesouro.ValorTotal = arredondarTibares(tesouro.ValorTotal)
+
+	return tesouro, nil
+}
+
+// rolarRiqueza sorteia um objeto de valor da categoria e rola o seu valor
+func (h *TesouroHandler) rolarRiqueza(gerador *rand.Rand, categoria string) (*RiquezaGerada, error) {
+	rolagem := gerador.Intn(100) + 1
+
+	var riqueza models.Riqueza
+	if err := h.DB.Where("categoria = ? AND ? BETWEEN faixa_min AND faixa_max", categoria, rolagem).
+		First(&riqueza).Error; err != nil {
+		return nil, err
+	}
+
+	valor, err := services.RolarDadosCom(gerador, riqueza.Dados)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RiquezaGerada{
+		Nome:      riqueza.Nome,
+		Categoria: categoria,
+		Valor:     float64(valor.Total * riqueza.Multiplicador),
+	}, nil
+}
+
+// sortearItem escolhe um item do catálogo da categoria. Itens superiores recebem uma
+// melhoria aplicável, com o preço ajustado pela tabela de melhorias.
+func (h *TesouroHandler) sortearItem(gerador *rand.Rand, categoria string, superior bool) (*ItemGerado, error) {
+	var catalogo []models.ItemCatalogo
+	if err := h.DB.Where("categoria = ?", categoria).Order("id").Find(&catalogo).Error; err != nil {
+		return nil, err
+	}
+	if len(catalogo) == 0 {
+		return nil, nil
+	}
+
+	escolhido := catalogo[gerador.Intn(len(catalogo))]
+	item := &ItemGerado{
+		ItemCatalogoID: escolhido.ID,
+		Nome:           escolhido.Nome,
+		Categoria:      escolhido.Categoria,
+		Quantidade:     1,
+		Valor:          escolhido.Preco,
+		catalogo:       escolhido,
+	}
+
+	if superior {
+		var melhorias []models.Melhoria
+		if err := h.DB.Where("COALESCE(pre_requisito, '') = ''").Order("id").Find(&melhorias).Error; err != nil {
+			return nil, err
+		}
+		tipo := tipoItemPorCategoria(escolhido.Categoria)
+		aplicaveis := make([]models.Melhoria, 0, len(melhorias))
+		for _, m := range melhorias {
+			if m.AplicavelA(tipo) {
+				aplicaveis = append(aplicaveis, m)
+			}
+		}
+		if len(aplicaveis) > 0 {
+			item.Melhorias = []models.Melhoria{aplicaveis[gerador.Intn(len(aplicaveis))]}
+			item.Valor += models.PrecoAdicionalMelhorias(len(item.Melhorias))
+		}
+	}
+
+	return item, nil
+}
+
+// depositarTesouro credita os tibares no livro-caixa e grava riquezas e itens no inventário.
+// Riquezas entram como itens do tipo riqueza, vendáveis pelo seu valor.
+func depositarTesouro(tx *gorm.DB, personagemID uint, tesouro *TesouroGerado) (*models.PersonagemTransacao, error) {
+	for _, riqueza := range tesouro.Riquezas {
+		item := models.PersonagemItem{
+			PersonagemID: personagemID,
+			Nome:         riqueza.Nome,
+			Tipo:         "riqueza",
+			Quantidade:   1,
+			Valor:        riqueza.Valor,
+			Descricao:    fmt.Sprintf("Riqueza %s (tesouro ND %s)", riqueza.Categoria, tesouro.ND),
+			Fonte:        models.FonteItemTesouro,
+			Ordem:        proximaOrdem(tx, personagemID),
+		}
+		if err := criarItemTesouro(tx, &item); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, gerado := range tesouro.Itens {
+		if len(gerado.Melhorias) == 0 {
+			if _, err := adicionarItemCatalogo(tx, personagemID, &gerado.catalogo, gerado.Quantidade, models.FonteItemTesouro); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Itens superiores ficam em linhas próprias com as melhorias aplicadas
+		item := models.PersonagemItem{
+			PersonagemID:   personagemID,
+			Nome:           gerado.Nome,
+			Tipo:           tipoItemPorCategoria(gerado.Categoria),
+			Quantidade:     gerado.Quantidade,
+			Peso:           gerado.catalogo.Espacos,
+			Valor:          gerado.catalogo.Preco,
+			Descricao:      gerado.catalogo.Descricao,
+			ItemCatalogoID: &gerado.ItemCatalogoID,
+			Fonte:          models.FonteItemTesouro,
+			Ordem:          proximaOrdem(tx, personagemID),
+		}
+		if err := criarItemTesouro(tx, &item); err != nil {
+			return nil, err
+		}
+		for _, m := range gerado.Melhorias {
+			if err := tx.Create(&models.PersonagemItemMelhoria{PersonagemItemID: item.ID, MelhoriaID: m.ID}).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if tesouro.Tibares <= 0 {
+		return nil, nil
+	}
+	return registrarTransacao(tx, personagemID, tesouro.Tibares, fmt.Sprintf("Tesouro (ND %s)", tesouro.ND), nil)
+}
+
+// criarItemTesouro grava um item novo respeitando o limite de itens do personagem
+func criarItemTesouro(tx *gorm.DB, item *models.PersonagemItem) error {
+	var count int64
+	if err := tx.Model(&models.PersonagemItem{}).Where("personagem_id = ?", item.PersonagemID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count >= maxItensPorPersonagem {
+		return errLimiteItens
+	}
+	return tx.Omit(clause.Associations).Create(item).Error
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"tormenta20-builder/internal/models"
+)
+
+// bonusDeformidade é o bônus em perícia de cada Deformidade do lefou
+const bonusDeformidade = 2
+
+// escolhasDeformidade são as escolhas da habilidade Deformidade guardadas em escolhas_raca:
+// {"deformidade": {"pericias": ["Furtividade", "Luta"], "poderes": [<id de um poder da Tormenta>]}}
+type escolhasDeformidade struct {
+	Deformidade struct {
+		Pericias []string `json:"pericias"`
+		Poderes  []uint   `json:"poderes"`
+	} `json:"deformidade"`
+}
+
+// opcoesDeformidade são os limites da habilidade especial em raca_habilidades_especiais
+type opcoesDeformidade struct {
+	PericiasBonus int `json:"pericias_bonus"`
+	MaxTrocas     int `json:"max_trocas"`
+}
+
+// calcularTormenta conta os poderes da Tormenta do personagem (benefícios de origem, poderes
+// de classe e divinos) e as Deformidades escolhidas, e calcula a perda de Carisma.
+// Deformidades e o poder trocado por uma delas não contam para a perda de Carisma.
+func (h *PersonagemHandler) calcularTormenta(personagem *models.Personagem) {
+	personagem.Tormenta = models.CorrupcaoTormenta{}
+
+	trocados := make(map[uint]bool)
+	var especial models.RacaHabilidadeEspecial
+	if personagem.RacaID != 0 && personagem.EscolhasRaca != "" &&
+		h.DB.Where("raca_id = ? AND tipo = ?", personagem.RacaID, "deformidade").First(&especial).Error == nil {
+		var opcoes opcoesDeformidade
+		var escolhas escolhasDeformidade
+		json.Unmarshal([]byte(especial.Opcoes), &opcoes)
+		if err := json.Unmarshal([]byte(personagem.EscolhasRaca), &escolhas); err == nil {
+			poderes := uniqueIDs(escolhas.Deformidade.Poderes)
+			if len(poderes) > opcoes.MaxTrocas {
+				poderes = poderes[:opcoes.MaxTrocas]
+			}
+			if len(poderes) > 0 {
+				var validos []uint
+				h.DB.Model(&models.Poder{}).
+					Where("id IN ? AND tipo = ?", poderes, models.TipoPoderTormenta).
+					Pluck("id", &validos)
+				for _, id := range validos {
+					trocados[id] = true
+				}
+			}
+
+			limite := opcoes.PericiasBonus - len(trocados)
+			pericias := make([]string, 0, len(escolhas.Deformidade.Pericias))
+			for _, pericia := range uniqueStrings(escolhas.Deformidade.Pericias) {
+				if len(pericias) >= limite {
+					break
+				}
+				pericias = append(pericias, pericia)
+			}
+			if len(pericias) > 0 {
+				personagem.Tormenta.PericiasDeformidade = pericias
+			}
+			personagem.Tormenta.Deformidades = len(trocados) + len(pericias)
+		}
+	}
+
+	if personagem.ID != 0 {
+		var ids []uint
+		h.DB.Table("poderes").
+			Where("poderes.tipo = ?", models.TipoPoderTormenta).
+			Where(`poderes.id IN (SELECT poder_id FROM personagem_beneficio_poderes WHERE personagem_id = ?)
+				OR poderes.id IN (SELECT poder_id FROM personagem_poderes_classe WHERE personagem_id = ?)
+				OR poderes.id IN (SELECT poder_id FROM personagem_poderes_divinos WHERE personagem_id = ?)`,
+				personagem.ID, personagem.ID, personagem.ID).
+			Pluck("poderes.id", &ids)
+		for _, id := range ids {
+			if !trocados[id] {
+				personagem.Tormenta.Poderes++
+			}
+		}
+	}
+
+	personagem.Tormenta.Total = personagem.Tormenta.Poderes + personagem.Tormenta.Deformidades
+	personagem.Tormenta.PenalidadeCarisma = models.PenalidadeCarismaTormenta(personagem.Tormenta.Poderes)
+}
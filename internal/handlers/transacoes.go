@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSaldoInsuficiente indica que a transação deixaria o saldo do personagem negativo
+var ErrSaldoInsuficiente = errors.New("saldo insuficiente")
+
+// TransacaoRequest representa um crédito ou débito manual
+type TransacaoRequest struct {
+	Valor  float64 `json:"valor" binding:"required,gt=0,max=10000000"`
+	Motivo string  `json:"motivo" binding:"required,min=1,max=200"`
+	ItemID *uint   `json:"item_id"`
+}
+
+// arredondarTibares arredonda um valor em T$ para centavos
+func arredondarTibares(valor float64) float64 {
+	return math.Round(valor*100) / 100
+}
+
+// registrarTransacao aplica um crédito (valor positivo) ou débito (valor negativo) no saldo
+// do personagem e grava a entrada no livro-caixa. Deve ser chamada dentro de uma transação
+// do banco: a linha do personagem é travada para que edições concorrentes não se sobrescrevam.
+func registrarTransacao(tx *gorm.DB, personagemID uint, valor float64, motivo string, itemID *uint) (*models.PersonagemTransacao, error) {
+	var personagem models.Personagem
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "dinheiro").
+		First(&personagem, personagemID).Error; err != nil {
+		return nil, err
+	}
+
+	valor = arredondarTibares(valor)
+	novoSaldo := arredondarTibares(personagem.Dinheiro + valor)
+	if novoSaldo < 0 {
+		return nil, ErrSaldoInsuficiente
+	}
+
+	if err := tx.Model(&personagem).Update("dinheiro", novoSaldo).Error; err != nil {
+		return nil, err
+	}
+
+	transacao := models.PersonagemTransacao{
+		PersonagemID: personagemID,
+		Valor:        valor,
+		SaldoApos:    novoSaldo,
+		Motivo:       motivo,
+		ItemID:       itemID,
+	}
+	if err := tx.Create(&transacao).Error; err != nil {
+		return nil, err
+	}
+
+	return &transacao, nil
+}
+
+// ajustarSaldo registra no livro-caixa a diferença entre o saldo atual e o saldo desejado.
+// Usado quando a ficha é salva inteira (PUT) com um novo valor de dinheiro.
+func ajustarSaldo(tx *gorm.DB, personagemID uint, novoSaldo float64, motivo string) error {
+	var personagem models.Personagem
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "dinheiro").
+		First(&personagem, personagemID).Error; err != nil {
+		return err
+	}
+
+	diferenca := arredondarTibares(novoSaldo - personagem.Dinheiro)
+	if diferenca == 0 {
+		return nil
+	}
+
+	_, err := registrarTransacao(tx, personagemID, diferenca, motivo, nil)
+	return err
+}
+
+// GetTransacoes retorna o histórico de transações de um personagem, da mais recente para a mais antiga
+func (h *PersonagemHandler) GetTransacoes(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	limite, err := strconv.Atoi(c.DefaultQuery("limite", "100"))
+	if err != nil || limite < 1 || limite > 500 {
+		h.Response.BadRequest(c, "Limite inválido")
+		return
+	}
+
+	var transacoes []models.PersonagemTransacao
+	if err := database.DB.Where("personagem_id = ?", id).
+		Order("created_at DESC, id DESC").
+		Limit(limite).
+		Find(&transacoes).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar transações")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"personagem_id": id,
+		"saldo":         personagem.Dinheiro,
+		"transacoes":    transacoes,
+	})
+}
+
+// CreditarDinheiro adiciona T$ ao saldo do personagem
+func (h *PersonagemHandler) CreditarDinheiro(c *gin.Context) {
+	h.movimentarDinheiro(c, 1)
+}
+
+// DebitarDinheiro retira T$ do saldo do personagem, sem permitir saldo negativo
+func (h *PersonagemHandler) DebitarDinheiro(c *gin.Context) {
+	h.movimentarDinheiro(c, -1)
+}
+
+func (h *PersonagemHandler) movimentarDinheiro(c *gin.Context, sinal float64) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	var req TransacaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	// Item relacionado precisa pertencer ao mesmo personagem
+	if req.ItemID != nil {
+		var count int64
+		if err := database.DB.Model(&models.PersonagemItem{}).
+			Where("id = ? AND personagem_id = ?", *req.ItemID, id).
+			Count(&count).Error; err != nil || count == 0 {
+			h.Response.BadRequest(c, "Item relacionado não encontrado no inventário do personagem")
+			return
+		}
+	}
+
+	var transacao *models.PersonagemTransacao
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transacao, err = registrarTransacao(tx, uint(id), sinal*req.Valor, req.Motivo, req.ItemID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrSaldoInsuficiente) {
+			h.Response.BadRequest(c, "Saldo insuficiente para o débito")
+		} else {
+			h.Response.InternalError(c, "Erro ao registrar transação")
+		}
+		return
+	}
+
+	h.Response.Created(c, transacao)
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strconv"
 
+	"tormenta20-builder/internal/middleware"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
@@ -26,6 +28,10 @@ func (rh *ResponseHandler) NotFound(c *gin.Context, message string) {
 	c.JSON(http.StatusNotFound, gin.H{"error": message})
 }
 
+func (rh *ResponseHandler) Unauthorized(c *gin.Context, message string) {
+	c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+}
+
 func (rh *ResponseHandler) BadRequest(c *gin.Context, message string) {
 	c.JSON(http.StatusBadRequest, gin.H{"error": message})
 }
@@ -199,3 +205,9 @@ func (gs *GenericService) Delete(c *gin.Context, model interface{}, notFoundMsg
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// escritaCatalogo retorna um grupo para as rotas que alteram o catálogo: apenas
+// administradores ou chaves de API com o escopo catalogo:escrita, com auditoria
+func escritaCatalogo(rg *gin.RouterGroup) *gin.RouterGroup {
+	return rg.Group("", middleware.EscritaCatalogo()...)
+}
@@ -0,0 +1,734 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"tormenta20-builder/internal/config"
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/middleware"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errVersaoNaoEncontrada = errors.New("versão não encontrada")
+
+// AlteracaoVersao é um campo que mudou entre duas versões (null = ausente naquela versão)
+type AlteracaoVersao struct {
+	Campo  string          `json:"campo"`
+	Antes  json.RawMessage `json:"antes"`
+	Depois json.RawMessage `json:"depois"`
+}
+
+// carregarFichaVersao lê do banco o estado atual da ficha no formato das versões
+func carregarFichaVersao(tx *gorm.DB, personagemID uint) (*models.FichaVersao, error) {
+	var personagem models.Personagem
+	if err := tx.Scopes(preloadItens).First(&personagem, personagemID).Error; err != nil {
+		return nil, err
+	}
+
+	ficha := models.FichaVersao{
+		Nome:            personagem.Nome,
+		Nivel:           personagem.Nivel,
+		Experiencia:     personagem.Experiencia,
+		For:             personagem.For,
+		Des:             personagem.Des,
+		Con:             personagem.Con,
+		Int:             personagem.Int,
+		Sab:             personagem.Sab,
+		Car:             personagem.Car,
+		RacaID:          personagem.RacaID,
+		ClasseID:        personagem.ClasseID,
+		OrigemID:        personagem.OrigemID,
+		DivindadeID:     personagem.DivindadeID,
+		CaminhoID:       personagem.CaminhoID,
+		EscolhasRaca:    jsonOuPadrao(personagem.EscolhasRaca, "{}"),
+		AtributosLivres: jsonOuPadrao(personagem.AtributosLivres, "[]"),
+		Dinheiro:        personagem.Dinheiro,
+		Anotacoes:       personagem.Anotacoes,
+		Historico:       personagem.Historico,
+		Itens:           []models.ItemVersao{},
+		Parceiros:       []models.ParceiroVersao{},
+	}
+
+	if err := tx.Where("personagem_id = ?", personagemID).Order("pericia_id, fonte").Find(&ficha.Pericias).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.PersonagemBeneficioPericia{}).Where("personagem_id = ?", personagemID).
+		Order("pericia_id").Pluck("pericia_id", &ficha.BeneficiosPericias).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.PersonagemBeneficioPoder{}).Where("personagem_id = ?", personagemID).
+		Order("poder_id").Pluck("poder_id", &ficha.BeneficiosPoderes).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("personagem_id = ?", personagemID).Order("poder_id").Find(&ficha.PoderesDivinos).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("personagem_id = ?", personagemID).Order("poder_id").Find(&ficha.PoderesClasse).Error; err != nil {
+		return nil, err
+	}
+
+	var parceiros []models.PersonagemParceiro
+	if err := tx.Where("personagem_id = ?", personagemID).Order("id").Find(&parceiros).Error; err != nil {
+		return nil, err
+	}
+	for _, parceiro := range parceiros {
+		ficha.Parceiros = append(ficha.Parceiros, models.ParceiroVersao{
+			Nome:         parceiro.Nome,
+			Tipo:         parceiro.Tipo,
+			Patamar:      parceiro.Patamar,
+			Fonte:        parceiro.Fonte,
+			Ativo:        parceiro.Ativo,
+			BonusAtaque:  parceiro.BonusAtaque,
+			BonusDano:    parceiro.BonusDano,
+			BonusDefesa:  parceiro.BonusDefesa,
+			BonusPericia: parceiro.BonusPericia,
+			Pericias:     jsonOuPadrao(parceiro.Pericias, "[]"),
+			Descricao:    parceiro.Descricao,
+		})
+	}
+
+	for _, item := range personagem.Itens {
+		versao := models.ItemVersao{
+			ID:             item.ID,
+			Nome:           item.Nome,
+			Tipo:           item.Tipo,
+			Quantidade:     item.Quantidade,
+			Peso:           item.Peso,
+			Valor:          item.Valor,
+			Descricao:      item.Descricao,
+			ItemCatalogoID: item.ItemCatalogoID,
+			Fonte:          item.Fonte,
+			OrigemItemID:   item.OrigemItemID,
+			Ordem:          item.Ordem,
+			ContainerID:    item.ContainerID,
+			Capacidade:     item.Capacidade,
+			Equipado:       item.Equipado,
+			CategoriaArma:  item.CategoriaArma,
+			MelhoriaIDs:    []uint{},
+			EncantoIDs:     []uint{},
+		}
+		for _, m := range item.Melhorias {
+			versao.MelhoriaIDs = append(versao.MelhoriaIDs, m.ID)
+		}
+		for _, e := range item.Encantos {
+			versao.EncantoIDs = append(versao.EncantoIDs, e.ID)
+		}
+		ficha.Itens = append(ficha.Itens, versao)
+	}
+
+	return &ficha, nil
+}
+
+// jsonOuPadrao devolve a coluna jsonb como JSON bruto, com um padrão para colunas vazias
+func jsonOuPadrao(valor, padrao string) json.RawMessage {
+	if valor == "" {
+		return json.RawMessage(padrao)
+	}
+	return json.RawMessage(valor)
+}
+
+// gravarVersao grava uma nova versão com o estado atual da ficha e aplica a retenção. A
+// linha do personagem fica travada para que salvamentos simultâneos não repitam o número.
+func gravarVersao(tx *gorm.DB, personagemID uint, usuarioID *uint, motivo string, restauradaDe *int) (*models.PersonagemVersao, error) {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Personagem{}, personagemID).Error; err != nil {
+		return nil, err
+	}
+
+	ficha, err := carregarFichaVersao(tx, personagemID)
+	if err != nil {
+		return nil, err
+	}
+	dados, err := json.Marshal(ficha)
+	if err != nil {
+		return nil, err
+	}
+
+	var ultima int
+	if err := tx.Model(&models.PersonagemVersao{}).Where("personagem_id = ?", personagemID).
+		Select("COALESCE(MAX(versao), 0)").Scan(&ultima).Error; err != nil {
+		return nil, err
+	}
+
+	versao := models.PersonagemVersao{
+		PersonagemID: personagemID,
+		Versao:       ultima + 1,
+		Dados:        string(dados),
+		UsuarioID:    usuarioID,
+		Motivo:       motivo,
+		RestauradaDe: restauradaDe,
+	}
+	if err := tx.Create(&versao).Error; err != nil {
+		return nil, err
+	}
+
+	return &versao, podarVersoes(tx, personagemID, versao.Versao)
+}
+
+// podarVersoes apaga as versões além do limite de quantidade ou de idade configurado.
+// A versão mais recente é sempre mantida.
+func podarVersoes(tx *gorm.DB, personagemID uint, ultima int) error {
+	cfg := config.GetVersoesConfig()
+
+	if cfg.MaxPorPersonagem > 0 {
+		if err := tx.Where("personagem_id = ? AND versao <= ?", personagemID, ultima-cfg.MaxPorPersonagem).
+			Delete(&models.PersonagemVersao{}).Error; err != nil {
+			return err
+		}
+	}
+	if cfg.Retencao > 0 {
+		if err := tx.Where("personagem_id = ? AND versao < ? AND created_at < ?", personagemID, ultima, time.Now().Add(-cfg.Retencao)).
+			Delete(&models.PersonagemVersao{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registrarVersao grava a versão de um salvamento feito pela requisição atual
+func registrarVersao(c *gin.Context, personagemID uint, motivo string) error {
+	var usuarioID *uint
+	if id, ok := middleware.GetUsuarioID(c); ok {
+		usuarioID = &id
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		_, err := gravarVersao(tx, personagemID, usuarioID, motivo, nil)
+		return err
+	})
+}
+
+// garantirVersaoBase grava o estado atual de personagens que ainda não têm versões, para
+// que a primeira edição feita depois do histórico também possa ser desfeita
+func garantirVersaoBase(c *gin.Context, personagemID uint) error {
+	var total int64
+	if err := database.DB.Model(&models.PersonagemVersao{}).Where("personagem_id = ?", personagemID).Count(&total).Error; err != nil {
+		return err
+	}
+	if total > 0 {
+		return nil
+	}
+	return registrarVersao(c, personagemID, models.VersaoBase)
+}
+
+// aplicarFichaVersao sobrescreve a ficha com o conteúdo de uma versão. Itens que ainda
+// existem mantêm o ID (e os recursos e usos ligados a eles); o dinheiro passa pelo livro-caixa.
+func aplicarFichaVersao(tx *gorm.DB, personagemID uint, ficha *models.FichaVersao, versao int) error {
+	err := tx.Model(&models.Personagem{}).Where("id = ?", personagemID).Updates(map[string]interface{}{
+		"nome":             ficha.Nome,
+		"nivel":            ficha.Nivel,
+		"experiencia":      ficha.Experiencia,
+		"for":              ficha.For,
+		"des":              ficha.Des,
+		"con":              ficha.Con,
+		"int":              ficha.Int,
+		"sab":              ficha.Sab,
+		"car":              ficha.Car,
+		"raca_id":          ficha.RacaID,
+		"classe_id":        ficha.ClasseID,
+		"origem_id":        ficha.OrigemID,
+		"divindade_id":     ficha.DivindadeID,
+		"caminho_id":       ficha.CaminhoID,
+		"escolhas_raca":    string(jsonOuPadrao(string(ficha.EscolhasRaca), "{}")),
+		"atributos_livres": string(jsonOuPadrao(string(ficha.AtributosLivres), "[]")),
+		"anotacoes":        ficha.Anotacoes,
+		"historico":        ficha.Historico,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	if err := ajustarSaldo(tx, personagemID, ficha.Dinheiro, fmt.Sprintf("Restauração da versão %d", versao)); err != nil {
+		return err
+	}
+
+	// Relações são substituídas por inteiro
+	relacoes := []interface{}{
+		&models.PersonagemPericia{},
+		&models.PersonagemBeneficioPericia{},
+		&models.PersonagemBeneficioPoder{},
+		&models.PersonagemPoderDivino{},
+		&models.PersonagemPoderClasse{},
+	}
+	for _, relacao := range relacoes {
+		if err := tx.Where("personagem_id = ?", personagemID).Delete(relacao).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, pericia := range ficha.Pericias {
+		pericia.PersonagemID = personagemID
+		if err := tx.Create(&pericia).Error; err != nil {
+			return err
+		}
+	}
+	for _, periciaID := range ficha.BeneficiosPericias {
+		if err := tx.Create(&models.PersonagemBeneficioPericia{PersonagemID: personagemID, PericiaID: periciaID}).Error; err != nil {
+			return err
+		}
+	}
+	for _, poderID := range ficha.BeneficiosPoderes {
+		if err := tx.Create(&models.PersonagemBeneficioPoder{PersonagemID: personagemID, PoderID: poderID}).Error; err != nil {
+			return err
+		}
+	}
+	for _, poder := range ficha.PoderesDivinos {
+		poder.PersonagemID = personagemID
+		if err := tx.Create(&poder).Error; err != nil {
+			return err
+		}
+	}
+	for _, poder := range ficha.PoderesClasse {
+		poder.PersonagemID = personagemID
+		if err := tx.Create(&poder).Error; err != nil {
+			return err
+		}
+	}
+
+	// Versões gravadas antes dos parceiros não mexem neles
+	if ficha.Parceiros != nil {
+		if err := tx.Where("personagem_id = ?", personagemID).Delete(&models.PersonagemParceiro{}).Error; err != nil {
+			return err
+		}
+		for _, versao := range ficha.Parceiros {
+			parceiro := models.PersonagemParceiro{
+				PersonagemID: personagemID,
+				Nome:         versao.Nome,
+				Tipo:         versao.Tipo,
+				Patamar:      versao.Patamar,
+				Fonte:        versao.Fonte,
+				Ativo:        versao.Ativo,
+				BonusAtaque:  versao.BonusAtaque,
+				BonusDano:    versao.BonusDano,
+				BonusDefesa:  versao.BonusDefesa,
+				BonusPericia: versao.BonusPericia,
+				Pericias:     string(jsonOuPadrao(string(versao.Pericias), "[]")),
+				Descricao:    versao.Descricao,
+			}
+			if err := tx.Create(&parceiro).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return restaurarItens(tx, personagemID, ficha.Itens)
+}
+
+// restaurarItens deixa o inventário igual ao da versão: atualiza os itens que ainda existem,
+// recria os que foram removidos (com IDs novos) e remove os que não existiam na versão
+func restaurarItens(tx *gorm.DB, personagemID uint, itens []models.ItemVersao) error {
+	var existentes []uint
+	if err := tx.Model(&models.PersonagemItem{}).Where("personagem_id = ?", personagemID).Pluck("id", &existentes).Error; err != nil {
+		return err
+	}
+	existe := make(map[uint]bool, len(existentes))
+	for _, id := range existentes {
+		existe[id] = true
+	}
+
+	campos := append([]string{"fonte", "origem_item_id", "item_catalogo_id"}, camposEditaveisItem...)
+	ids := make(map[uint]uint, len(itens)) // ID na versão -> ID atual
+	mantidos := []uint{}
+	for _, versao := range itens {
+		item := models.PersonagemItem{
+			PersonagemID:   personagemID,
+			Nome:           versao.Nome,
+			Tipo:           versao.Tipo,
+			Quantidade:     versao.Quantidade,
+			Peso:           versao.Peso,
+			Valor:          versao.Valor,
+			Descricao:      versao.Descricao,
+			ItemCatalogoID: versao.ItemCatalogoID,
+			Fonte:          versao.Fonte,
+			OrigemItemID:   versao.OrigemItemID,
+			Ordem:          versao.Ordem,
+			Capacidade:     versao.Capacidade,
+			Equipado:       versao.Equipado,
+			CategoriaArma:  versao.CategoriaArma,
+		}
+
+		// container_id é ligado depois, quando todos os itens já têm ID
+		if existe[versao.ID] {
+			if err := tx.Model(&models.PersonagemItem{ID: versao.ID}).Select(campos).Updates(&item).Error; err != nil {
+				return err
+			}
+			item.ID = versao.ID
+		} else if err := tx.Omit(clause.Associations).Create(&item).Error; err != nil {
+			return err
+		}
+		ids[versao.ID] = item.ID
+		mantidos = append(mantidos, item.ID)
+
+		// Melhorias e encantos removidos do catálogo desde a versão ficam de fora
+		if err := tx.Where("personagem_item_id = ?", item.ID).Delete(&models.PersonagemItemMelhoria{}).Error; err != nil {
+			return err
+		}
+		if len(versao.MelhoriaIDs) > 0 {
+			var melhorias []models.Melhoria
+			if err := tx.Where("id IN ?", versao.MelhoriaIDs).Find(&melhorias).Error; err != nil {
+				return err
+			}
+			for _, m := range melhorias {
+				if err := tx.Create(&models.PersonagemItemMelhoria{PersonagemItemID: item.ID, MelhoriaID: m.ID}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if err := tx.Where("personagem_item_id = ?", item.ID).Delete(&models.PersonagemItemEncanto{}).Error; err != nil {
+			return err
+		}
+		if len(versao.EncantoIDs) > 0 {
+			var encantos []models.Encanto
+			if err := tx.Where("id IN ?", versao.EncantoIDs).Find(&encantos).Error; err != nil {
+				return err
+			}
+			for _, e := range encantos {
+				if err := tx.Create(&models.PersonagemItemEncanto{PersonagemItemID: item.ID, EncantoID: e.ID}).Error; err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	remover := tx.Where("personagem_id = ?", personagemID)
+	if len(mantidos) > 0 {
+		remover = remover.Where("id NOT IN ?", mantidos)
+	}
+	if err := remover.Delete(&models.PersonagemItem{}).Error; err != nil {
+		return err
+	}
+
+	for _, versao := range itens {
+		if versao.ContainerID == nil {
+			continue
+		}
+		if containerID, ok := ids[*versao.ContainerID]; ok {
+			if err := tx.Model(&models.PersonagemItem{}).Where("id = ?", ids[versao.ID]).
+				Update("container_id", containerID).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compararVersoes lista os campos que mudaram entre duas versões. Listas são comparadas
+// por inteiro, exceto os itens, comparados um a um pelo ID.
+func compararVersoes(antes, depois *models.FichaVersao) ([]AlteracaoVersao, error) {
+	camposAntes, err := camposVersao(antes)
+	if err != nil {
+		return nil, err
+	}
+	camposDepois, err := camposVersao(depois)
+	if err != nil {
+		return nil, err
+	}
+
+	campos := make([]string, 0, len(camposAntes))
+	for campo := range camposAntes {
+		campos = append(campos, campo)
+	}
+	sort.Strings(campos)
+
+	alteracoes := []AlteracaoVersao{}
+	for _, campo := range campos {
+		if !bytes.Equal(camposAntes[campo], camposDepois[campo]) {
+			alteracoes = append(alteracoes, AlteracaoVersao{campo, camposAntes[campo], camposDepois[campo]})
+		}
+	}
+
+	itensAntes := make(map[uint]json.RawMessage, len(antes.Itens))
+	itensDepois := make(map[uint]json.RawMessage, len(depois.Itens))
+	var itemIDs []uint
+	for _, item := range antes.Itens {
+		itensAntes[item.ID], _ = json.Marshal(item)
+		itemIDs = append(itemIDs, item.ID)
+	}
+	for _, item := range depois.Itens {
+		itensDepois[item.ID], _ = json.Marshal(item)
+		if _, ok := itensAntes[item.ID]; !ok {
+			itemIDs = append(itemIDs, item.ID)
+		}
+	}
+	sort.Slice(itemIDs, func(i, j int) bool { return itemIDs[i] < itemIDs[j] })
+
+	nulo := json.RawMessage("null")
+	for _, id := range itemIDs {
+		antesItem, depoisItem := itensAntes[id], itensDepois[id]
+		if bytes.Equal(antesItem, depoisItem) {
+			continue
+		}
+		if antesItem == nil {
+			antesItem = nulo
+		}
+		if depoisItem == nil {
+			depoisItem = nulo
+		}
+		alteracoes = append(alteracoes, AlteracaoVersao{fmt.Sprintf("itens[%d]", id), antesItem, depoisItem})
+	}
+
+	return alteracoes, nil
+}
+
+// camposVersao separa uma versão em campos JSON, sem os itens
+func camposVersao(ficha *models.FichaVersao) (map[string]json.RawMessage, error) {
+	dados, err := json.Marshal(ficha)
+	if err != nil {
+		return nil, err
+	}
+	var campos map[string]json.RawMessage
+	if err := json.Unmarshal(dados, &campos); err != nil {
+		return nil, err
+	}
+	delete(campos, "itens")
+	return campos, nil
+}
+
+// findVersao carrega uma versão do personagem e decodifica a ficha
+func findVersao(personagemID uint, numero int) (*models.PersonagemVersao, *models.FichaVersao, error) {
+	var versao models.PersonagemVersao
+	if err := database.DB.Where("personagem_id = ? AND versao = ?", personagemID, numero).First(&versao).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errVersaoNaoEncontrada
+		}
+		return nil, nil, err
+	}
+
+	var ficha models.FichaVersao
+	if err := json.Unmarshal([]byte(versao.Dados), &ficha); err != nil {
+		return nil, nil, err
+	}
+	return &versao, &ficha, nil
+}
+
+// parseVersao lê o parâmetro :versao das rotas de versões
+func parseVersao(c *gin.Context) (int, error) {
+	numero, err := strconv.Atoi(c.Param("versao"))
+	if err != nil || numero < 1 {
+		return 0, fmt.Errorf("versão inválida")
+	}
+	return numero, nil
+}
+
+// responderErroVersao traduz erros de busca de versão em respostas HTTP
+func (h *PersonagemHandler) responderErroVersao(c *gin.Context, err error) {
+	if errors.Is(err, errVersaoNaoEncontrada) {
+		h.Response.NotFound(c, "Versão não encontrada")
+		return
+	}
+	h.Response.InternalError(c, "Erro ao buscar versão")
+}
+
+// GetVersoes lista as versões guardadas do personagem, da mais recente para a mais antiga
+func (h *PersonagemHandler) GetVersoes(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var versoes []models.PersonagemVersao
+	if err := database.DB.Omit("dados").Where("personagem_id = ?", id).Order("versao DESC").Find(&versoes).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao buscar versões")
+		return
+	}
+
+	c.JSON(http.StatusOK, versoes)
+}
+
+// GetVersao retorna uma versão com a ficha completa daquele momento
+func (h *PersonagemHandler) GetVersao(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+	numero, err := parseVersao(c)
+	if err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	versao, ficha, err := findVersao(id, numero)
+	if err != nil {
+		h.responderErroVersao(c, err)
+		return
+	}
+
+	h.Response.Success(c, gin.H{
+		"versao": versao,
+		"dados":  ficha,
+	})
+}
+
+// GetDiffVersoes compara a versão com outra (?com=, padrão: a versão guardada anterior)
+func (h *PersonagemHandler) GetDiffVersoes(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+	numero, err := parseVersao(c)
+	if err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	if _, err := h.findPersonagemByUser(c, int(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	var com int
+	if valor := c.Query("com"); valor != "" {
+		com, err = strconv.Atoi(valor)
+		if err != nil || com < 1 {
+			h.Response.BadRequest(c, "com deve ser o número de uma versão")
+			return
+		}
+	} else {
+		var anteriores []int
+		if err := database.DB.Model(&models.PersonagemVersao{}).
+			Where("personagem_id = ? AND versao < ?", id, numero).
+			Order("versao DESC").Limit(1).Pluck("versao", &anteriores).Error; err != nil {
+			h.Response.InternalError(c, "Erro ao buscar versão")
+			return
+		}
+		if len(anteriores) == 0 {
+			h.Response.NotFound(c, "Não há versão anterior para comparar")
+			return
+		}
+		com = anteriores[0]
+	}
+
+	// A comparação vai sempre da versão mais antiga para a mais nova
+	de, para := min(com, numero), max(com, numero)
+	_, fichaDe, err := findVersao(id, de)
+	if err != nil {
+		h.responderErroVersao(c, err)
+		return
+	}
+	_, fichaPara, err := findVersao(id, para)
+	if err != nil {
+		h.responderErroVersao(c, err)
+		return
+	}
+
+	alteracoes, err := compararVersoes(fichaDe, fichaPara)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao comparar versões")
+		return
+	}
+
+	h.Response.Success(c, gin.H{
+		"de":         de,
+		"para":       para,
+		"alteracoes": alteracoes,
+	})
+}
+
+// RestaurarVersao volta a ficha para uma versão anterior. A restauração grava uma versão
+// nova, então também pode ser desfeita.
+func (h *PersonagemHandler) RestaurarVersao(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		h.Response.BadRequest(c, "ID inválido")
+		return
+	}
+	numero, err := parseVersao(c)
+	if err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	personagem, err := h.findPersonagemByUser(c, int(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			h.Response.NotFound(c, "Personagem não encontrado")
+		} else {
+			h.Response.InternalError(c, "Erro ao buscar personagem")
+		}
+		return
+	}
+
+	_, ficha, err := findVersao(id, numero)
+	if err != nil {
+		h.responderErroVersao(c, err)
+		return
+	}
+
+	// A versão antiga precisa respeitar as regras da mesa atual
+	regras, err := carregarRegras(database.DB, personagem.MesaID)
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao buscar regras da mesa")
+		return
+	}
+	if err := validarEscolhasMesa(database.DB, &regras, ficha.RacaID, ficha.ClasseID, ficha.OrigemID, ficha.DivindadeID); err != nil {
+		h.Response.BadRequest(c, err.Error())
+		return
+	}
+
+	var usuarioID *uint
+	if uid, ok := middleware.GetUsuarioID(c); ok {
+		usuarioID = &uid
+	}
+
+	var nova *models.PersonagemVersao
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := aplicarFichaVersao(tx, id, ficha, numero); err != nil {
+			return err
+		}
+		var err error
+		nova, err = gravarVersao(tx, id, usuarioID, models.VersaoRestauracao, &numero)
+		return err
+	})
+	if err != nil {
+		h.Response.InternalError(c, "Erro ao restaurar versão")
+		return
+	}
+
+	var restaurado models.Personagem
+	if err := database.DB.Scopes(preloadFicha).First(&restaurado, id).Error; err != nil {
+		h.Response.InternalError(c, "Erro ao carregar personagem restaurado")
+		return
+	}
+	h.loadPersonagemPericias(&restaurado)
+	h.calculatePersonagemStats(&restaurado)
+
+	h.Response.Success(c, gin.H{
+		"versao":     nova,
+		"personagem": restaurado,
+	})
+}
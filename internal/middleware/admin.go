@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDadosAuditoria limita quanto do corpo da requisição é guardado na auditoria
+const maxDadosAuditoria = 64 * 1024
+
+// RequireAdmin permite apenas usuários logados com papel de administrador.
+// Chaves de API não passam: gerenciar chaves e papéis exige um administrador.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := GetUsuarioID(c); !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Login necessário"})
+			return
+		}
+		if GetUsuarioPapel(c) != models.PapelAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Acesso restrito a administradores"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireEscopo permite administradores logados ou chaves de API com o escopo informado
+func RequireEscopo(escopo string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, logado := GetUsuarioID(c)
+		_, temChave := GetApiChaveID(c)
+
+		switch {
+		case logado && GetUsuarioPapel(c) == models.PapelAdmin:
+			c.Next()
+		case temChave && slices.Contains(c.GetStringSlice("api_escopos"), escopo):
+			c.Next()
+		case !logado && !temChave:
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Autenticação necessária"})
+		default:
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Permissão insuficiente"})
+		}
+	}
+}
+
+// AuditarCatalogo registra cada escrita no catálogo: quem fez (usuário ou chave de API),
+// a rota, o registro afetado, o status da resposta e o corpo enviado
+func AuditarCatalogo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var dados []byte
+		if c.Request.Body != nil {
+			dados, _ = io.ReadAll(c.Request.Body)
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(dados))
+		}
+		if len(dados) > maxDadosAuditoria {
+			dados = dados[:maxDadosAuditoria]
+		}
+
+		c.Next()
+
+		registro := models.AuditoriaCatalogo{
+			Metodo:    c.Request.Method,
+			Rota:      c.FullPath(),
+			RecursoID: c.Param("id"),
+			Status:    c.Writer.Status(),
+			Dados:     string(dados),
+		}
+		if usuarioID, ok := GetUsuarioID(c); ok {
+			registro.UsuarioID = &usuarioID
+		}
+		if chaveID, ok := GetApiChaveID(c); ok {
+			registro.ApiChaveID = &chaveID
+		}
+		if err := database.DB.Create(&registro).Error; err != nil {
+			log.Printf("Erro ao registrar auditoria do catálogo: %v", err)
+		}
+	}
+}
+
+// EscritaCatalogo combina a permissão de escrita no catálogo com a auditoria
+func EscritaCatalogo() gin.HandlersChain {
+	return gin.HandlersChain{RequireEscopo(models.EscopoCatalogoEscrita), AuditarCatalogo()}
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	AuthCookie   = "auth_token"
+	ApiKeyHeader = "X-API-Key"
+	authBearer   = "Bearer "
+)
+
+// AuthMiddleware identifica o usuário logado pelo token da sessão (header
+// Authorization: Bearer ou cookie) e automações pela chave de API (X-API-Key).
+// Requisições sem credenciais seguem anônimas.
+func AuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := GetAuthToken(c); token != "" {
+			var sessao struct {
+				ID        uint
+				UsuarioID uint
+				Papel     string
+			}
+			err := database.DB.Table("usuario_sessoes").
+				Select("usuario_sessoes.id, usuario_sessoes.usuario_id, usuarios.papel").
+				Joins("JOIN usuarios ON usuarios.id = usuario_sessoes.usuario_id").
+				Where("usuario_sessoes.token_hash = ? AND usuario_sessoes.expira_em > ?", HashToken(token), time.Now()).
+				Take(&sessao).Error
+			if err == nil {
+				c.Set("usuario_id", sessao.UsuarioID)
+				c.Set("usuario_papel", sessao.Papel)
+				c.Set("sessao_id", sessao.ID)
+			}
+		}
+
+		if chave := c.GetHeader(ApiKeyHeader); chave != "" {
+			var apiChave models.ApiChave
+			err := database.DB.Where("chave_hash = ? AND revogada_em IS NULL", HashToken(chave)).First(&apiChave).Error
+			if err == nil {
+				c.Set("api_chave_id", apiChave.ID)
+				c.Set("api_escopos", apiChave.ListaEscopos())
+				database.DB.Model(&apiChave).Update("ultimo_uso_em", time.Now())
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAuth rejeita requisições sem usuário logado
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := GetUsuarioID(c); !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Login necessário"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetAuthToken obtém o token de login do header Authorization ou do cookie
+func GetAuthToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); strings.HasPrefix(header, authBearer) {
+		return strings.TrimSpace(strings.TrimPrefix(header, authBearer))
+	}
+	if token, err := c.Cookie(AuthCookie); err == nil {
+		return token
+	}
+	return ""
+}
+
+// GetUsuarioID obtém o ID do usuário logado do contexto
+func GetUsuarioID(c *gin.Context) (uint, bool) {
+	if usuarioID, exists := c.Get("usuario_id"); exists {
+		if id, ok := usuarioID.(uint); ok {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// GetUsuarioPapel obtém o papel (jogador, admin) do usuário logado
+func GetUsuarioPapel(c *gin.Context) string {
+	return c.GetString("usuario_papel")
+}
+
+// GetApiChaveID obtém o ID da chave de API usada na requisição
+func GetApiChaveID(c *gin.Context) (uint, bool) {
+	if chaveID, exists := c.Get("api_chave_id"); exists {
+		if id, ok := chaveID.(uint); ok {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// GetSessaoID obtém o ID da sessão de login atual do contexto
+func GetSessaoID(c *gin.Context) (uint, bool) {
+	if sessaoID, exists := c.Get("sessao_id"); exists {
+		if id, ok := sessaoID.(uint); ok {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// NovoToken gera um token aleatório de 256 bits em hexadecimal
+func NovoToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken retorna o SHA-256 do token, que é o que fica salvo no banco
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SetAuthCookie grava (ou remove, com maxAge negativo) o cookie do token de login
+func SetAuthCookie(c *gin.Context, token string, maxAge int) {
+	secure := requisicaoSegura(c)
+	if secure {
+		c.SetSameSite(http.SameSiteNoneMode)
+	} else {
+		c.SetSameSite(http.SameSiteLaxMode)
+	}
+	c.SetCookie(AuthCookie, token, maxAge, "/", "", secure, true)
+}
+
+// requisicaoSegura indica se a requisição chegou por HTTPS (direto ou atrás do proxy)
+func requisicaoSegura(c *gin.Context) bool {
+	return c.GetHeader("X-Forwarded-Proto") == "https" ||
+		c.Request.TLS != nil ||
+		strings.HasPrefix(c.Request.Host, "backend-tormenta20.fly.dev")
+}
@@ -20,8 +20,8 @@ func SetupCORS() gin.HandlerFunc {
 			"https://backend-tormenta20.fly.dev",
 		},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-User-Session-ID"},
-		ExposeHeaders:    []string{"X-User-Session-ID", "X-User-IP"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-User-Session-ID", "X-API-Key"},
+		ExposeHeaders:    []string{"X-User-Session-ID"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	})
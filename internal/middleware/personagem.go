@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const personagemKey = "personagem"
+
+// EscopoDono filtra os personagens que a requisição pode acessar. Com login, vale apenas a
+// conta; sem login, vale a sessão anônima, restrita a personagens que não pertencem a uma
+// conta. Retorna false se a requisição não tem nenhuma identificação.
+func EscopoDono(c *gin.Context) (func(*gorm.DB) *gorm.DB, bool) {
+	if usuarioID, ok := GetUsuarioID(c); ok {
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where("personagens.usuario_id = ?", usuarioID)
+		}, true
+	}
+
+	sessionID := GetUserSessionID(c)
+	if sessionID == "" {
+		return nil, false
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("personagens.usuario_id IS NULL AND personagens.user_session_id = ?", sessionID)
+	}, true
+}
+
+// EscopoMestre filtra os personagens das mesas em que o usuário é mestre. Para escrita,
+// valem apenas as mesas que permitem ao mestre editar as fichas (mestre_edita).
+func EscopoMestre(usuarioID uint, escrita bool) func(*gorm.DB) *gorm.DB {
+	mesas := "SELECT mesa_membros.mesa_id FROM mesa_membros JOIN mesas ON mesas.id = mesa_membros.mesa_id " +
+		"WHERE mesa_membros.usuario_id = ? AND mesa_membros.papel = ?"
+	if escrita {
+		mesas += " AND mesas.mestre_edita"
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("personagens.mesa_id IN ("+mesas+")", usuarioID, models.PapelMesaMestre)
+	}
+}
+
+// BuscarPersonagem carrega um personagem que a requisição pode acessar: os do próprio dono
+// e, com login, os das mesas em que o usuário é mestre (ver EscopoMestre; GET e HEAD contam
+// como leitura). É a única regra de acesso a personagens: quem não pode acessar recebe
+// gorm.ErrRecordNotFound, como se o personagem não existisse.
+func BuscarPersonagem(c *gin.Context, personagemID uint) (*models.Personagem, error) {
+	if personagem, ok := GetPersonagem(c); ok && personagem.ID == personagemID {
+		return personagem, nil
+	}
+
+	dono, ok := EscopoDono(c)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var personagem models.Personagem
+	err := database.DB.Scopes(dono).Where("personagens.id = ?", personagemID).First(&personagem).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return &personagem, err
+	}
+
+	usuarioID, logado := GetUsuarioID(c)
+	if !logado {
+		return nil, err
+	}
+	escrita := c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead
+	err = database.DB.Scopes(EscopoMestre(usuarioID, escrita)).Where("personagens.id = ?", personagemID).First(&personagem).Error
+	return &personagem, err
+}
+
+// EhDono indica se a requisição vem do dono do personagem (e não do mestre da mesa)
+func EhDono(c *gin.Context, personagem *models.Personagem) bool {
+	if usuarioID, ok := GetUsuarioID(c); ok {
+		return personagem.UsuarioID != nil && *personagem.UsuarioID == usuarioID
+	}
+	sessionID := GetUserSessionID(c)
+	return personagem.UsuarioID == nil && personagem.UserSessionID != nil && sessionID != "" && *personagem.UserSessionID == sessionID
+}
+
+// CarregarPersonagem protege as rotas /personagens/:id/*: resolve o personagem do parâmetro
+// :id, verifica o acesso e o coloca no contexto (GetPersonagem). Rotas sem :id seguem direto.
+// Quem não tem acesso recebe 404 em todas as rotas.
+func CarregarPersonagem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		param := c.Param("id")
+		if param == "" {
+			c.Next()
+			return
+		}
+
+		id, err := strconv.ParseUint(param, 10, 32)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		personagem, err := BuscarPersonagem(c, uint(id))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Personagem não encontrado"})
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar personagem"})
+			}
+			return
+		}
+
+		c.Set(personagemKey, personagem)
+		c.Next()
+	}
+}
+
+// GetPersonagem obtém do contexto o personagem carregado por CarregarPersonagem
+func GetPersonagem(c *gin.Context) (*models.Personagem, bool) {
+	if personagem, exists := c.Get(personagemKey); exists {
+		if p, ok := personagem.(*models.Personagem); ok {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// FichaEditavel protege as rotas que alteram a construção da ficha: fora do rascunho
+// (enviada, aprovada ou bloqueada), responde 409. Deve vir depois de CarregarPersonagem.
+func FichaEditavel() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if personagem, ok := GetPersonagem(c); ok && personagem.FichaTravada() {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+				"error":  "A ficha não pode ser editada no estado atual",
+				"estado": personagem.Estado,
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"net"
 	"net/http"
 	"strings"
-	"tormenta20-builder/internal/database"
+	"tormenta20-builder/internal/config"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -15,13 +15,11 @@ import (
 const (
 	UserSessionCookie = "user_session_id"
 	UserSessionHeader = "X-User-Session-ID"
-	UserIPHeader      = "X-User-IP"
 )
 
 func UserSessionMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var sessionID string
-		userIP := getUserIP(c)
 
 		// 1. Tenta obter sessão do cabeçalho (MAIOR PRIORIDADE)
 		sessionID = c.GetHeader(UserSessionHeader)
@@ -34,57 +32,58 @@ func UserSessionMiddleware() gin.HandlerFunc {
 			}
 		}
 
-		// 3. Se não existe em nenhum lugar, cria uma nova sessão
-		if sessionID == "" {
+		// 3. Se não existe (ou não é um UUID válido), cria uma nova sessão. Sessões novas
+		// não herdam nada: vincular outro dispositivo exige um código de transferência.
+		if _, err := uuid.Parse(sessionID); err != nil {
 			sessionID = uuid.New().String()
-
-			if userIP != "unknown" {
-				// A lógica de re-associação por IP pode ser mantida como um fallback
-				go func(sid, ip string) {
-					database.DB.Exec(
-						"UPDATE personagens SET user_session_id = ? WHERE user_ip = ? AND (user_session_id IS NULL OR user_session_id != ?)",
-						sid, ip, sid,
-					)
-				}(sessionID, userIP)
-			}
 		}
 
 		// 4. Define o cookie (ou atualiza o tempo de expiração do existente)
-		isSecure := c.GetHeader("X-Forwarded-Proto") == "https" ||
-			c.Request.TLS != nil ||
-			strings.HasPrefix(c.Request.Host, "backend-tormenta20.fly.dev")
-
-		// Para cross-origin com credentials, precisamos SameSite=None + Secure
-		if isSecure {
-			c.SetSameSite(http.SameSiteNoneMode)
-		} else {
-			c.SetSameSite(http.SameSiteLaxMode)
-		}
+		SetSessionCookie(c, sessionID)
 
-		c.SetCookie(
-			UserSessionCookie,
-			sessionID,
-			60*60*24*365, // 365 dias
-			"/",
-			"",
-			isSecure,
-			false,
-		)
-
-		// 5. Adiciona informações ao contexto
+		// 5. Adiciona informações ao contexto; o IP só é guardado como hash com salt
 		c.Set("user_session_id", sessionID)
-		c.Set("user_ip", userIP)
+		c.Set("user_ip_hash", HashIP(getUserIP(c)))
 
-		// 6. Adiciona headers na RESPOSTA para o frontend sempre ter o ID mais atual
+		// 6. Adiciona header na RESPOSTA para o frontend sempre ter o ID mais atual
 		c.Header(UserSessionHeader, sessionID)
-		c.Header(UserIPHeader, userIP)
 
 		c.Next()
 	}
 }
 
-// ... (o resto do arquivo user_session.go permanece o mesmo)
-// getUserIP, GetUserSessionID, GetUserIP, GetUserIdentification
+// SetSessionCookie grava o cookie da sessão anônima
+func SetSessionCookie(c *gin.Context, sessionID string) {
+	isSecure := requisicaoSegura(c)
+
+	// Para cross-origin com credentials, precisamos SameSite=None + Secure
+	if isSecure {
+		c.SetSameSite(http.SameSiteNoneMode)
+	} else {
+		c.SetSameSite(http.SameSiteLaxMode)
+	}
+
+	c.SetCookie(
+		UserSessionCookie,
+		sessionID,
+		60*60*24*365, // 365 dias
+		"/",
+		"",
+		isSecure,
+		false,
+	)
+}
+
+// HashIP retorna o SHA-256 do IP com o salt configurado (IP_HASH_SALT). Serve apenas para
+// limitar abusos; nunca identifica o dono de um personagem.
+func HashIP(ip string) string {
+	if ip == "" || ip == "unknown" {
+		return ""
+	}
+	return HashToken(config.GetAuthConfig().IPHashSalt + ip)
+}
+
+// getUserIP obtém o IP real do cliente, considerando os headers de proxy
 func getUserIP(c *gin.Context) string {
 	// Verifica headers de proxy mais comuns
 	headers := []string{
@@ -134,17 +133,12 @@ func GetUserSessionID(c *gin.Context) string {
 	return ""
 }
 
-// GetUserIP obtém o IP do usuário do contexto
-func GetUserIP(c *gin.Context) string {
-	if userIP, exists := c.Get("user_ip"); exists {
-		if ip, ok := userIP.(string); ok {
-			return ip
+// GetUserIPHash obtém o hash do IP do usuário do contexto
+func GetUserIPHash(c *gin.Context) string {
+	if ipHash, exists := c.Get("user_ip_hash"); exists {
+		if hash, ok := ipHash.(string); ok {
+			return hash
 		}
 	}
 	return ""
 }
-
-// GetUserIdentification retorna both session ID and IP for flexibility
-func GetUserIdentification(c *gin.Context) (sessionID, userIP string) {
-	return GetUserSessionID(c), GetUserIP(c)
-}
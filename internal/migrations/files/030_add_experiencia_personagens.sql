@@ -0,0 +1,9 @@
+-- Migration: Experiência (XP) dos personagens
+-- Tabela do T20: XP necessário para o nível N = 1000 * N * (N - 1) / 2
+
+ALTER TABLE personagens ADD COLUMN IF NOT EXISTS experiencia INTEGER NOT NULL DEFAULT 0;
+
+-- Personagens existentes recebem o mínimo de XP do seu nível atual
+UPDATE personagens SET experiencia = 1000 * nivel * (nivel - 1) / 2 WHERE experiencia = 0 AND nivel > 1;
+
+COMMENT ON COLUMN personagens.experiencia IS 'Experiência acumulada do personagem (XP)';
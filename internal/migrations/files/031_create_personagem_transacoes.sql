@@ -0,0 +1,24 @@
+-- Migration: Livro-caixa (transações de dinheiro) dos personagens
+-- personagens.dinheiro passa a ser o saldo consolidado das transações
+
+CREATE TABLE IF NOT EXISTS personagem_transacoes (
+    id SERIAL PRIMARY KEY,
+    personagem_id INTEGER NOT NULL REFERENCES personagens(id) ON DELETE CASCADE,
+    valor DECIMAL(10,2) NOT NULL,
+    saldo_apos DECIMAL(10,2) NOT NULL,
+    motivo VARCHAR(200) NOT NULL,
+    item_id INTEGER REFERENCES personagem_itens(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_personagem_transacoes_personagem_id ON personagem_transacoes(personagem_id, created_at);
+
+-- Saldo nunca pode ficar negativo
+UPDATE personagens SET dinheiro = 0 WHERE dinheiro IS NULL OR dinheiro < 0;
+ALTER TABLE personagens ADD CONSTRAINT chk_personagens_dinheiro_nao_negativo CHECK (dinheiro >= 0);
+
+-- Saldo atual dos personagens existentes vira a transação de abertura
+INSERT INTO personagem_transacoes (personagem_id, valor, saldo_apos, motivo, created_at)
+SELECT id, dinheiro, dinheiro, 'Saldo inicial', NOW()
+FROM personagens
+WHERE dinheiro > 0;
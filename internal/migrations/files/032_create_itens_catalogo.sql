@@ -0,0 +1,85 @@
+-- Migration: Catálogo de equipamento geral + vínculo dos itens do personagem com o catálogo
+-- Preço em T$, espaços ocupados conforme a regra de carga do T20
+
+CREATE TABLE IF NOT EXISTS itens_catalogo (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(200) NOT NULL UNIQUE,
+    categoria VARCHAR(50) NOT NULL, -- geral, alquimico, vestuario, ferramenta, alimentacao
+    preco DECIMAL(10,2) NOT NULL DEFAULT 0,
+    espacos DECIMAL(8,2) NOT NULL DEFAULT 1,
+    descricao TEXT DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_itens_catalogo_categoria ON itens_catalogo(categoria);
+
+ALTER TABLE personagem_itens ADD COLUMN IF NOT EXISTS item_catalogo_id INTEGER REFERENCES itens_catalogo(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_personagem_itens_item_catalogo_id ON personagem_itens(item_catalogo_id);
+
+-- Seed: equipamento geral
+INSERT INTO itens_catalogo (nome, categoria, preco, espacos, descricao) VALUES
+-- Itens gerais (equipamento de aventura)
+('Algemas', 'geral', 15, 1, 'Prendem os pulsos de uma criatura Média ou menor.'),
+('Arpéu', 'geral', 5, 1, 'Gancho de ferro para prender cordas em muros e penhascos.'),
+('Bandoleira de poções', 'geral', 20, 1, 'Permite sacar poções e preparados alquímicos como ação livre.'),
+('Barraca', 'geral', 10, 1, 'Abriga até duas criaturas Médias.'),
+('Corda', 'geral', 1, 1, 'Rolo de corda de cânhamo com 10m.'),
+('Equipamento de viagem', 'geral', 10, 1, 'Cantil, pederneira, panela e utensílios básicos para viagens.'),
+('Espelho', 'geral', 10, 1, 'Pequeno espelho de metal polido.'),
+('Lampião', 'geral', 7, 1, 'Ilumina um raio de 9m por uma noite com um frasco de óleo.'),
+('Mochila', 'geral', 2, 0, 'Necessária para carregar itens além das mãos.'),
+('Mochila de aventureiro', 'geral', 50, 0, 'Mochila com divisórias e bolsos que fornece +2 espaços.'),
+('Óleo', 'geral', 0.1, 0.5, 'Frasco de óleo para lampiões.'),
+('Organizador de pergaminhos', 'geral', 25, 1, 'Estojo para guardar e sacar pergaminhos rapidamente.'),
+('Pé de cabra', 'geral', 2, 1, 'Fornece +5 em testes de Força para abrir portas e baús.'),
+('Saco de dormir', 'geral', 1, 1, 'Permite descansar ao relento sem penalidades.'),
+('Símbolo sagrado', 'geral', 5, 1, 'Medalhão ou ídolo de uma divindade.'),
+('Tocha', 'geral', 0.1, 1, 'Ilumina um raio de 6m por uma cena.'),
+('Vara de madeira', 'geral', 0.2, 1, 'Vara com 3m de comprimento.'),
+-- Ferramentas
+('Alaúde élfico', 'ferramenta', 300, 1, 'Instrumento musical refinado. Fornece +2 em Atuação.'),
+('Coleção de livros', 'ferramenta', 75, 1, 'Fornece +1 em Conhecimento, Guerra, Misticismo, Nobreza e Religião.'),
+('Estojo de disfarces', 'ferramenta', 50, 1, 'Maquiagem, tintas e perucas. Necessário para disfarces.'),
+('Flauta mística', 'ferramenta', 150, 1, 'Instrumento musical que fornece +1 na CD de magias de bardo.'),
+('Gazua', 'ferramenta', 5, 1, 'Ferramenta para abrir fechaduras.'),
+('Instrumento musical', 'ferramenta', 35, 1, 'Alaúde, flauta, tambor, lira ou similar.'),
+('Instrumentos de ofício', 'ferramenta', 30, 1, 'Ferramentas necessárias para um tipo de ofício.'),
+('Luneta', 'ferramenta', 100, 1, 'Fornece +5 em Percepção para observar coisas distantes.'),
+('Maleta de medicamentos', 'ferramenta', 50, 1, 'Bandagens, ervas e instrumentos. Necessária para usar Cura.'),
+('Sela', 'ferramenta', 20, 1, 'Necessária para cavalgar sem penalidades.'),
+-- Vestuário
+('Andrajos de aldeão', 'vestuario', 1, 1, 'Roupas simples de camponês.'),
+('Bandana', 'vestuario', 5, 1, 'Lenço amarrado à cabeça.'),
+('Botas reforçadas', 'vestuario', 20, 1, 'Botas de couro grosso para longas caminhadas.'),
+('Capa esvoaçante', 'vestuario', 25, 1, 'Capa elegante que fornece +1 em Atuação.'),
+('Capa pesada', 'vestuario', 15, 1, 'Protege contra o frio.'),
+('Casaco longo', 'vestuario', 20, 1, 'Casaco que esconde armas e itens.'),
+('Chapéu arcano', 'vestuario', 50, 1, 'Chapéu pontudo característico de conjuradores.'),
+('Farrapos de ermitão', 'vestuario', 1, 1, 'Roupas gastas de quem vive isolado.'),
+('Luvas de pelica', 'vestuario', 25, 1, 'Luvas finas de couro macio.'),
+('Manto camuflado', 'vestuario', 12, 1, 'Fornece +2 em Furtividade em ambientes naturais.'),
+('Manto eclesiástico', 'vestuario', 20, 1, 'Vestes de sacerdote.'),
+('Robe místico', 'vestuario', 50, 1, 'Robe bordado com símbolos arcanos.'),
+('Sapatos de camurça', 'vestuario', 8, 1, 'Sapatos macios e silenciosos.'),
+('Tabardo', 'vestuario', 10, 1, 'Veste com o brasão de uma ordem ou casa nobre.'),
+('Traje da corte', 'vestuario', 100, 1, 'Roupas finas para eventos da nobreza.'),
+('Traje de sacerdote', 'vestuario', 10, 1, 'Vestes simples de um servo dos deuses.'),
+('Traje de viajante', 'vestuario', 10, 1, 'Roupas resistentes para a estrada.'),
+-- Alquímicos
+('Ácido', 'alquimico', 10, 0.5, 'Frasco arremessável que causa 2d4 pontos de dano de ácido.'),
+('Água benta', 'alquimico', 10, 0.5, 'Causa 2d6 pontos de dano de luz em mortos-vivos.'),
+('Bálsamo restaurador', 'alquimico', 10, 0.5, 'Cura 2d4 pontos de vida.'),
+('Bomba', 'alquimico', 50, 0.5, 'Explode causando 6d6 pontos de dano de impacto.'),
+('Cosmético', 'alquimico', 30, 0.5, 'Fornece +2 em Diplomacia e Enganação por um dia.'),
+('Elixir do amor', 'alquimico', 100, 0.5, 'A criatura que beber fica enfeitiçada pelo primeiro ser que ver.'),
+('Essência de mana', 'alquimico', 50, 0.5, 'Recupera 1d4 pontos de mana.'),
+('Fogo alquímico', 'alquimico', 10, 0.5, 'Frasco arremessável que causa 1d6 pontos de dano de fogo e deixa o alvo em chamas.'),
+('Pó do desaparecimento', 'alquimico', 100, 0.5, 'Deixa a criatura invisível por uma cena.'),
+-- Alimentação
+('Batata valkariana', 'alimentacao', 2, 0.5, 'Porção de batatas fritas em óleo.'),
+('Prato do aventureiro', 'alimentacao', 1, 0.5, 'Refeição farta que recupera +1 PV por nível no descanso.'),
+('Ração de viagem', 'alimentacao', 0.5, 0.5, 'Comida desidratada suficiente para um dia.'),
+('Sopa de peixe', 'alimentacao', 1, 0.5, 'Fornece +1 em Fortitude até o fim do dia.')
+ON CONFLICT (nome) DO NOTHING;
@@ -0,0 +1,89 @@
+-- Migration: Itens superiores (melhorias) e mágicos (encantos)
+-- Preço: o custo adicional depende do número de melhorias/encantos do item (ver models.PrecoAdicionalMelhorias)
+
+CREATE TABLE IF NOT EXISTS melhorias (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(100) NOT NULL UNIQUE,
+    descricao TEXT DEFAULT '',
+    aplica_em VARCHAR(100) NOT NULL, -- tipos separados por vírgula: arma, armadura, escudo, esoterico, ferramenta, vestuario
+    pre_requisito VARCHAR(100) DEFAULT '',
+    bonus_ataque INTEGER DEFAULT 0,
+    bonus_dano INTEGER DEFAULT 0,
+    bonus_defesa INTEGER DEFAULT 0,
+    margem_ameaca INTEGER DEFAULT 0,
+    reducao_penalidade INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS encantos (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(100) NOT NULL UNIQUE,
+    descricao TEXT DEFAULT '',
+    aplica_em VARCHAR(100) NOT NULL,
+    pre_requisito VARCHAR(100) DEFAULT '',
+    bonus_ataque INTEGER DEFAULT 0,
+    bonus_dano INTEGER DEFAULT 0,
+    bonus_defesa INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS personagem_item_melhorias (
+    personagem_item_id INTEGER NOT NULL REFERENCES personagem_itens(id) ON DELETE CASCADE,
+    melhoria_id INTEGER NOT NULL REFERENCES melhorias(id) ON DELETE CASCADE,
+    PRIMARY KEY (personagem_item_id, melhoria_id)
+);
+
+CREATE TABLE IF NOT EXISTS personagem_item_encantos (
+    personagem_item_id INTEGER NOT NULL REFERENCES personagem_itens(id) ON DELETE CASCADE,
+    encanto_id INTEGER NOT NULL REFERENCES encantos(id) ON DELETE CASCADE,
+    PRIMARY KEY (personagem_item_id, encanto_id)
+);
+
+ALTER TABLE personagem_itens ADD COLUMN IF NOT EXISTS equipado BOOLEAN DEFAULT FALSE;
+
+-- Seed: melhorias
+INSERT INTO melhorias (nome, descricao, aplica_em, pre_requisito, bonus_ataque, bonus_dano, bonus_defesa, margem_ameaca, reducao_penalidade) VALUES
+('Certeira', 'Fabricada para ser precisa. Fornece +1 nos testes de ataque.', 'arma', '', 1, 0, 0, 0, 0),
+('Pungente', 'Lâmina ou ponta perfeitamente afiada. Fornece +2 nos testes de ataque (cumulativo com certeira).', 'arma', 'Certeira', 2, 0, 0, 0, 0),
+('Cruel', 'Arma com gume ou cabeça mais letal. Fornece +1 nas rolagens de dano.', 'arma', '', 0, 1, 0, 0, 0),
+('Atroz', 'Arma brutal. Fornece +2 nas rolagens de dano (cumulativo com cruel).', 'arma', 'Cruel', 0, 2, 0, 0, 0),
+('Precisa', 'Arma balanceada para golpes críticos. Aumenta a margem de ameaça em +1.', 'arma', '', 0, 0, 0, 1, 0),
+('Equilibrada', 'Fornece +2 em testes de ataque para manobras de combate.', 'arma', '', 0, 0, 0, 0, 0),
+('Maciça', 'Arma mais pesada. O multiplicador de crítico aumenta em +1.', 'arma', '', 0, 0, 0, 0, 0),
+('Discreta', 'Fornece +5 em testes de Ladinagem para ocultar o item.', 'arma,armadura,esoterico', '', 0, 0, 0, 0, 0),
+('Banhada a ouro', 'Fornece +2 em Diplomacia.', 'arma,armadura,escudo,esoterico,vestuario', '', 0, 0, 0, 0, 0),
+('Cravejada de gemas', 'Fornece +2 em Enganação.', 'arma,armadura,escudo,esoterico,vestuario', '', 0, 0, 0, 0, 0),
+('Ajustada', 'Feita sob as medidas do usuário. Reduz a penalidade de armadura em 1.', 'armadura,escudo', '', 0, 0, 0, 0, 1),
+('Sob medida', 'Ajuste perfeito ao corpo do usuário. Reduz a penalidade de armadura em mais 1.', 'armadura', 'Ajustada', 0, 0, 0, 0, 1),
+('Reforçada', 'Placas e rebites extras. Fornece +1 na Defesa.', 'armadura,escudo', '', 0, 0, 1, 0, 0),
+('Espinhosa', 'Coberta de espinhos. Causa dano em quem agarrar o usuário.', 'armadura,escudo', '', 0, 0, 0, 0, 0),
+('Polida', 'Superfície espelhada. Fornece +2 em testes de resistência contra efeitos de luz e olhar.', 'armadura,escudo', '', 0, 0, 0, 0, 0),
+('Selada', 'Juntas vedadas. Fornece +1 em Fortitude.', 'armadura', '', 0, 0, 0, 0, 0),
+('Canalizador', 'Esotérico que reduz em 1 PM o custo de aprimoramentos.', 'esoterico', '', 0, 0, 0, 0, 0),
+('Poderoso', 'Aumenta a CD das magias lançadas com o esotérico em +1.', 'esoterico', '', 0, 0, 0, 0, 0),
+('Aprimorada', 'Ferramenta ou vestuário de qualidade superior. Fornece +1 na perícia associada.', 'ferramenta,vestuario', '', 0, 0, 0, 0, 0)
+ON CONFLICT (nome) DO NOTHING;
+
+-- Seed: encantos
+INSERT INTO encantos (nome, descricao, aplica_em, pre_requisito, bonus_ataque, bonus_dano, bonus_defesa) VALUES
+('Formidável', 'Fornece +2 em testes de ataque e rolagens de dano.', 'arma', '', 2, 2, 0),
+('Magnífica', 'Fornece +4 em testes de ataque e rolagens de dano (substitui o bônus de formidável).', 'arma', 'Formidável', 2, 2, 0),
+('Ameaçadora', 'A margem de ameaça da arma é dobrada.', 'arma', '', 0, 0, 0),
+('Flamejante', 'A arma causa +1d6 de dano de fogo.', 'arma', '', 0, 0, 0),
+('Congelante', 'A arma causa +1d6 de dano de frio.', 'arma', '', 0, 0, 0),
+('Elétrica', 'A arma causa +1d6 de dano de eletricidade.', 'arma', '', 0, 0, 0),
+('Corrosiva', 'A arma causa +1d6 de dano de ácido.', 'arma', '', 0, 0, 0),
+('Defensora', 'Enquanto empunha a arma, você recebe +2 na Defesa.', 'arma', '', 0, 0, 2),
+('Veloz', 'Uma vez por rodada, você pode fazer um ataque extra com a arma.', 'arma', '', 0, 0, 0),
+('Sagrada', 'A arma causa +2d6 de dano de luz contra criaturas malignas.', 'arma', '', 0, 0, 0),
+('Defensor', 'A armadura ou escudo fornece +2 na Defesa.', 'armadura,escudo', '', 0, 0, 2),
+('Guardião', 'A armadura ou escudo fornece +4 na Defesa (substitui o bônus de defensor).', 'armadura,escudo', 'Defensor', 0, 0, 2),
+('Abençoado', 'Fornece +2 em testes de resistência.', 'armadura,escudo', '', 0, 0, 0),
+('Fortificado', 'Chance de ignorar o dano extra de acertos críticos e ataques furtivos.', 'armadura', '', 0, 0, 0),
+('Refletor', 'Reflete magias que tenham o usuário como único alvo.', 'escudo', '', 0, 0, 0),
+('Sombrio', 'Fornece +5 em Furtividade.', 'armadura', '', 0, 0, 0)
+ON CONFLICT (nome) DO NOTHING;
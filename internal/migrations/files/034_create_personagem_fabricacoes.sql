@@ -0,0 +1,24 @@
+-- Migration: Fabricação de itens com a perícia Ofício
+-- Matéria-prima (1/3 do preço) é debitada no início; progresso em dias até o teste final
+
+CREATE TABLE IF NOT EXISTS personagem_fabricacoes (
+    id SERIAL PRIMARY KEY,
+    personagem_id INTEGER NOT NULL REFERENCES personagens(id) ON DELETE CASCADE,
+    item_catalogo_id INTEGER NOT NULL REFERENCES itens_catalogo(id),
+    nome VARCHAR(200) NOT NULL,
+    quantidade INTEGER NOT NULL DEFAULT 1,
+    para_cinco BOOLEAN NOT NULL DEFAULT FALSE,
+    custo_materia_prima DECIMAL(10,2) NOT NULL,
+    cd INTEGER NOT NULL,
+    penalidade INTEGER NOT NULL DEFAULT 0,
+    dias_necessarios INTEGER NOT NULL,
+    dias_trabalhados INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'em_andamento', -- em_andamento, concluida, falhou
+    ultima_rolagem INTEGER,
+    ultimo_resultado INTEGER,
+    item_id INTEGER REFERENCES personagem_itens(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_personagem_fabricacoes_personagem_id ON personagem_fabricacoes(personagem_id, status);
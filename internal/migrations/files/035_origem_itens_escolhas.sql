@@ -0,0 +1,35 @@
+-- Migration: Itens iniciais de origem concedidos automaticamente ao personagem
+-- origem_itens ganha opções de escolha; personagem_itens passa a registrar a fonte do item
+
+ALTER TABLE origem_itens ADD COLUMN IF NOT EXISTS opcoes JSONB DEFAULT '[]';
+ALTER TABLE origem_itens ADD COLUMN IF NOT EXISTS escolha_livre BOOLEAN DEFAULT FALSE;
+
+-- Itens "X ou Y (escolha um)" com alternativas fechadas
+UPDATE origem_itens SET opcoes = '["Cão de caça", "Cavalo", "Pônei", "Trobo"]'
+WHERE nome = 'Cão de caça, cavalo, pônei ou trobo (escolha um)';
+UPDATE origem_itens SET opcoes = '["Estojo de disfarces", "Instrumento musical"]'
+WHERE nome = 'Estojo de disfarces ou instrumento musical';
+UPDATE origem_itens SET opcoes = '["Estojo de disfarces", "Gazua"]'
+WHERE nome = 'Estojo de disfarces ou gazua';
+UPDATE origem_itens SET opcoes = '["Instrumentos de ofício", "Arma simples"]'
+WHERE nome = 'Instrumentos de ofício ou arma simples';
+UPDATE origem_itens SET opcoes = '["Gazua", "Instrumentos de ofício"]'
+WHERE nome = 'Gazua ou instrumentos de ofício';
+UPDATE origem_itens SET opcoes = '["Galinha", "Porco", "Ovelha"]'
+WHERE nome = 'Animal não combativo';
+UPDATE origem_itens SET opcoes = '["Cão", "Gato", "Rato", "Pombo"]'
+WHERE nome = 'Animal urbano';
+UPDATE origem_itens SET opcoes = '["Pássaro", "Esquilo"]'
+WHERE nome = 'Animal de estimação';
+UPDATE origem_itens SET opcoes = '["Anel de sinete", "Manto cerimonial"]'
+WHERE nome = 'Símbolo de herança';
+
+-- Itens em que o jogador escolhe livremente (ex.: "Arma marcial, escolha uma")
+UPDATE origem_itens SET escolha_livre = TRUE
+WHERE tipo = 'arma' AND descricao IN ('Escolha uma', 'Escolha um');
+
+-- Fonte do item no inventário: manual, origem, loja, fabricacao
+ALTER TABLE personagem_itens ADD COLUMN IF NOT EXISTS fonte VARCHAR(20) DEFAULT 'manual';
+ALTER TABLE personagem_itens ADD COLUMN IF NOT EXISTS origem_item_id INTEGER REFERENCES origem_itens(id) ON DELETE SET NULL;
+UPDATE personagem_itens SET fonte = 'loja' WHERE item_catalogo_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_personagem_itens_fonte ON personagem_itens(personagem_id, fonte);
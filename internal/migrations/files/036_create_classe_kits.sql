@@ -0,0 +1,92 @@
+-- Migration: Equipamento inicial e dinheiro inicial por classe
+-- grupo = 0: item fixo do kit; grupo > 0: o jogador escolhe um item do grupo
+
+ALTER TABLE classes ADD COLUMN IF NOT EXISTS dinheiro_inicial DECIMAL(10,2) DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS classe_kit_itens (
+    id SERIAL PRIMARY KEY,
+    classe_id INTEGER NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    grupo INTEGER NOT NULL DEFAULT 0,
+    nome VARCHAR(200) NOT NULL,
+    tipo VARCHAR(50) DEFAULT 'item',
+    quantidade INTEGER DEFAULT 1,
+    descricao TEXT DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_classe_kit_itens_classe_id ON classe_kit_itens(classe_id, grupo);
+
+-- Seed: dinheiro inicial
+UPDATE classes SET dinheiro_inicial = 20 WHERE nome IN ('Arcanista', 'Bardo', 'Bucaneiro', 'Ladino');
+UPDATE classes SET dinheiro_inicial = 15 WHERE nome IN ('Bárbaro', 'Caçador', 'Cavaleiro', 'Clérigo', 'Druida', 'Guerreiro', 'Lutador', 'Paladino');
+UPDATE classes SET dinheiro_inicial = 30 WHERE nome = 'Inventor';
+UPDATE classes SET dinheiro_inicial = 40 WHERE nome = 'Nobre';
+
+-- Seed: kits de classe
+INSERT INTO classe_kit_itens (classe_id, grupo, nome, tipo, quantidade, descricao) VALUES
+-- Arcanista
+((SELECT id FROM classes WHERE nome='Arcanista'), 0, 'Grimório', 'item', 1, 'Apenas para magos'),
+((SELECT id FROM classes WHERE nome='Arcanista'), 1, 'Adaga', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Arcanista'), 1, 'Bordão', 'arma', 1, ''),
+-- Bárbaro
+((SELECT id FROM classes WHERE nome='Bárbaro'), 0, 'Gibão de peles', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Bárbaro'), 1, 'Machado de batalha', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Bárbaro'), 1, 'Montante', 'arma', 1, ''),
+-- Bardo
+((SELECT id FROM classes WHERE nome='Bardo'), 0, 'Instrumento musical', 'ferramenta', 1, ''),
+((SELECT id FROM classes WHERE nome='Bardo'), 0, 'Couro', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Bardo'), 1, 'Espada curta', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Bardo'), 1, 'Florete', 'arma', 1, ''),
+-- Bucaneiro
+((SELECT id FROM classes WHERE nome='Bucaneiro'), 0, 'Couro', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Bucaneiro'), 1, 'Florete', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Bucaneiro'), 1, 'Cimitarra', 'arma', 1, ''),
+-- Caçador
+((SELECT id FROM classes WHERE nome='Caçador'), 0, 'Couro batido', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Caçador'), 0, 'Flechas', 'municao', 20, ''),
+((SELECT id FROM classes WHERE nome='Caçador'), 1, 'Arco longo', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Caçador'), 1, 'Arco curto', 'arma', 1, ''),
+-- Cavaleiro
+((SELECT id FROM classes WHERE nome='Cavaleiro'), 0, 'Cota de malha', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Cavaleiro'), 0, 'Escudo pesado', 'escudo', 1, ''),
+((SELECT id FROM classes WHERE nome='Cavaleiro'), 1, 'Espada longa', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Cavaleiro'), 1, 'Maça', 'arma', 1, ''),
+-- Clérigo
+((SELECT id FROM classes WHERE nome='Clérigo'), 0, 'Símbolo sagrado', 'item', 1, ''),
+((SELECT id FROM classes WHERE nome='Clérigo'), 0, 'Couro batido', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Clérigo'), 1, 'Maça', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Clérigo'), 1, 'Lança', 'arma', 1, ''),
+-- Druida
+((SELECT id FROM classes WHERE nome='Druida'), 0, 'Gibão de peles', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Druida'), 1, 'Bordão', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Druida'), 1, 'Lança', 'arma', 1, ''),
+-- Guerreiro
+((SELECT id FROM classes WHERE nome='Guerreiro'), 0, 'Escudo leve', 'escudo', 1, ''),
+((SELECT id FROM classes WHERE nome='Guerreiro'), 1, 'Espada longa', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Guerreiro'), 1, 'Machado de batalha', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Guerreiro'), 2, 'Cota de malha', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Guerreiro'), 2, 'Brunea', 'armadura', 1, ''),
+-- Inventor
+((SELECT id FROM classes WHERE nome='Inventor'), 0, 'Instrumentos de ofício', 'ferramenta', 1, ''),
+((SELECT id FROM classes WHERE nome='Inventor'), 0, 'Couro', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Inventor'), 1, 'Besta leve', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Inventor'), 1, 'Pistola', 'arma', 1, ''),
+-- Ladino
+((SELECT id FROM classes WHERE nome='Ladino'), 0, 'Gazua', 'ferramenta', 1, ''),
+((SELECT id FROM classes WHERE nome='Ladino'), 0, 'Couro', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Ladino'), 1, 'Adaga', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Ladino'), 1, 'Espada curta', 'arma', 1, ''),
+-- Lutador
+((SELECT id FROM classes WHERE nome='Lutador'), 0, 'Gibão de peles', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Lutador'), 1, 'Manopla', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Lutador'), 1, 'Corrente de espinhos', 'arma', 1, ''),
+-- Nobre
+((SELECT id FROM classes WHERE nome='Nobre'), 0, 'Traje da corte', 'vestuario', 1, ''),
+((SELECT id FROM classes WHERE nome='Nobre'), 0, 'Couro batido', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Nobre'), 1, 'Espada longa', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Nobre'), 1, 'Florete', 'arma', 1, ''),
+-- Paladino
+((SELECT id FROM classes WHERE nome='Paladino'), 0, 'Símbolo sagrado', 'item', 1, ''),
+((SELECT id FROM classes WHERE nome='Paladino'), 0, 'Cota de malha', 'armadura', 1, ''),
+((SELECT id FROM classes WHERE nome='Paladino'), 0, 'Escudo pesado', 'escudo', 1, ''),
+((SELECT id FROM classes WHERE nome='Paladino'), 1, 'Espada longa', 'arma', 1, ''),
+((SELECT id FROM classes WHERE nome='Paladino'), 1, 'Martelo de guerra', 'arma', 1, '');
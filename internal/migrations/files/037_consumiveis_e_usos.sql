@@ -0,0 +1,35 @@
+-- Migration: Consumíveis, munição e registro de uso de itens
+-- itens_catalogo.efeito define o que acontece ao usar o item (ex.: cura de PV)
+
+ALTER TABLE itens_catalogo ADD COLUMN IF NOT EXISTS efeito JSONB NOT NULL DEFAULT '{}';
+
+UPDATE itens_catalogo SET efeito = '{"tipo": "cura_pv", "dados": "2d4"}' WHERE nome = 'Bálsamo restaurador';
+UPDATE itens_catalogo SET efeito = '{"tipo": "cura_pm", "dados": "1d4"}' WHERE nome = 'Essência de mana';
+
+-- Seed: poções e munição (munição tem preço e espaço por unidade)
+INSERT INTO itens_catalogo (nome, categoria, preco, espacos, descricao, efeito) VALUES
+('Poção de Curar Ferimentos', 'pocao', 30, 0.5, 'Cura 2d8+2 pontos de vida.', '{"tipo": "cura_pv", "dados": "2d8+2"}'),
+('Poção de Curar Ferimentos maior', 'pocao', 270, 0.5, 'Cura 4d8+4 pontos de vida.', '{"tipo": "cura_pv", "dados": "4d8+4"}'),
+('Flechas', 'municao', 0.05, 0.05, 'Preço e espaço por unidade (pacote de 20 por T$ 1).', '{}'),
+('Virotes', 'municao', 0.1, 0.05, 'Preço e espaço por unidade (pacote de 20 por T$ 2).', '{}'),
+('Balas', 'municao', 0.1, 0.05, 'Preço e espaço por unidade (pacote de 20 por T$ 2).', '{}')
+ON CONFLICT (nome) DO NOTHING;
+
+-- PV e PM atuais (NULL = cheio)
+ALTER TABLE personagens ADD COLUMN IF NOT EXISTS pv_atual INTEGER;
+ALTER TABLE personagens ADD COLUMN IF NOT EXISTS pm_atual INTEGER;
+
+-- Registro de uso de itens
+CREATE TABLE IF NOT EXISTS personagem_item_usos (
+    id SERIAL PRIMARY KEY,
+    personagem_id INTEGER NOT NULL REFERENCES personagens(id) ON DELETE CASCADE,
+    item_id INTEGER REFERENCES personagem_itens(id) ON DELETE SET NULL,
+    nome VARCHAR(200) NOT NULL,
+    quantidade INTEGER NOT NULL DEFAULT 1,
+    efeito VARCHAR(50) DEFAULT '',
+    resultado INTEGER,
+    detalhes TEXT DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_personagem_item_usos_personagem_id ON personagem_item_usos(personagem_id, created_at);
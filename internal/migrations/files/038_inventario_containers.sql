@@ -0,0 +1,21 @@
+-- Migration: Inventário item a item com ordem estável e containers (mochilas, bolsas...)
+-- capacidade > 0 transforma o item em container; itens dentro dele não contam na carga do personagem
+
+ALTER TABLE personagem_itens ADD COLUMN IF NOT EXISTS ordem INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE personagem_itens ADD COLUMN IF NOT EXISTS container_id INTEGER REFERENCES personagem_itens(id) ON DELETE SET NULL;
+ALTER TABLE personagem_itens ADD COLUMN IF NOT EXISTS capacidade DECIMAL(8,2) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_personagem_itens_container_id ON personagem_itens(container_id);
+
+-- Ordem inicial segue a ordem de inserção
+UPDATE personagem_itens SET ordem = sub.posicao
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY personagem_id ORDER BY id) - 1 AS posicao
+    FROM personagem_itens
+) AS sub
+WHERE personagem_itens.id = sub.id;
+
+-- Containers do catálogo: a capacidade é copiada para o item ao comprar
+ALTER TABLE itens_catalogo ADD COLUMN IF NOT EXISTS capacidade DECIMAL(8,2) NOT NULL DEFAULT 0;
+UPDATE itens_catalogo SET capacidade = 10 WHERE nome = 'Mochila';
+UPDATE itens_catalogo SET capacidade = 12 WHERE nome = 'Mochila de aventureiro';
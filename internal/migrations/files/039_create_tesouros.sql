@@ -0,0 +1,290 @@
+-- Migration: Tabelas de tesouro por ND (dinheiro, riqueza e itens) e tabela de riquezas
+-- faixa_min/faixa_max correspondem à rolagem de d%. resultado: nada, tibares, riqueza, item, superior
+-- dados: quantidade rolada (T$ antes do multiplicador, número de riquezas ou de itens)
+-- categoria: categoria da riqueza (menor, media, maior) ou do item no catálogo
+
+CREATE TABLE IF NOT EXISTS tesouro_tabelas (
+    id SERIAL PRIMARY KEY,
+    nd VARCHAR(5) NOT NULL,
+    tipo VARCHAR(20) NOT NULL, -- dinheiro, riqueza, itens
+    faixa_min INTEGER NOT NULL,
+    faixa_max INTEGER NOT NULL,
+    resultado VARCHAR(20) NOT NULL,
+    dados VARCHAR(20) DEFAULT '',
+    multiplicador INTEGER NOT NULL DEFAULT 1,
+    categoria VARCHAR(50) DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_tesouro_tabelas_nd_tipo ON tesouro_tabelas(nd, tipo);
+
+CREATE TABLE IF NOT EXISTS tesouro_riquezas (
+    id SERIAL PRIMARY KEY,
+    categoria VARCHAR(20) NOT NULL, -- menor, media, maior
+    faixa_min INTEGER NOT NULL,
+    faixa_max INTEGER NOT NULL,
+    nome VARCHAR(200) NOT NULL,
+    dados VARCHAR(20) NOT NULL,
+    multiplicador INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_tesouro_riquezas_categoria ON tesouro_riquezas(categoria);
+
+INSERT INTO tesouro_tabelas (nd, tipo, faixa_min, faixa_max, resultado, dados, multiplicador, categoria) VALUES
+('1/4', 'dinheiro', 1, 30, 'nada', '', 1, ''),
+('1/4', 'dinheiro', 31, 70, 'tibares', '1d6', 5, ''),
+('1/4', 'dinheiro', 71, 95, 'tibares', '2d6', 10, ''),
+('1/4', 'dinheiro', 96, 100, 'riqueza', '1', 1, 'menor'),
+('1/4', 'riqueza', 1, 60, 'nada', '', 1, ''),
+('1/4', 'riqueza', 61, 100, 'riqueza', '1', 1, 'menor'),
+('1/4', 'itens', 1, 70, 'nada', '', 1, ''),
+('1/4', 'itens', 71, 90, 'item', '1', 1, 'geral'),
+('1/4', 'itens', 91, 100, 'item', '1', 1, 'alquimico'),
+('1/2', 'dinheiro', 1, 30, 'nada', '', 1, ''),
+('1/2', 'dinheiro', 31, 70, 'tibares', '1d6', 10, ''),
+('1/2', 'dinheiro', 71, 95, 'tibares', '2d6', 10, ''),
+('1/2', 'dinheiro', 96, 100, 'riqueza', '1', 1, 'menor'),
+('1/2', 'riqueza', 1, 60, 'nada', '', 1, ''),
+('1/2', 'riqueza', 61, 100, 'riqueza', '1', 1, 'menor'),
+('1/2', 'itens', 1, 70, 'nada', '', 1, ''),
+('1/2', 'itens', 71, 90, 'item', '1', 1, 'geral'),
+('1/2', 'itens', 91, 100, 'item', '1', 1, 'alquimico'),
+('1', 'dinheiro', 1, 20, 'nada', '', 1, ''),
+('1', 'dinheiro', 21, 70, 'tibares', '2d6', 10, ''),
+('1', 'dinheiro', 71, 90, 'tibares', '2d12', 10, ''),
+('1', 'dinheiro', 91, 100, 'riqueza', '1d2', 1, 'menor'),
+('1', 'riqueza', 1, 38, 'nada', '', 1, ''),
+('1', 'riqueza', 39, 85, 'riqueza', '1d2', 1, 'menor'),
+('1', 'riqueza', 86, 100, 'riqueza', '1', 1, 'media'),
+('1', 'itens', 1, 50, 'nada', '', 1, ''),
+('1', 'itens', 51, 75, 'item', '1', 1, 'geral'),
+('1', 'itens', 76, 90, 'item', '1d2', 1, 'alquimico'),
+('1', 'itens', 91, 100, 'item', '1', 1, 'pocao'),
+('2', 'dinheiro', 1, 20, 'nada', '', 1, ''),
+('2', 'dinheiro', 21, 70, 'tibares', '3d6', 10, ''),
+('2', 'dinheiro', 71, 90, 'tibares', '3d12', 10, ''),
+('2', 'dinheiro', 91, 100, 'riqueza', '1d2', 1, 'menor'),
+('2', 'riqueza', 1, 36, 'nada', '', 1, ''),
+('2', 'riqueza', 37, 85, 'riqueza', '1d2', 1, 'menor'),
+('2', 'riqueza', 86, 100, 'riqueza', '1', 1, 'media'),
+('2', 'itens', 1, 50, 'nada', '', 1, ''),
+('2', 'itens', 51, 75, 'item', '1', 1, 'geral'),
+('2', 'itens', 76, 90, 'item', '1d2', 1, 'alquimico'),
+('2', 'itens', 91, 100, 'item', '1', 1, 'pocao'),
+('3', 'dinheiro', 1, 20, 'nada', '', 1, ''),
+('3', 'dinheiro', 21, 70, 'tibares', '3d6', 10, ''),
+('3', 'dinheiro', 71, 90, 'tibares', '3d12', 10, ''),
+('3', 'dinheiro', 91, 100, 'riqueza', '1d2', 1, 'menor'),
+('3', 'riqueza', 1, 34, 'nada', '', 1, ''),
+('3', 'riqueza', 35, 85, 'riqueza', '1d2', 1, 'menor'),
+('3', 'riqueza', 86, 100, 'riqueza', '1', 1, 'media'),
+('3', 'itens', 1, 50, 'nada', '', 1, ''),
+('3', 'itens', 51, 75, 'item', '1', 1, 'geral'),
+('3', 'itens', 76, 90, 'item', '1d2', 1, 'alquimico'),
+('3', 'itens', 91, 100, 'item', '1', 1, 'pocao'),
+('4', 'dinheiro', 1, 20, 'nada', '', 1, ''),
+('4', 'dinheiro', 21, 70, 'tibares', '4d6', 10, ''),
+('4', 'dinheiro', 71, 90, 'tibares', '4d12', 10, ''),
+('4', 'dinheiro', 91, 100, 'riqueza', '1d2', 1, 'menor'),
+('4', 'riqueza', 1, 32, 'nada', '', 1, ''),
+('4', 'riqueza', 33, 85, 'riqueza', '1d2', 1, 'menor'),
+('4', 'riqueza', 86, 100, 'riqueza', '1', 1, 'media'),
+('4', 'itens', 1, 50, 'nada', '', 1, ''),
+('4', 'itens', 51, 75, 'item', '1', 1, 'geral'),
+('4', 'itens', 76, 90, 'item', '1d2', 1, 'alquimico'),
+('4', 'itens', 91, 100, 'item', '1', 1, 'pocao'),
+('5', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('5', 'dinheiro', 11, 70, 'tibares', '4d6', 20, ''),
+('5', 'dinheiro', 71, 90, 'tibares', '4d12', 20, ''),
+('5', 'dinheiro', 91, 100, 'riqueza', '1d2', 1, 'media'),
+('5', 'riqueza', 1, 30, 'nada', '', 1, ''),
+('5', 'riqueza', 31, 75, 'riqueza', '1d3', 1, 'menor'),
+('5', 'riqueza', 76, 95, 'riqueza', '1d2', 1, 'media'),
+('5', 'riqueza', 96, 100, 'riqueza', '1', 1, 'maior'),
+('5', 'itens', 1, 30, 'nada', '', 1, ''),
+('5', 'itens', 31, 55, 'item', '1d3', 1, 'alquimico'),
+('5', 'itens', 56, 80, 'item', '1d2', 1, 'pocao'),
+('5', 'itens', 81, 100, 'superior', '1', 1, 'ferramenta'),
+('6', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('6', 'dinheiro', 11, 70, 'tibares', '5d6', 20, ''),
+('6', 'dinheiro', 71, 90, 'tibares', '5d12', 20, ''),
+('6', 'dinheiro', 91, 100, 'riqueza', '1d2', 1, 'media'),
+('6', 'riqueza', 1, 28, 'nada', '', 1, ''),
+('6', 'riqueza', 29, 75, 'riqueza', '1d3', 1, 'menor'),
+('6', 'riqueza', 76, 95, 'riqueza', '1d2', 1, 'media'),
+('6', 'riqueza', 96, 100, 'riqueza', '1', 1, 'maior'),
+('6', 'itens', 1, 30, 'nada', '', 1, ''),
+('6', 'itens', 31, 55, 'item', '1d3', 1, 'alquimico'),
+('6', 'itens', 56, 80, 'item', '1d2', 1, 'pocao'),
+('6', 'itens', 81, 100, 'superior', '1', 1, 'ferramenta'),
+('7', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('7', 'dinheiro', 11, 70, 'tibares', '5d6', 20, ''),
+('7', 'dinheiro', 71, 90, 'tibares', '5d12', 20, ''),
+('7', 'dinheiro', 91, 100, 'riqueza', '1d2', 1, 'media'),
+('7', 'riqueza', 1, 26, 'nada', '', 1, ''),
+('7', 'riqueza', 27, 75, 'riqueza', '1d3', 1, 'menor'),
+('7', 'riqueza', 76, 95, 'riqueza', '1d2', 1, 'media'),
+('7', 'riqueza', 96, 100, 'riqueza', '1', 1, 'maior'),
+('7', 'itens', 1, 30, 'nada', '', 1, ''),
+('7', 'itens', 31, 55, 'item', '1d3', 1, 'alquimico'),
+('7', 'itens', 56, 80, 'item', '1d2', 1, 'pocao'),
+('7', 'itens', 81, 100, 'superior', '1', 1, 'ferramenta'),
+('8', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('8', 'dinheiro', 11, 70, 'tibares', '6d6', 20, ''),
+('8', 'dinheiro', 71, 90, 'tibares', '6d12', 20, ''),
+('8', 'dinheiro', 91, 100, 'riqueza', '1d2', 1, 'media'),
+('8', 'riqueza', 1, 24, 'nada', '', 1, ''),
+('8', 'riqueza', 25, 75, 'riqueza', '1d3', 1, 'menor'),
+('8', 'riqueza', 76, 95, 'riqueza', '1d2', 1, 'media'),
+('8', 'riqueza', 96, 100, 'riqueza', '1', 1, 'maior'),
+('8', 'itens', 1, 30, 'nada', '', 1, ''),
+('8', 'itens', 31, 55, 'item', '1d3', 1, 'alquimico'),
+('8', 'itens', 56, 80, 'item', '1d2', 1, 'pocao'),
+('8', 'itens', 81, 100, 'superior', '1', 1, 'ferramenta'),
+('9', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('9', 'dinheiro', 11, 70, 'tibares', '6d6', 20, ''),
+('9', 'dinheiro', 71, 90, 'tibares', '6d12', 20, ''),
+('9', 'dinheiro', 91, 100, 'riqueza', '1d2', 1, 'media'),
+('9', 'riqueza', 1, 22, 'nada', '', 1, ''),
+('9', 'riqueza', 23, 75, 'riqueza', '1d3', 1, 'menor'),
+('9', 'riqueza', 76, 95, 'riqueza', '1d2', 1, 'media'),
+('9', 'riqueza', 96, 100, 'riqueza', '1', 1, 'maior'),
+('9', 'itens', 1, 30, 'nada', '', 1, ''),
+('9', 'itens', 31, 55, 'item', '1d3', 1, 'alquimico'),
+('9', 'itens', 56, 80, 'item', '1d2', 1, 'pocao'),
+('9', 'itens', 81, 100, 'superior', '1', 1, 'ferramenta'),
+('10', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('10', 'dinheiro', 11, 70, 'tibares', '7d6', 30, ''),
+('10', 'dinheiro', 71, 90, 'tibares', '7d12', 30, ''),
+('10', 'dinheiro', 91, 100, 'riqueza', '1d2', 1, 'media'),
+('10', 'riqueza', 1, 20, 'nada', '', 1, ''),
+('10', 'riqueza', 21, 75, 'riqueza', '1d3', 1, 'menor'),
+('10', 'riqueza', 76, 95, 'riqueza', '1d2', 1, 'media'),
+('10', 'riqueza', 96, 100, 'riqueza', '1', 1, 'maior'),
+('10', 'itens', 1, 30, 'nada', '', 1, ''),
+('10', 'itens', 31, 55, 'item', '1d3', 1, 'alquimico'),
+('10', 'itens', 56, 80, 'item', '1d2', 1, 'pocao'),
+('10', 'itens', 81, 100, 'superior', '1', 1, 'ferramenta'),
+('11', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('11', 'dinheiro', 11, 70, 'tibares', '7d6', 30, ''),
+('11', 'dinheiro', 71, 90, 'tibares', '7d12', 30, ''),
+('11', 'dinheiro', 91, 100, 'riqueza', '1d3', 1, 'maior'),
+('11', 'riqueza', 1, 18, 'nada', '', 1, ''),
+('11', 'riqueza', 19, 60, 'riqueza', '1d3', 1, 'media'),
+('11', 'riqueza', 61, 100, 'riqueza', '1d2', 1, 'maior'),
+('11', 'itens', 1, 15, 'nada', '', 1, ''),
+('11', 'itens', 16, 40, 'item', '1d4', 1, 'pocao'),
+('11', 'itens', 41, 75, 'superior', '1d2', 1, 'ferramenta'),
+('11', 'itens', 76, 100, 'superior', '1d2', 1, 'vestuario'),
+('12', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('12', 'dinheiro', 11, 70, 'tibares', '8d6', 30, ''),
+('12', 'dinheiro', 71, 90, 'tibares', '8d12', 30, ''),
+('12', 'dinheiro', 91, 100, 'riqueza', '1d3', 1, 'maior'),
+('12', 'riqueza', 1, 16, 'nada', '', 1, ''),
+('12', 'riqueza', 17, 60, 'riqueza', '1d3', 1, 'media'),
+('12', 'riqueza', 61, 100, 'riqueza', '1d2', 1, 'maior'),
+('12', 'itens', 1, 15, 'nada', '', 1, ''),
+('12', 'itens', 16, 40, 'item', '1d4', 1, 'pocao'),
+('12', 'itens', 41, 75, 'superior', '1d2', 1, 'ferramenta'),
+('12', 'itens', 76, 100, 'superior', '1d2', 1, 'vestuario'),
+('13', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('13', 'dinheiro', 11, 70, 'tibares', '8d6', 30, ''),
+('13', 'dinheiro', 71, 90, 'tibares', '8d12', 30, ''),
+('13', 'dinheiro', 91, 100, 'riqueza', '1d3', 1, 'maior'),
+('13', 'riqueza', 1, 14, 'nada', '', 1, ''),
+('13', 'riqueza', 15, 60, 'riqueza', '1d3', 1, 'media'),
+('13', 'riqueza', 61, 100, 'riqueza', '1d2', 1, 'maior'),
+('13', 'itens', 1, 15, 'nada', '', 1, ''),
+('13', 'itens', 16, 40, 'item', '1d4', 1, 'pocao'),
+('13', 'itens', 41, 75, 'superior', '1d2', 1, 'ferramenta'),
+('13', 'itens', 76, 100, 'superior', '1d2', 1, 'vestuario'),
+('14', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('14', 'dinheiro', 11, 70, 'tibares', '9d6', 30, ''),
+('14', 'dinheiro', 71, 90, 'tibares', '9d12', 30, ''),
+('14', 'dinheiro', 91, 100, 'riqueza', '1d3', 1, 'maior'),
+('14', 'riqueza', 1, 12, 'nada', '', 1, ''),
+('14', 'riqueza', 13, 60, 'riqueza', '1d3', 1, 'media'),
+('14', 'riqueza', 61, 100, 'riqueza', '1d2', 1, 'maior'),
+('14', 'itens', 1, 15, 'nada', '', 1, ''),
+('14', 'itens', 16, 40, 'item', '1d4', 1, 'pocao'),
+('14', 'itens', 41, 75, 'superior', '1d2', 1, 'ferramenta'),
+('14', 'itens', 76, 100, 'superior', '1d2', 1, 'vestuario'),
+('15', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('15', 'dinheiro', 11, 70, 'tibares', '9d6', 40, ''),
+('15', 'dinheiro', 71, 90, 'tibares', '9d12', 40, ''),
+('15', 'dinheiro', 91, 100, 'riqueza', '1d3', 1, 'maior'),
+('15', 'riqueza', 1, 10, 'nada', '', 1, ''),
+('15', 'riqueza', 11, 60, 'riqueza', '1d3', 1, 'media'),
+('15', 'riqueza', 61, 100, 'riqueza', '1d2', 1, 'maior'),
+('15', 'itens', 1, 15, 'nada', '', 1, ''),
+('15', 'itens', 16, 40, 'item', '1d4', 1, 'pocao'),
+('15', 'itens', 41, 75, 'superior', '1d2', 1, 'ferramenta'),
+('15', 'itens', 76, 100, 'superior', '1d2', 1, 'vestuario'),
+('16', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('16', 'dinheiro', 11, 70, 'tibares', '10d6', 40, ''),
+('16', 'dinheiro', 71, 90, 'tibares', '10d12', 40, ''),
+('16', 'dinheiro', 91, 100, 'riqueza', '1d3', 1, 'maior'),
+('16', 'riqueza', 1, 8, 'nada', '', 1, ''),
+('16', 'riqueza', 9, 60, 'riqueza', '1d3', 1, 'media'),
+('16', 'riqueza', 61, 100, 'riqueza', '1d2', 1, 'maior'),
+('16', 'itens', 1, 15, 'nada', '', 1, ''),
+('16', 'itens', 16, 40, 'item', '1d4', 1, 'pocao'),
+('16', 'itens', 41, 75, 'superior', '1d2', 1, 'ferramenta'),
+('16', 'itens', 76, 100, 'superior', '1d2', 1, 'vestuario'),
+('17', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('17', 'dinheiro', 11, 70, 'tibares', '10d6', 40, ''),
+('17', 'dinheiro', 71, 90, 'tibares', '10d12', 40, ''),
+('17', 'dinheiro', 91, 100, 'riqueza', '1d3', 1, 'maior'),
+('17', 'riqueza', 1, 6, 'nada', '', 1, ''),
+('17', 'riqueza', 7, 60, 'riqueza', '1d3', 1, 'media'),
+('17', 'riqueza', 61, 100, 'riqueza', '1d2', 1, 'maior'),
+('17', 'itens', 1, 15, 'nada', '', 1, ''),
+('17', 'itens', 16, 40, 'item', '1d4', 1, 'pocao'),
+('17', 'itens', 41, 75, 'superior', '1d2', 1, 'ferramenta'),
+('17', 'itens', 76, 100, 'superior', '1d2', 1, 'vestuario'),
+('18', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('18', 'dinheiro', 11, 70, 'tibares', '11d6', 40, ''),
+('18', 'dinheiro', 71, 90, 'tibares', '11d12', 40, ''),
+('18', 'dinheiro', 91, 100, 'riqueza', '1d3', 1, 'maior'),
+('18', 'riqueza', 1, 5, 'nada', '', 1, ''),
+('18', 'riqueza', 6, 60, 'riqueza', '1d3', 1, 'media'),
+('18', 'riqueza', 61, 100, 'riqueza', '1d2', 1, 'maior'),
+('18', 'itens', 1, 15, 'nada', '', 1, ''),
+('18', 'itens', 16, 40, 'item', '1d4', 1, 'pocao'),
+('18', 'itens', 41, 75, 'superior', '1d2', 1, 'ferramenta'),
+('18', 'itens', 76, 100, 'superior', '1d2', 1, 'vestuario'),
+('19', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('19', 'dinheiro', 11, 70, 'tibares', '11d6', 40, ''),
+('19', 'dinheiro', 71, 90, 'tibares', '11d12', 40, ''),
+('19', 'dinheiro', 91, 100, 'riqueza', '1d3', 1, 'maior'),
+('19', 'riqueza', 1, 5, 'nada', '', 1, ''),
+('19', 'riqueza', 6, 60, 'riqueza', '1d3', 1, 'media'),
+('19', 'riqueza', 61, 100, 'riqueza', '1d2', 1, 'maior'),
+('19', 'itens', 1, 15, 'nada', '', 1, ''),
+('19', 'itens', 16, 40, 'item', '1d4', 1, 'pocao'),
+('19', 'itens', 41, 75, 'superior', '1d2', 1, 'ferramenta'),
+('19', 'itens', 76, 100, 'superior', '1d2', 1, 'vestuario'),
+('20', 'dinheiro', 1, 10, 'nada', '', 1, ''),
+('20', 'dinheiro', 11, 70, 'tibares', '12d6', 50, ''),
+('20', 'dinheiro', 71, 90, 'tibares', '12d12', 50, ''),
+('20', 'dinheiro', 91, 100, 'riqueza', '1d3', 1, 'maior'),
+('20', 'riqueza', 1, 5, 'nada', '', 1, ''),
+('20', 'riqueza', 6, 60, 'riqueza', '1d3', 1, 'media'),
+('20', 'riqueza', 61, 100, 'riqueza', '1d2', 1, 'maior'),
+('20', 'itens', 1, 15, 'nada', '', 1, ''),
+('20', 'itens', 16, 40, 'item', '1d4', 1, 'pocao'),
+('20', 'itens', 41, 75, 'superior', '1d2', 1, 'ferramenta'),
+('20', 'itens', 76, 100, 'superior', '1d2', 1, 'vestuario');
+
+INSERT INTO tesouro_riquezas (categoria, faixa_min, faixa_max, nome, dados, multiplicador) VALUES
+('menor', 1, 25, 'Ídolo de pedra', '4d4', 1),
+('menor', 26, 50, 'Frasco de perfume raro', '1d4', 10),
+('menor', 51, 75, 'Pedaço de âmbar', '2d4', 10),
+('menor', 76, 100, 'Anel de prata', '4d6', 10),
+('media', 1, 25, 'Cálice de ouro', '2d4', 100),
+('media', 26, 50, 'Pérola negra', '1d6', 100),
+('media', 51, 75, 'Tapeçaria élfica', '3d6', 100),
+('media', 76, 100, 'Esmeralda lapidada', '2d10', 100),
+('maior', 1, 25, 'Coroa cravejada', '2d6', 1000),
+('maior', 26, 50, 'Estatueta de mitral', '1d10', 1000),
+('maior', 51, 75, 'Diamante estrela', '4d6', 1000),
+('maior', 76, 100, 'Relíquia de um deus menor', '2d10', 1000);
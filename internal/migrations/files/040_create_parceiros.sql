@@ -0,0 +1,73 @@
+-- Migration: Parceiros (ajudantes, montarias, guardiões...) concedidos por poderes e habilidades
+-- parceiro_tipos guarda o bônus padrão de cada tipo por patamar; personagem_parceiros copia esses
+-- valores ao criar o parceiro e permite ajustá-los. Bônus do mesmo tipo não se acumulam.
+
+CREATE TABLE IF NOT EXISTS parceiro_tipos (
+    id SERIAL PRIMARY KEY,
+    tipo VARCHAR(30) NOT NULL,
+    patamar VARCHAR(20) NOT NULL CHECK (patamar IN ('iniciante', 'veterano', 'mestre')),
+    bonus_ataque INTEGER NOT NULL DEFAULT 0,
+    bonus_dano INTEGER NOT NULL DEFAULT 0,
+    bonus_defesa INTEGER NOT NULL DEFAULT 0,
+    bonus_pericia INTEGER NOT NULL DEFAULT 0,
+    pericias JSONB,
+    descricao TEXT DEFAULT '',
+    UNIQUE (tipo, patamar)
+);
+
+CREATE TABLE IF NOT EXISTS personagem_parceiros (
+    id SERIAL PRIMARY KEY,
+    personagem_id INTEGER NOT NULL REFERENCES personagens(id) ON DELETE CASCADE,
+    nome VARCHAR(100) NOT NULL,
+    tipo VARCHAR(30) NOT NULL,
+    patamar VARCHAR(20) NOT NULL DEFAULT 'iniciante' CHECK (patamar IN ('iniciante', 'veterano', 'mestre')),
+    fonte VARCHAR(100) DEFAULT '',
+    ativo BOOLEAN NOT NULL DEFAULT TRUE,
+    bonus_ataque INTEGER NOT NULL DEFAULT 0,
+    bonus_dano INTEGER NOT NULL DEFAULT 0,
+    bonus_defesa INTEGER NOT NULL DEFAULT 0,
+    bonus_pericia INTEGER NOT NULL DEFAULT 0,
+    pericias JSONB NOT NULL DEFAULT '[]',
+    descricao TEXT DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_personagem_parceiros_personagem_id ON personagem_parceiros(personagem_id);
+
+-- Pericias NULL com bonus_pericia > 0: o jogador escolhe as perícias (ajudante)
+INSERT INTO parceiro_tipos (tipo, patamar, bonus_ataque, bonus_dano, bonus_defesa, bonus_pericia, pericias, descricao) VALUES
+('ajudante', 'iniciante', 0, 0, 0, 2, NULL, 'Bônus em duas perícias escolhidas'),
+('ajudante', 'veterano', 0, 0, 0, 4, NULL, 'Bônus em duas perícias escolhidas'),
+('ajudante', 'mestre', 0, 0, 0, 6, NULL, 'Bônus em duas perícias escolhidas'),
+('assassino', 'iniciante', 0, 0, 0, 0, '[]', '+1d6 em rolagens de dano de ataques furtivos'),
+('assassino', 'veterano', 0, 0, 0, 0, '[]', '+2d6 em rolagens de dano de ataques furtivos'),
+('assassino', 'mestre', 0, 0, 0, 0, '[]', '+3d6 em rolagens de dano de ataques furtivos'),
+('atirador', 'iniciante', 0, 0, 0, 0, '[]', '+1d6 em rolagens de dano de ataques à distância'),
+('atirador', 'veterano', 0, 0, 0, 0, '[]', '+1d10 em rolagens de dano de ataques à distância'),
+('atirador', 'mestre', 0, 0, 0, 0, '[]', '+2d8 em rolagens de dano de ataques à distância'),
+('combatente', 'iniciante', 2, 0, 0, 0, '[]', 'Bônus em testes de ataque'),
+('combatente', 'veterano', 3, 0, 0, 0, '[]', 'Bônus em testes de ataque'),
+('combatente', 'mestre', 4, 0, 0, 0, '[]', 'Bônus em testes de ataque'),
+('fortao', 'iniciante', 0, 2, 0, 0, '[]', 'Bônus em rolagens de dano corpo a corpo'),
+('fortao', 'veterano', 0, 4, 0, 0, '[]', 'Bônus em rolagens de dano corpo a corpo'),
+('fortao', 'mestre', 0, 6, 0, 0, '[]', 'Bônus em rolagens de dano corpo a corpo'),
+('guardiao', 'iniciante', 0, 0, 2, 0, '[]', 'Bônus na Defesa'),
+('guardiao', 'veterano', 0, 0, 3, 0, '[]', 'Bônus na Defesa'),
+('guardiao', 'mestre', 0, 0, 4, 0, '[]', 'Bônus na Defesa'),
+('medico', 'iniciante', 0, 0, 0, 0, '[]', 'Uma vez por rodada, cura 1d8 PV de um aliado'),
+('medico', 'veterano', 0, 0, 0, 0, '[]', 'Uma vez por rodada, cura 2d8 PV de um aliado'),
+('medico', 'mestre', 0, 0, 0, 0, '[]', 'Uma vez por rodada, cura 4d8 PV de um aliado'),
+('montaria', 'iniciante', 0, 0, 0, 0, '[]', 'Deslocamento 12m para o cavaleiro'),
+('montaria', 'veterano', 0, 0, 0, 2, '["Cavalgar"]', 'Deslocamento 15m para o cavaleiro e bônus em Cavalgar'),
+('montaria', 'mestre', 0, 0, 0, 4, '["Cavalgar"]', 'Deslocamento 18m para o cavaleiro e bônus em Cavalgar'),
+('perseguidor', 'iniciante', 0, 0, 0, 2, '["Percepção", "Sobrevivência"]', 'Bônus em Percepção e Sobrevivência'),
+('perseguidor', 'veterano', 0, 0, 0, 4, '["Percepção", "Sobrevivência"]', 'Bônus em Percepção e Sobrevivência'),
+('perseguidor', 'mestre', 0, 0, 0, 6, '["Percepção", "Sobrevivência"]', 'Bônus em Percepção e Sobrevivência'),
+('vigilante', 'iniciante', 0, 0, 0, 2, '["Percepção", "Iniciativa"]', 'Bônus em Percepção e Iniciativa'),
+('vigilante', 'veterano', 0, 0, 0, 4, '["Percepção", "Iniciativa"]', 'Bônus em Percepção e Iniciativa'),
+('vigilante', 'mestre', 0, 0, 0, 6, '["Percepção", "Iniciativa"]', 'Bônus em Percepção e Iniciativa'),
+('adepto', 'iniciante', 0, 0, 0, 0, '[]', '+1 PM por cena para lançar magias'),
+('adepto', 'veterano', 0, 0, 0, 0, '[]', '+2 PM por cena para lançar magias'),
+('adepto', 'mestre', 0, 0, 0, 0, '[]', '+3 PM por cena para lançar magias')
+ON CONFLICT (tipo, patamar) DO NOTHING;
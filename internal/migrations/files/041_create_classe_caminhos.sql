@@ -0,0 +1,41 @@
+-- Migration: Caminhos de classe (escolhas que definem a build, como Bruxo/Feiticeiro/Mago do Arcanista)
+-- atributo_chave vazio mantém o atributo de magia da classe; habilidades com caminho_id só valem
+-- para personagens que escolheram aquele caminho.
+
+ALTER TABLE classes ADD COLUMN IF NOT EXISTS atributo_magia VARCHAR(3) DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS classe_caminhos (
+    id SERIAL PRIMARY KEY,
+    classe_id INTEGER NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    nome VARCHAR(100) NOT NULL,
+    descricao TEXT DEFAULT '',
+    atributo_chave VARCHAR(3) DEFAULT '',
+    nivel_escolha INTEGER NOT NULL DEFAULT 1,
+    UNIQUE (classe_id, nome)
+);
+
+CREATE INDEX IF NOT EXISTS idx_classe_caminhos_classe_id ON classe_caminhos(classe_id);
+
+ALTER TABLE habilidade_classes ADD COLUMN IF NOT EXISTS caminho_id INTEGER REFERENCES classe_caminhos(id) ON DELETE CASCADE;
+ALTER TABLE personagens ADD COLUMN IF NOT EXISTS caminho_id INTEGER REFERENCES classe_caminhos(id) ON DELETE SET NULL;
+
+-- Seed: atributo de magia das classes conjuradoras (Arcanista depende do caminho)
+UPDATE classes SET atributo_magia = 'CAR' WHERE nome = 'Bardo';
+UPDATE classes SET atributo_magia = 'SAB' WHERE nome IN ('Clérigo', 'Druida');
+
+-- Seed: caminhos
+INSERT INTO classe_caminhos (classe_id, nome, descricao, atributo_chave, nivel_escolha) VALUES
+((SELECT id FROM classes WHERE nome = 'Arcanista'), 'Bruxo', 'Você lança magias através de um foco arcano. Seu atributo-chave para magias é Inteligência.', 'INT', 1),
+((SELECT id FROM classes WHERE nome = 'Arcanista'), 'Feiticeiro', 'Você lança magias por um poder inato herdado de sua linhagem. Seu atributo-chave para magias é Carisma.', 'CAR', 1),
+((SELECT id FROM classes WHERE nome = 'Arcanista'), 'Mago', 'Você lança magias estudadas em seu grimório. Seu atributo-chave para magias é Inteligência.', 'INT', 1),
+((SELECT id FROM classes WHERE nome = 'Cavaleiro'), 'Bastião', 'Se estiver usando armadura pesada, você recebe redução de dano 5.', '', 5),
+((SELECT id FROM classes WHERE nome = 'Cavaleiro'), 'Montaria', 'Você recebe um cavalo de guerra como parceiro veterano do tipo montaria.', '', 5)
+ON CONFLICT (classe_id, nome) DO NOTHING;
+
+-- Seed: habilidades exclusivas de cada caminho
+INSERT INTO habilidade_classes (classe_id, caminho_id, nome, descricao, nivel, opcional) VALUES
+((SELECT id FROM classes WHERE nome = 'Arcanista'), (SELECT cc.id FROM classe_caminhos cc JOIN classes c ON c.id = cc.classe_id WHERE c.nome = 'Arcanista' AND cc.nome = 'Bruxo'), 'Foco Arcano', 'Você precisa empunhar seu foco (um cajado, varinha, orbe...) para lançar magias. Se perder o foco, pode criar outro com um dia de trabalho.', 1, FALSE),
+((SELECT id FROM classes WHERE nome = 'Arcanista'), (SELECT cc.id FROM classe_caminhos cc JOIN classes c ON c.id = cc.classe_id WHERE c.nome = 'Arcanista' AND cc.nome = 'Feiticeiro'), 'Linhagem Sobrenatural', 'Sua magia vem do sangue. Escolha uma linhagem (dracônica, feérica ou rubra) e receba seu benefício básico.', 1, FALSE),
+((SELECT id FROM classes WHERE nome = 'Arcanista'), (SELECT cc.id FROM classe_caminhos cc JOIN classes c ON c.id = cc.classe_id WHERE c.nome = 'Arcanista' AND cc.nome = 'Mago'), 'Grimório', 'Você aprende magias estudando e precisa de seu grimório para prepará-las. Começa com uma magia adicional.', 1, FALSE),
+((SELECT id FROM classes WHERE nome = 'Cavaleiro'), (SELECT cc.id FROM classe_caminhos cc JOIN classes c ON c.id = cc.classe_id WHERE c.nome = 'Cavaleiro' AND cc.nome = 'Bastião'), 'Bastião', 'Se estiver usando armadura pesada, você recebe redução de dano 5.', 5, FALSE),
+((SELECT id FROM classes WHERE nome = 'Cavaleiro'), (SELECT cc.id FROM classe_caminhos cc JOIN classes c ON c.id = cc.classe_id WHERE c.nome = 'Cavaleiro' AND cc.nome = 'Montaria'), 'Montaria', 'Você recebe um cavalo de guerra, um parceiro veterano do tipo montaria.', 5, FALSE);
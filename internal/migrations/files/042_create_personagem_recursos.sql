@@ -0,0 +1,18 @@
+-- Migration: Recursos limitados do personagem (usos por cena/dia/aventura e cargas de itens)
+-- maximo é uma fórmula como "1 + CAR"; atual NULL indica o recurso cheio. reinicio define
+-- quando o recurso volta ao máximo. item_id liga cargas a um item do inventário.
+
+CREATE TABLE IF NOT EXISTS personagem_recursos (
+    id SERIAL PRIMARY KEY,
+    personagem_id INTEGER NOT NULL REFERENCES personagens(id) ON DELETE CASCADE,
+    nome VARCHAR(100) NOT NULL,
+    maximo VARCHAR(100) NOT NULL DEFAULT '1',
+    atual INTEGER CHECK (atual >= 0),
+    reinicio VARCHAR(20) NOT NULL DEFAULT 'dia' CHECK (reinicio IN ('cena', 'dia', 'descanso', 'aventura')),
+    item_id INTEGER REFERENCES personagem_itens(id) ON DELETE CASCADE,
+    descricao TEXT DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_personagem_recursos_personagem_id ON personagem_recursos(personagem_id);
@@ -0,0 +1,19 @@
+-- Migration: Efeitos ativos (bônus e penalidades temporários de magias e habilidades)
+-- alvo define o que é modificado; para alvo 'pericia', detalhe é o nome da perícia (vazio = todas).
+-- restante é a duração que falta na unidade do efeito; efeitos expirados são removidos ao avançar o tempo.
+
+CREATE TABLE IF NOT EXISTS personagem_efeitos (
+    id SERIAL PRIMARY KEY,
+    personagem_id INTEGER NOT NULL REFERENCES personagens(id) ON DELETE CASCADE,
+    nome VARCHAR(100) NOT NULL,
+    fonte VARCHAR(100) DEFAULT '',
+    alvo VARCHAR(20) NOT NULL CHECK (alvo IN ('defesa', 'ataque', 'dano', 'pericia', 'pv', 'pm')),
+    detalhe VARCHAR(100) DEFAULT '',
+    modificador INTEGER NOT NULL,
+    unidade VARCHAR(20) NOT NULL CHECK (unidade IN ('rodada', 'cena', 'dia')),
+    restante INTEGER NOT NULL CHECK (restante >= 1),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_personagem_efeitos_personagem_id ON personagem_efeitos(personagem_id);
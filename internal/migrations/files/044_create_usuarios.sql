@@ -0,0 +1,28 @@
+-- Migration: Contas de usuário (e-mail + senha) com sessões no servidor
+-- O token da sessão fica apenas com o cliente; o banco guarda o SHA-256 dele.
+-- personagens.usuario_id substitui a posse por cookie/IP para quem tem conta.
+
+CREATE TABLE IF NOT EXISTS usuarios (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    nome VARCHAR(100) DEFAULT '',
+    senha_hash VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_usuarios_email ON usuarios(LOWER(email));
+
+CREATE TABLE IF NOT EXISTS usuario_sessoes (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    user_agent VARCHAR(255) DEFAULT '',
+    expira_em TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_usuario_sessoes_usuario_id ON usuario_sessoes(usuario_id);
+
+ALTER TABLE personagens ADD COLUMN IF NOT EXISTS usuario_id INTEGER REFERENCES usuarios(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_personagens_usuario_id ON personagens(usuario_id);
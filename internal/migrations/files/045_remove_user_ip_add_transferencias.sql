@@ -0,0 +1,20 @@
+-- Migration: Remove a posse de personagens por IP e adiciona códigos de transferência de sessão
+-- O IP bruto deixa de ser salvo; user_ip_hash guarda só um hash com salt para limitar abusos.
+-- Vincular outro dispositivo passa a exigir um código de uso único gerado pela sessão de origem.
+
+DROP INDEX IF EXISTS idx_personagens_user_identification;
+DROP INDEX IF EXISTS idx_personagens_user_ip;
+ALTER TABLE personagens DROP COLUMN IF EXISTS user_ip;
+ALTER TABLE personagens ADD COLUMN IF NOT EXISTS user_ip_hash CHAR(64);
+
+CREATE TABLE IF NOT EXISTS sessao_transferencias (
+    id SERIAL PRIMARY KEY,
+    codigo_hash CHAR(64) NOT NULL UNIQUE,
+    user_session_id VARCHAR(36) NOT NULL,
+    user_ip_hash CHAR(64),
+    expira_em TIMESTAMP NOT NULL,
+    usado_em TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessao_transferencias_user_session_id ON sessao_transferencias(user_session_id);
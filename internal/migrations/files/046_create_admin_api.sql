@@ -0,0 +1,31 @@
+-- Migration: Papéis de usuário, chaves de API com escopos e auditoria das alterações no catálogo
+-- Apenas administradores (ou chaves com o escopo catalogo:escrita) alteram raças, classes,
+-- origens, divindades, poderes, perícias e itens. Toda escrita no catálogo fica registrada.
+
+ALTER TABLE usuarios ADD COLUMN IF NOT EXISTS papel VARCHAR(20) NOT NULL DEFAULT 'jogador' CHECK (papel IN ('jogador', 'admin'));
+
+CREATE TABLE IF NOT EXISTS api_chaves (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(100) NOT NULL,
+    prefixo VARCHAR(12) NOT NULL,
+    chave_hash CHAR(64) NOT NULL UNIQUE,
+    escopos JSONB NOT NULL DEFAULT '[]',
+    usuario_id INTEGER REFERENCES usuarios(id) ON DELETE SET NULL,
+    ultimo_uso_em TIMESTAMP,
+    revogada_em TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS auditoria_catalogo (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER REFERENCES usuarios(id) ON DELETE SET NULL,
+    api_chave_id INTEGER REFERENCES api_chaves(id) ON DELETE SET NULL,
+    metodo VARCHAR(10) NOT NULL,
+    rota VARCHAR(200) NOT NULL,
+    recurso_id VARCHAR(20) DEFAULT '',
+    status INTEGER NOT NULL,
+    dados TEXT DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auditoria_catalogo_created_at ON auditoria_catalogo(created_at);
@@ -0,0 +1,18 @@
+-- Migration: Links públicos de leitura para personagens
+-- Apenas o hash SHA-256 do token é salvo. ocultar lista campos privados omitidos na ficha
+-- compartilhada (ex: ["anotacoes"]). Revogar ou expirar o link corta o acesso na hora.
+
+CREATE TABLE IF NOT EXISTS personagem_compartilhamentos (
+    id SERIAL PRIMARY KEY,
+    personagem_id INTEGER NOT NULL REFERENCES personagens(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    prefixo VARCHAR(12) NOT NULL,
+    ocultar JSONB NOT NULL DEFAULT '[]',
+    expira_em TIMESTAMP,
+    revogado_em TIMESTAMP,
+    acessos INTEGER NOT NULL DEFAULT 0,
+    ultimo_acesso_em TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_personagem_compartilhamentos_personagem_id ON personagem_compartilhamentos(personagem_id);
@@ -0,0 +1,27 @@
+-- Migration: Mesas (campanhas) com mestre e jogadores
+-- Jogadores entram com o código de convite da mesa e vinculam seus personagens (personagens.mesa_id).
+-- O mestre vê as fichas dos personagens da mesa; editá-las depende de mestre_edita.
+
+CREATE TABLE IF NOT EXISTS mesas (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(100) NOT NULL,
+    descricao TEXT DEFAULT '',
+    codigo_convite VARCHAR(20) NOT NULL UNIQUE,
+    mestre_edita BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS mesa_membros (
+    id SERIAL PRIMARY KEY,
+    mesa_id INTEGER NOT NULL REFERENCES mesas(id) ON DELETE CASCADE,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    papel VARCHAR(20) NOT NULL DEFAULT 'jogador' CHECK (papel IN ('mestre', 'jogador')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (mesa_id, usuario_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mesa_membros_usuario_id ON mesa_membros(usuario_id);
+
+ALTER TABLE personagens ADD COLUMN IF NOT EXISTS mesa_id INTEGER REFERENCES mesas(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_personagens_mesa_id ON personagens(mesa_id);
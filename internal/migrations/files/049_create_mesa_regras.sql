@@ -0,0 +1,23 @@
+-- Migration: Regras da casa por mesa (point-buy, livros, raças/classes banidas, PV, nível e dinheiro inicial)
+-- Mesas sem linha em mesa_regras usam as regras padrão. Listas vazias não restringem nada.
+-- livro identifica o livro de origem de raças, classes, origens e divindades ('basico' = Livro Básico).
+
+CREATE TABLE IF NOT EXISTS mesa_regras (
+    mesa_id INTEGER PRIMARY KEY REFERENCES mesas(id) ON DELETE CASCADE,
+    pontos_atributos INTEGER NOT NULL DEFAULT 10 CHECK (pontos_atributos BETWEEN 0 AND 30),
+    livros_permitidos JSONB NOT NULL DEFAULT '[]',
+    racas_banidas JSONB NOT NULL DEFAULT '[]',
+    classes_banidas JSONB NOT NULL DEFAULT '[]',
+    pv_por_nivel VARCHAR(10) NOT NULL DEFAULT 'fixo' CHECK (pv_por_nivel IN ('fixo', 'rolado')),
+    nivel_inicial INTEGER NOT NULL DEFAULT 1 CHECK (nivel_inicial BETWEEN 1 AND 20),
+    dinheiro_inicial DECIMAL(10,2) CHECK (dinheiro_inicial >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE racas ADD COLUMN IF NOT EXISTS livro VARCHAR(50) NOT NULL DEFAULT 'basico';
+ALTER TABLE classes ADD COLUMN IF NOT EXISTS livro VARCHAR(50) NOT NULL DEFAULT 'basico';
+ALTER TABLE origens ADD COLUMN IF NOT EXISTS livro VARCHAR(50) NOT NULL DEFAULT 'basico';
+ALTER TABLE divindades ADD COLUMN IF NOT EXISTS livro VARCHAR(50) NOT NULL DEFAULT 'basico';
+
+-- PV rolados a partir do 2º nível (um valor por nível), usados quando a mesa rola PV
+ALTER TABLE personagens ADD COLUMN IF NOT EXISTS pv_rolagens JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,19 @@
+-- Migration: Aprovação de personagens pelo mestre e bloqueio da ficha
+-- rascunho -> enviado (dono) -> aprovado (mestre) -> bloqueado (mestre); o mestre pode rejeitar
+-- um envio (volta a rascunho, com comentário) e desbloquear a ficha. Toda transição fica no histórico.
+
+ALTER TABLE personagens ADD COLUMN IF NOT EXISTS estado VARCHAR(20) NOT NULL DEFAULT 'rascunho'
+    CHECK (estado IN ('rascunho', 'enviado', 'aprovado', 'bloqueado'));
+
+CREATE TABLE IF NOT EXISTS personagem_estados (
+    id SERIAL PRIMARY KEY,
+    personagem_id INTEGER NOT NULL REFERENCES personagens(id) ON DELETE CASCADE,
+    de VARCHAR(20) NOT NULL,
+    para VARCHAR(20) NOT NULL,
+    usuario_id INTEGER REFERENCES usuarios(id) ON DELETE SET NULL,
+    papel VARCHAR(20) NOT NULL CHECK (papel IN ('dono', 'mestre', 'sistema')),
+    comentario TEXT DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_personagem_estados_personagem_id ON personagem_estados(personagem_id);
@@ -0,0 +1,16 @@
+-- Migration: Histórico de versões dos personagens
+-- Cada salvamento da ficha grava uma foto imutável (dados), com perícias, benefícios, poderes
+-- e itens. Personagens anteriores ao histórico ganham uma versão 'base' antes da primeira edição.
+-- versao cresce por personagem; a retenção apaga as versões mais antigas, nunca a última.
+
+CREATE TABLE IF NOT EXISTS personagem_versoes (
+    id SERIAL PRIMARY KEY,
+    personagem_id INTEGER NOT NULL REFERENCES personagens(id) ON DELETE CASCADE,
+    versao INTEGER NOT NULL,
+    dados JSONB NOT NULL,
+    usuario_id INTEGER REFERENCES usuarios(id) ON DELETE SET NULL,
+    motivo VARCHAR(20) NOT NULL CHECK (motivo IN ('criacao', 'base', 'edicao', 'restauracao')),
+    restaurada_de INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (personagem_id, versao)
+);
@@ -0,0 +1,8 @@
+-- Migration: Lixeira de personagens
+-- Excluir um personagem preenche deleted_at em vez de apagar a linha; as consultas do GORM
+-- ignoram personagens excluídos. A limpeza periódica apaga de vez (com as tabelas filhas, em
+-- cascata) os que passaram do prazo da lixeira.
+
+ALTER TABLE personagens ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_personagens_deleted_at ON personagens(deleted_at);
@@ -0,0 +1,12 @@
+-- Migration: Categoria das armas do inventário
+-- Define a perícia do ataque (Luta ou Pontaria) e se a Força soma no dano. Armas existentes
+-- recebem a categoria pelo nome, com a mesma lista de models.CategoriaArmaPorNome.
+
+ALTER TABLE personagem_itens ADD COLUMN IF NOT EXISTS categoria_arma VARCHAR(20) NOT NULL DEFAULT '';
+
+UPDATE personagem_itens SET categoria_arma = CASE
+    WHEN LOWER(nome) SIMILAR TO '%(arco|besta|funda|pistola|mosquete|zarabatana)%' THEN 'disparo'
+    WHEN LOWER(nome) SIMILAR TO '%(azagaia|dardo|shuriken|rede)%' THEN 'arremesso'
+    ELSE 'corpo_a_corpo'
+END
+WHERE tipo = 'arma' AND categoria_arma = '';
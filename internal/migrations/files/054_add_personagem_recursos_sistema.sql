@@ -0,0 +1,9 @@
+-- Migration: Recursos controlados pelo servidor
+-- sistema = TRUE marca usos limitados de poderes mantidos pelo servidor (ex: Frutos do
+-- Trabalho, uma vez por aventura). O jogador não edita, remove nem recupera esses recursos.
+
+ALTER TABLE personagem_recursos ADD COLUMN IF NOT EXISTS sistema BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE personagem_recursos SET sistema = TRUE
+WHERE nome = 'Frutos do Trabalho' AND reinicio = 'aventura' AND maximo = '1' AND item_id IS NULL
+  AND descricao = 'Uso por aventura do poder Frutos do Trabalho';
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Papéis de usuário
+const (
+	PapelJogador = "jogador"
+	PapelAdmin   = "admin"
+)
+
+// Escopos de chaves de API
+const (
+	EscopoCatalogoEscrita  = "catalogo:escrita"
+	EscopoAuditoriaLeitura = "auditoria:leitura"
+)
+
+// EscoposValidos lista os escopos que podem ser concedidos a uma chave de API
+var EscoposValidos = []string{EscopoCatalogoEscrita, EscopoAuditoriaLeitura}
+
+// ApiChave é uma chave de API para automações (ex: importação do catálogo).
+// Apenas o hash SHA-256 da chave é salvo; o prefixo ajuda a identificá-la.
+type ApiChave struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Nome        string     `json:"nome" gorm:"type:varchar(100);not null"`
+	Prefixo     string     `json:"prefixo" gorm:"type:varchar(12);not null"`
+	ChaveHash   string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	Escopos     string     `json:"escopos" gorm:"type:jsonb;not null;default:'[]'"`
+	UsuarioID   *uint      `json:"usuario_id"` // administrador que criou a chave
+	UltimoUsoEm *time.Time `json:"ultimo_uso_em"`
+	RevogadaEm  *time.Time `json:"revogada_em"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (ApiChave) TableName() string {
+	return "api_chaves"
+}
+
+// ListaEscopos retorna os escopos concedidos à chave
+func (k *ApiChave) ListaEscopos() []string {
+	var escopos []string
+	if k.Escopos != "" {
+		json.Unmarshal([]byte(k.Escopos), &escopos)
+	}
+	return escopos
+}
+
+// AuditoriaCatalogo registra uma tentativa de escrita no catálogo
+type AuditoriaCatalogo struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UsuarioID  *uint     `json:"usuario_id"`
+	ApiChaveID *uint     `json:"api_chave_id"`
+	Metodo     string    `json:"metodo" gorm:"type:varchar(10);not null"`
+	Rota       string    `json:"rota" gorm:"type:varchar(200);not null"`
+	RecursoID  string    `json:"recurso_id" gorm:"type:varchar(20);default:''"`
+	Status     int       `json:"status" gorm:"not null"`
+	Dados      string    `json:"dados" gorm:"type:text;default:''"` // corpo da requisição
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (AuditoriaCatalogo) TableName() string {
+	return "auditoria_catalogo"
+}
@@ -0,0 +1,65 @@
+package models
+
+import "strings"
+
+// Categorias de arma: definem a perícia do ataque e se a Força soma no dano
+const (
+	CategoriaArmaCorpoACorpo = "corpo_a_corpo" // Luta, soma Força no dano
+	CategoriaArmaArremesso   = "arremesso"     // Pontaria, soma Força no dano
+	CategoriaArmaDisparo     = "disparo"       // Pontaria, sem Força no dano
+)
+
+// Trechos do nome que identificam armas de ataque à distância, para itens que chegam sem
+// categoria (kits, itens antigos). A migração 053 usa a mesma lista.
+var (
+	nomesArmaDisparo   = []string{"arco", "besta", "funda", "pistola", "mosquete", "zarabatana"}
+	nomesArmaArremesso = []string{"azagaia", "dardo", "shuriken", "rede"}
+)
+
+// CategoriaArmaPorNome deduz a categoria de uma arma pelo nome; na dúvida, corpo a corpo
+func CategoriaArmaPorNome(nome string) string {
+	nome = strings.ToLower(nome)
+	for _, trecho := range nomesArmaDisparo {
+		if strings.Contains(nome, trecho) {
+			return CategoriaArmaDisparo
+		}
+	}
+	for _, trecho := range nomesArmaArremesso {
+		if strings.Contains(nome, trecho) {
+			return CategoriaArmaArremesso
+		}
+	}
+	return CategoriaArmaCorpoACorpo
+}
+
+// CategoriaArmaValida indica se a categoria é conhecida
+func CategoriaArmaValida(categoria string) bool {
+	switch categoria {
+	case CategoriaArmaCorpoACorpo, CategoriaArmaArremesso, CategoriaArmaDisparo:
+		return true
+	}
+	return false
+}
+
+// NormalizarCategoriaArma limpa a categoria de itens que não são armas e deduz pelo nome a
+// das armas que chegam sem categoria válida
+func (i *PersonagemItem) NormalizarCategoriaArma() {
+	if i.Tipo != "arma" {
+		i.CategoriaArma = ""
+	} else if !CategoriaArmaValida(i.CategoriaArma) {
+		i.CategoriaArma = CategoriaArmaPorNome(i.Nome)
+	}
+}
+
+// PericiaAtaque retorna a perícia usada nos ataques com a arma
+func (i *PersonagemItem) PericiaAtaque() string {
+	if i.CategoriaArma == CategoriaArmaArremesso || i.CategoriaArma == CategoriaArmaDisparo {
+		return "Pontaria"
+	}
+	return "Luta"
+}
+
+// SomaForcaNoDano indica se a Força entra no dano: armas de disparo não somam
+func (i *PersonagemItem) SomaForcaNoDano() bool {
+	return i.CategoriaArma != CategoriaArmaDisparo
+}
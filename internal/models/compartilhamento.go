@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Campos privados que o dono pode omitir de uma ficha compartilhada
+const (
+	CampoAnotacoes = "anotacoes"
+	CampoHistorico = "historico"
+	CampoDinheiro  = "dinheiro"
+)
+
+// CamposOcultaveis lista os campos aceitos em PersonagemCompartilhamento.Ocultar
+var CamposOcultaveis = []string{CampoAnotacoes, CampoHistorico, CampoDinheiro}
+
+// PersonagemCompartilhamento é um link público de leitura para um personagem.
+// Apenas o hash SHA-256 do token é salvo; o prefixo ajuda o dono a identificar o link.
+type PersonagemCompartilhamento struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	PersonagemID   uint       `json:"personagem_id" gorm:"not null;index"`
+	TokenHash      string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	Prefixo        string     `json:"prefixo" gorm:"type:varchar(12);not null"`
+	Ocultar        string     `json:"ocultar" gorm:"type:jsonb;not null;default:'[]'"`
+	ExpiraEm       *time.Time `json:"expira_em"` // nil = não expira
+	RevogadoEm     *time.Time `json:"revogado_em"`
+	Acessos        int        `json:"acessos" gorm:"not null;default:0"`
+	UltimoAcessoEm *time.Time `json:"ultimo_acesso_em"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (PersonagemCompartilhamento) TableName() string {
+	return "personagem_compartilhamentos"
+}
+
+// CamposOcultos retorna os campos omitidos na ficha compartilhada
+func (s *PersonagemCompartilhamento) CamposOcultos() []string {
+	var campos []string
+	if s.Ocultar != "" {
+		json.Unmarshal([]byte(s.Ocultar), &campos)
+	}
+	return campos
+}
+
+// Ativo indica se o link ainda dá acesso à ficha
+func (s *PersonagemCompartilhamento) Ativo(agora time.Time) bool {
+	return s.RevogadoEm == nil && (s.ExpiraEm == nil || s.ExpiraEm.After(agora))
+}
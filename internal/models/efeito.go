@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// Alvos de um efeito ativo
+const (
+	AlvoEfeitoDefesa  = "defesa"
+	AlvoEfeitoAtaque  = "ataque"
+	AlvoEfeitoDano    = "dano"
+	AlvoEfeitoPericia = "pericia"
+	AlvoEfeitoPV      = "pv"
+	AlvoEfeitoPM      = "pm"
+)
+
+// Unidades de duração de um efeito, da menor para a maior
+const (
+	UnidadeRodada = "rodada"
+	UnidadeCena   = "cena"
+	UnidadeDia    = "dia"
+)
+
+// UnidadesEncerradas retorna as unidades de duração que terminam por completo quando o
+// tempo avança na unidade informada: o fim da cena encerra efeitos medidos em rodadas e
+// um novo dia encerra também os de cena
+func UnidadesEncerradas(unidade string) []string {
+	switch unidade {
+	case UnidadeCena:
+		return []string{UnidadeRodada}
+	case UnidadeDia:
+		return []string{UnidadeRodada, UnidadeCena}
+	}
+	return nil
+}
+
+// PersonagemEfeito é um bônus ou penalidade temporário ("+2 na Defesa até o fim da cena")
+type PersonagemEfeito struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	PersonagemID uint      `json:"personagem_id" gorm:"not null;index"`
+	Nome         string    `json:"nome" gorm:"type:varchar(100);not null"`
+	Fonte        string    `json:"fonte" gorm:"type:varchar(100);default:''"` // magia ou habilidade que gerou o efeito
+	Alvo         string    `json:"alvo" gorm:"type:varchar(20);not null"`
+	Detalhe      string    `json:"detalhe" gorm:"type:varchar(100);default:''"` // perícia afetada; vazio = todas
+	Modificador  int       `json:"modificador" gorm:"not null"`
+	Unidade      string    `json:"unidade" gorm:"type:varchar(20);not null"`
+	Restante     int       `json:"restante" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (PersonagemEfeito) TableName() string {
+	return "personagem_efeitos"
+}
+
+// BonusEfeitos soma os modificadores dos efeitos ativos por alvo
+type BonusEfeitos struct {
+	Defesa        int            `json:"defesa"`
+	Ataque        int            `json:"ataque"`
+	Dano          int            `json:"dano"`
+	PV            int            `json:"pv"`
+	PM            int            `json:"pm"`
+	TodasPericias int            `json:"todas_pericias"`
+	Pericias      map[string]int `json:"pericias"`
+}
+
+// CalcularBonusEfeitos soma os efeitos que ainda não expiraram
+func CalcularBonusEfeitos(efeitos []PersonagemEfeito) BonusEfeitos {
+	bonus := BonusEfeitos{Pericias: make(map[string]int)}
+	for i := range efeitos {
+		e := &efeitos[i]
+		if e.Restante <= 0 {
+			continue
+		}
+		switch e.Alvo {
+		case AlvoEfeitoDefesa:
+			bonus.Defesa += e.Modificador
+		case AlvoEfeitoAtaque:
+			bonus.Ataque += e.Modificador
+		case AlvoEfeitoDano:
+			bonus.Dano += e.Modificador
+		case AlvoEfeitoPV:
+			bonus.PV += e.Modificador
+		case AlvoEfeitoPM:
+			bonus.PM += e.Modificador
+		case AlvoEfeitoPericia:
+			if e.Detalhe == "" {
+				bonus.TodasPericias += e.Modificador
+			} else {
+				bonus.Pericias[e.Detalhe] += e.Modificador
+			}
+		}
+	}
+	return bonus
+}
+
+// Pericia retorna o modificador dos efeitos em uma perícia
+func (b BonusEfeitos) Pericia(nome string) int {
+	return b.TodasPericias + b.Pericias[nome]
+}
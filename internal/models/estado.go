@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Estados da ficha no fluxo de aprovação da mesa
+const (
+	EstadoRascunho  = "rascunho"
+	EstadoEnviado   = "enviado"
+	EstadoAprovado  = "aprovado"
+	EstadoBloqueado = "bloqueado"
+)
+
+// Quem realizou uma transição de estado
+const (
+	AutorDono    = "dono"
+	AutorMestre  = "mestre"
+	AutorSistema = "sistema" // ex: personagem saiu da mesa
+)
+
+// FichaTravada indica se a ficha não aceita edições: aguardando aprovação, aprovada ou
+// bloqueada pelo mestre. Só rascunhos são editáveis; fluxos de jogo (recursos, dinheiro, XP,
+// subir de nível) continuam liberados.
+func (p *Personagem) FichaTravada() bool {
+	return p.Estado != EstadoRascunho
+}
+
+// PersonagemEstado registra uma transição de estado da ficha
+type PersonagemEstado struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	PersonagemID uint      `json:"personagem_id" gorm:"not null;index"`
+	De           string    `json:"de" gorm:"type:varchar(20);not null"`
+	Para         string    `json:"para" gorm:"type:varchar(20);not null"`
+	UsuarioID    *uint     `json:"usuario_id"`
+	Papel        string    `json:"papel" gorm:"type:varchar(20);not null"`
+	Comentario   string    `json:"comentario" gorm:"type:text;default:''"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (PersonagemEstado) TableName() string {
+	return "personagem_estados"
+}
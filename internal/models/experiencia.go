@@ -0,0 +1,64 @@
+package models
+
+// Patamares de jogo do Tormenta 20
+const (
+	PatamarIniciante = "iniciante"
+	PatamarVeterano  = "veterano"
+	PatamarCampeao   = "campeão"
+	PatamarLenda     = "lenda"
+)
+
+// NivelMaximo é o nível mais alto que um personagem pode atingir
+const NivelMaximo = 20
+
+// XPParaNivel retorna a experiência total necessária para alcançar um nível.
+// Tabela do T20: cada nível exige 1.000 XP vezes o nível anterior a mais que o último
+// (2º = 1.000, 3º = 3.000, 4º = 6.000 ... 20º = 190.000).
+func XPParaNivel(nivel int) int {
+	if nivel <= 1 {
+		return 0
+	}
+	if nivel > NivelMaximo {
+		nivel = NivelMaximo
+	}
+	return 1000 * nivel * (nivel - 1) / 2
+}
+
+// NivelPorXP retorna o nível correspondente a um total de experiência
+func NivelPorXP(xp int) int {
+	nivel := 1
+	for nivel < NivelMaximo && xp >= XPParaNivel(nivel+1) {
+		nivel++
+	}
+	return nivel
+}
+
+// PatamarPorNivel retorna o patamar de jogo de um nível.
+// Iniciante: 1-4, Veterano: 5-10, Campeão: 11-16, Lenda: 17-20.
+func PatamarPorNivel(nivel int) string {
+	switch {
+	case nivel >= 17:
+		return PatamarLenda
+	case nivel >= 11:
+		return PatamarCampeao
+	case nivel >= 5:
+		return PatamarVeterano
+	default:
+		return PatamarIniciante
+	}
+}
+
+// IndicePatamar retorna a posição do patamar (iniciante = 1 ... lenda = 4).
+// Útil para efeitos que escalam "a cada patamar", como Frutos do Trabalho e Coração Heroico.
+func IndicePatamar(nivel int) int {
+	switch PatamarPorNivel(nivel) {
+	case PatamarLenda:
+		return 4
+	case PatamarCampeao:
+		return 3
+	case PatamarVeterano:
+		return 2
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Situação de uma fabricação
+const (
+	FabricacaoEmAndamento = "em_andamento"
+	FabricacaoConcluida   = "concluida"
+	FabricacaoFalhou      = "falhou"
+)
+
+// PersonagemFabricacao acompanha a fabricação de um item do catálogo com a perícia Ofício.
+// A matéria-prima é paga no início; o teste é feito quando os dias de trabalho se completam.
+type PersonagemFabricacao struct {
+	ID                uint          `json:"id" gorm:"primaryKey"`
+	PersonagemID      uint          `json:"personagem_id" gorm:"not null;index"`
+	ItemCatalogoID    uint          `json:"item_catalogo_id" gorm:"not null"`
+	ItemCatalogo      *ItemCatalogo `json:"item_catalogo,omitempty" gorm:"foreignKey:ItemCatalogoID"`
+	Nome              string        `json:"nome" gorm:"type:varchar(200);not null"`
+	Quantidade        int           `json:"quantidade" gorm:"not null;default:1"`
+	ParaCinco         bool          `json:"para_cinco" gorm:"column:para_cinco;default:false"` // prato para cinco pessoas
+	CustoMateriaPrima float64       `json:"custo_materia_prima" gorm:"column:custo_materia_prima;type:decimal(10,2);not null"`
+	CD                int           `json:"cd" gorm:"column:cd;not null"`
+	Penalidade        int           `json:"penalidade" gorm:"not null;default:0"`
+	DiasNecessarios   int           `json:"dias_necessarios" gorm:"column:dias_necessarios;not null"`
+	DiasTrabalhados   int           `json:"dias_trabalhados" gorm:"column:dias_trabalhados;not null;default:0"`
+	Status            string        `json:"status" gorm:"type:varchar(20);not null;default:'em_andamento'"`
+	UltimaRolagem     *int          `json:"ultima_rolagem" gorm:"column:ultima_rolagem"`
+	UltimoResultado   *int          `json:"ultimo_resultado" gorm:"column:ultimo_resultado"`
+	ItemID            *uint         `json:"item_id" gorm:"column:item_id"` // item gerado no inventário
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+}
+
+func (PersonagemFabricacao) TableName() string {
+	return "personagem_fabricacoes"
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+// Categorias do catálogo de equipamento geral
+const (
+	CategoriaItemGeral       = "geral"
+	CategoriaItemAlquimico   = "alquimico"
+	CategoriaItemVestuario   = "vestuario"
+	CategoriaItemFerramenta  = "ferramenta"
+	CategoriaItemAlimentacao = "alimentacao"
+	CategoriaItemPocao       = "pocao"
+	CategoriaItemMunicao     = "municao"
+)
+
+// Efeitos aplicados ao usar um item consumível
+const (
+	EfeitoCuraPV = "cura_pv"
+	EfeitoCuraPM = "cura_pm"
+)
+
+// EfeitoItem descreve o efeito de um consumível, com os dados rolados (ex.: "2d8+2")
+type EfeitoItem struct {
+	Tipo  string `json:"tipo"`
+	Dados string `json:"dados"`
+}
+
+// ItemCatalogo representa um item do catálogo de equipamento (itens gerais, alquímicos,
+// vestuário, ferramentas, alimentação, poções e munição), com preço em T$ e espaços ocupados
+type ItemCatalogo struct {
+	gorm.Model
+	Nome      string  `json:"nome" gorm:"not null;unique" validate:"required,min=2,max=200"`
+	Categoria string  `json:"categoria" gorm:"not null;index" validate:"required,oneof=geral alquimico vestuario ferramenta alimentacao pocao municao"`
+	Preco     float64 `json:"preco" gorm:"type:decimal(10,2);default:0" validate:"min=0"`
+	Espacos   float64 `json:"espacos" gorm:"type:decimal(8,2);default:1" validate:"min=0"`
+	Descricao string  `json:"descricao" gorm:"type:text;default:''"`
+
+	// Espaços que o item comporta quando usado como container (mochilas)
+	Capacidade float64 `json:"capacidade" gorm:"type:decimal(8,2);default:0" validate:"min=0"`
+
+	// Efeito ao usar o item (JSON): {"tipo": "cura_pv", "dados": "2d8+2"}
+	Efeito string `json:"efeito" gorm:"type:jsonb;not null;default:'{}'"`
+}
+
+func (ItemCatalogo) TableName() string {
+	return "itens_catalogo"
+}
+
+// EfeitoDefinido retorna o efeito do item, ou nil se ele não tiver efeito ao ser usado
+func (i *ItemCatalogo) EfeitoDefinido() (*EfeitoItem, error) {
+	if i.Efeito == "" {
+		return nil, nil
+	}
+	var efeito EfeitoItem
+	if err := json.Unmarshal([]byte(i.Efeito), &efeito); err != nil {
+		return nil, err
+	}
+	if efeito.Tipo == "" {
+		return nil, nil
+	}
+	return &efeito, nil
+}
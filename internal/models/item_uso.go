@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// PersonagemItemUso registra cada uso de um item consumível do inventário
+type PersonagemItemUso struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	PersonagemID uint      `json:"personagem_id" gorm:"not null;index"`
+	ItemID       *uint     `json:"item_id" gorm:"column:item_id"` // nil quando o item acabou
+	Nome         string    `json:"nome" gorm:"type:varchar(200);not null"`
+	Quantidade   int       `json:"quantidade" gorm:"not null;default:1"`
+	Efeito       string    `json:"efeito" gorm:"type:varchar(50);default:''"`
+	Resultado    *int      `json:"resultado"` // total rolado pelo efeito, se houver
+	Detalhes     string    `json:"detalhes" gorm:"type:text;default:''"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (PersonagemItemUso) TableName() string {
+	return "personagem_item_usos"
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Limites de modificações por item
+const (
+	MaxMelhoriasPorItem = 4
+	MaxEncantosPorItem  = 3
+)
+
+// Custo adicional de um item superior pelo número de melhorias (1 a 4)
+var precoMelhorias = []float64{0, 300, 3000, 9000, 18000}
+
+// Custo adicional de um item mágico pelo número de encantos (menor, médio e maior)
+var precoEncantos = []float64{0, 30000, 90000, 180000}
+
+// Melhoria representa uma melhoria de item superior (certeira, pungente, ajustada, reforçada...)
+type Melhoria struct {
+	gorm.Model
+	Nome              string `json:"nome" gorm:"not null;unique" validate:"required,min=2,max=100"`
+	Descricao         string `json:"descricao"`
+	AplicaEm          string `json:"aplica_em" validate:"required"` // tipos de item separados por vírgula: arma, armadura, escudo, esoterico, ferramenta, vestuario
+	PreRequisito      string `json:"pre_requisito"`                 // nome de outra melhoria exigida no mesmo item
+	BonusAtaque       int    `json:"bonus_ataque"`
+	BonusDano         int    `json:"bonus_dano"`
+	BonusDefesa       int    `json:"bonus_defesa"`
+	MargemAmeaca      int    `json:"margem_ameaca"`
+	ReducaoPenalidade int    `json:"reducao_penalidade"` // redução na penalidade de armadura
+}
+
+func (Melhoria) TableName() string {
+	return "melhorias"
+}
+
+// Encanto representa um encanto mágico aplicado sobre um item
+type Encanto struct {
+	gorm.Model
+	Nome         string `json:"nome" gorm:"not null;unique" validate:"required,min=2,max=100"`
+	Descricao    string `json:"descricao"`
+	AplicaEm     string `json:"aplica_em" validate:"required"`
+	PreRequisito string `json:"pre_requisito"`
+	BonusAtaque  int    `json:"bonus_ataque"`
+	BonusDano    int    `json:"bonus_dano"`
+	BonusDefesa  int    `json:"bonus_defesa"`
+}
+
+func (Encanto) TableName() string {
+	return "encantos"
+}
+
+// PersonagemItemMelhoria relaciona um item do inventário às suas melhorias
+type PersonagemItemMelhoria struct {
+	PersonagemItemID uint `json:"personagem_item_id" gorm:"primaryKey"`
+	MelhoriaID       uint `json:"melhoria_id" gorm:"primaryKey"`
+}
+
+func (PersonagemItemMelhoria) TableName() string {
+	return "personagem_item_melhorias"
+}
+
+// PersonagemItemEncanto relaciona um item do inventário aos seus encantos
+type PersonagemItemEncanto struct {
+	PersonagemItemID uint `json:"personagem_item_id" gorm:"primaryKey"`
+	EncantoID        uint `json:"encanto_id" gorm:"primaryKey"`
+}
+
+func (PersonagemItemEncanto) TableName() string {
+	return "personagem_item_encantos"
+}
+
+// aplicavelA verifica se uma lista de tipos ("arma,escudo") inclui o tipo do item
+func aplicavelA(aplicaEm, tipo string) bool {
+	for _, t := range strings.Split(aplicaEm, ",") {
+		if strings.TrimSpace(t) == tipo {
+			return true
+		}
+	}
+	return false
+}
+
+// AplicavelA verifica se a melhoria pode ser aplicada a um tipo de item
+func (m Melhoria) AplicavelA(tipo string) bool {
+	return aplicavelA(m.AplicaEm, tipo)
+}
+
+// AplicavelA verifica se o encanto pode ser aplicado a um tipo de item
+func (e Encanto) AplicavelA(tipo string) bool {
+	return aplicavelA(e.AplicaEm, tipo)
+}
+
+// PrecoAdicionalMelhorias retorna o acréscimo de preço de um item com n melhorias
+func PrecoAdicionalMelhorias(n int) float64 {
+	if n <= 0 {
+		return 0
+	}
+	if n >= len(precoMelhorias) {
+		n = len(precoMelhorias) - 1
+	}
+	return precoMelhorias[n]
+}
+
+// PrecoAdicionalEncantos retorna o acréscimo de preço de um item com n encantos
+func PrecoAdicionalEncantos(n int) float64 {
+	if n <= 0 {
+		return 0
+	}
+	if n >= len(precoEncantos) {
+		n = len(precoEncantos) - 1
+	}
+	return precoEncantos[n]
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// Papéis dos membros de uma mesa
+const (
+	PapelMesaMestre  = "mestre"
+	PapelMesaJogador = "jogador"
+)
+
+// Mesa é uma campanha: o mestre convida jogadores, que vinculam seus personagens a ela
+type Mesa struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	Nome          string       `json:"nome" gorm:"type:varchar(100);not null"`
+	Descricao     string       `json:"descricao" gorm:"type:text;default:''"`
+	CodigoConvite string       `json:"codigo_convite,omitempty" gorm:"type:varchar(20);not null;uniqueIndex"` // visível só para o mestre
+	MestreEdita   bool         `json:"mestre_edita" gorm:"not null;default:false"`                            // o mestre pode alterar as fichas
+	Membros       []MesaMembro `json:"membros,omitempty" gorm:"foreignKey:MesaID"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+func (Mesa) TableName() string {
+	return "mesas"
+}
+
+// MesaMembro é a participação de um usuário em uma mesa
+type MesaMembro struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	MesaID    uint      `json:"mesa_id" gorm:"not null;index"`
+	UsuarioID uint      `json:"usuario_id" gorm:"not null"`
+	Papel     string    `json:"papel" gorm:"type:varchar(20);not null;default:'jogador'"`
+	Nome      string    `json:"nome" gorm:"->;-:migration"` // nome do usuário, carregado por join
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (MesaMembro) TableName() string {
+	return "mesa_membros"
+}
+
+// Modos de PV por nível nas regras da mesa
+const (
+	PVFixo   = "fixo"
+	PVRolado = "rolado"
+)
+
+// PontosAtributosPadrao é o orçamento de point-buy do Livro Básico
+const PontosAtributosPadrao = 10
+
+// MesaRegras são as regras da casa de uma mesa. Listas JSON vazias não restringem nada e
+// DinheiroInicial nil mantém o dinheiro inicial da classe.
+type MesaRegras struct {
+	MesaID           uint      `json:"mesa_id" gorm:"primaryKey;autoIncrement:false"`
+	PontosAtributos  int       `json:"pontos_atributos" gorm:"not null;default:10"`
+	LivrosPermitidos string    `json:"livros_permitidos" gorm:"type:jsonb;not null;default:'[]'"`
+	RacasBanidas     string    `json:"racas_banidas" gorm:"type:jsonb;not null;default:'[]'"`
+	ClassesBanidas   string    `json:"classes_banidas" gorm:"type:jsonb;not null;default:'[]'"`
+	PVPorNivel       string    `json:"pv_por_nivel" gorm:"column:pv_por_nivel;type:varchar(10);not null;default:'fixo'"`
+	NivelInicial     int       `json:"nivel_inicial" gorm:"not null;default:1"`
+	DinheiroInicial  *float64  `json:"dinheiro_inicial" gorm:"type:decimal(10,2)"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (MesaRegras) TableName() string {
+	return "mesa_regras"
+}
+
+// RegrasPadrao retorna as regras do Livro Básico, usadas fora de mesas ou quando a mesa
+// não configurou regras próprias
+func RegrasPadrao() MesaRegras {
+	return MesaRegras{
+		PontosAtributos:  PontosAtributosPadrao,
+		LivrosPermitidos: "[]",
+		RacasBanidas:     "[]",
+		ClassesBanidas:   "[]",
+		PVPorNivel:       PVFixo,
+		NivelInicial:     1,
+	}
+}
+
+// Livros retorna os livros permitidos (vazio = todos)
+func (r *MesaRegras) Livros() []string {
+	var livros []string
+	if r.LivrosPermitidos != "" {
+		json.Unmarshal([]byte(r.LivrosPermitidos), &livros)
+	}
+	return livros
+}
+
+// LivroPermitido indica se conteúdo do livro pode ser usado na mesa
+func (r *MesaRegras) LivroPermitido(livro string) bool {
+	livros := r.Livros()
+	return len(livros) == 0 || slices.Contains(livros, livro)
+}
+
+// RacaBanida indica se a raça foi proibida na mesa
+func (r *MesaRegras) RacaBanida(racaID uint) bool {
+	return slices.Contains(listaIDs(r.RacasBanidas), racaID)
+}
+
+// ClasseBanida indica se a classe foi proibida na mesa
+func (r *MesaRegras) ClasseBanida(classeID uint) bool {
+	return slices.Contains(listaIDs(r.ClassesBanidas), classeID)
+}
+
+func listaIDs(valor string) []uint {
+	var ids []uint
+	if valor != "" {
+		json.Unmarshal([]byte(valor), &ids)
+	}
+	return ids
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Patamares de parceiro (diferentes dos patamares de jogo do personagem)
+const (
+	PatamarParceiroIniciante = "iniciante"
+	PatamarParceiroVeterano  = "veterano"
+	PatamarParceiroMestre    = "mestre"
+)
+
+// ParceiroTipo é o bônus padrão de um tipo de parceiro em um patamar.
+// Pericias nil indica que o jogador escolhe as perícias beneficiadas (ajudante).
+type ParceiroTipo struct {
+	ID           uint    `json:"id" gorm:"primaryKey"`
+	Tipo         string  `json:"tipo" gorm:"type:varchar(30);not null"`
+	Patamar      string  `json:"patamar" gorm:"type:varchar(20);not null"`
+	BonusAtaque  int     `json:"bonus_ataque"`
+	BonusDano    int     `json:"bonus_dano"`
+	BonusDefesa  int     `json:"bonus_defesa"`
+	BonusPericia int     `json:"bonus_pericia"`
+	Pericias     *string `json:"pericias" gorm:"type:jsonb"`
+	Descricao    string  `json:"descricao"`
+}
+
+func (ParceiroTipo) TableName() string {
+	return "parceiro_tipos"
+}
+
+// PersonagemParceiro é um parceiro (ajudante, montaria, guardião...) de um personagem,
+// concedido por poderes como Amigo Especial e Antigo Mestre ou por habilidades de classe
+type PersonagemParceiro struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	PersonagemID uint      `json:"personagem_id" gorm:"not null;index"`
+	Nome         string    `json:"nome" gorm:"type:varchar(100);not null"`
+	Tipo         string    `json:"tipo" gorm:"type:varchar(30);not null"`
+	Patamar      string    `json:"patamar" gorm:"type:varchar(20);not null;default:'iniciante'"`
+	Fonte        string    `json:"fonte" gorm:"type:varchar(100);default:''"` // poder ou habilidade que concedeu o parceiro
+	Ativo        bool      `json:"ativo" gorm:"not null;default:true"`
+	BonusAtaque  int       `json:"bonus_ataque"`
+	BonusDano    int       `json:"bonus_dano"`
+	BonusDefesa  int       `json:"bonus_defesa"`
+	BonusPericia int       `json:"bonus_pericia"`
+	Pericias     string    `json:"pericias" gorm:"type:jsonb;default:'[]'"` // nomes das perícias que recebem BonusPericia
+	Descricao    string    `json:"descricao" gorm:"type:text;default:''"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (PersonagemParceiro) TableName() string {
+	return "personagem_parceiros"
+}
+
+// ListaPericias retorna as perícias que recebem o bônus do parceiro
+func (p *PersonagemParceiro) ListaPericias() []string {
+	var pericias []string
+	if p.Pericias != "" {
+		json.Unmarshal([]byte(p.Pericias), &pericias)
+	}
+	return pericias
+}
+
+// BonusParceiros reúne os bônus dos parceiros ativos. Bônus do mesmo tipo não se
+// acumulam: vale o maior entre os parceiros.
+type BonusParceiros struct {
+	Ataque   int            `json:"ataque"`
+	Dano     int            `json:"dano"`
+	Defesa   int            `json:"defesa"`
+	Pericias map[string]int `json:"pericias"`
+}
+
+// CalcularBonusParceiros retorna o maior bônus de cada tipo entre os parceiros ativos
+func CalcularBonusParceiros(parceiros []PersonagemParceiro) BonusParceiros {
+	bonus := BonusParceiros{Pericias: make(map[string]int)}
+	for i := range parceiros {
+		p := &parceiros[i]
+		if !p.Ativo {
+			continue
+		}
+		bonus.Ataque = max(bonus.Ataque, p.BonusAtaque)
+		bonus.Dano = max(bonus.Dano, p.BonusDano)
+		bonus.Defesa = max(bonus.Defesa, p.BonusDefesa)
+		for _, pericia := range p.ListaPericias() {
+			bonus.Pericias[pericia] = max(bonus.Pericias[pericia], p.BonusPericia)
+		}
+	}
+	return bonus
+}
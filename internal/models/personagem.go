@@ -12,6 +12,9 @@ type Personagem struct {
 	Nome  string `json:"nome" validate:"required,min=2"`
 	Nivel int    `json:"nivel" validate:"min=1,max=20"`
 
+	// Experiência acumulada (tabela de XP do T20)
+	Experiencia int `json:"experiencia" gorm:"column:experiencia;default:0" validate:"min=0"`
+
 	// Atributos base (point-buy do Tormenta 20: -1 a 4, mais bonus raciais)
 	For int `json:"for" gorm:"column:for" validate:"min=-1,max=10"`
 	Des int `json:"des" gorm:"column:des" validate:"min=-1,max=10"`
@@ -30,6 +33,10 @@ type Personagem struct {
 	DivindadeID *uint      `json:"divindade_id" gorm:"default:null"`
 	Divindade   *Divindade `json:"divindade" gorm:"foreignKey:DivindadeID"`
 
+	// Caminho escolhido na classe (ex.: Bruxo, Feiticeiro ou Mago do Arcanista)
+	CaminhoID *uint          `json:"caminho_id" gorm:"column:caminho_id"`
+	Caminho   *ClasseCaminho `json:"caminho,omitempty" gorm:"foreignKey:CaminhoID"`
+
 	// Perícias do personagem
 	Pericias []Pericia `json:"pericias" gorm:"many2many:personagem_pericias;"`
 
@@ -45,18 +52,81 @@ type Personagem struct {
 	Anotacoes string           `json:"anotacoes" gorm:"column:anotacoes;type:text;default:''"`
 	Historico string           `json:"historico" gorm:"column:historico;type:text;default:''"`
 
+	// Parceiros (ajudantes, montarias...) concedidos por poderes e habilidades
+	Parceiros []PersonagemParceiro `json:"parceiros" gorm:"foreignKey:PersonagemID"`
+
+	// Efeitos ativos (bônus e penalidades temporários), removidos ao expirar
+	Efeitos []PersonagemEfeito `json:"efeitos" gorm:"foreignKey:PersonagemID"`
+
+	// Conta dona do personagem; nil para personagens anônimos (sessão/IP)
+	UsuarioID *uint `json:"usuario_id" gorm:"column:usuario_id;index"`
+
+	// Mesa (campanha) à qual o personagem está vinculado
+	MesaID *uint `json:"mesa_id" gorm:"column:mesa_id"`
+
+	// Estado no fluxo de aprovação da mesa (rascunho, enviado, aprovado, bloqueado)
+	Estado string `json:"estado" gorm:"column:estado;type:varchar(20);default:'rascunho'"`
+
+	// PV rolados a partir do 2º nível, para mesas que rolam PV (JSON: [4, 7, ...])
+	PVRolagens string `json:"pv_rolagens" gorm:"column:pv_rolagens;type:jsonb;default:'[]'"`
+
 	// Identificação do usuário/sessão
 	UserSessionID *string `json:"user_session_id" gorm:"column:user_session_id;type:varchar(36)"`
-	UserIP        *string `json:"user_ip" gorm:"column:user_ip;type:inet"`
+	UserIPHash    *string `json:"-" gorm:"column:user_ip_hash;type:char(64)"` // SHA-256 do IP com salt, só contra abusos
 	CreatedByType string  `json:"created_by_type" gorm:"column:created_by_type;default:'session'"`
 
+	// PV e PM atuais (nil = cheios)
+	PVAtual *int `json:"pv_atual" gorm:"column:pv_atual"`
+	PMAtual *int `json:"pm_atual" gorm:"column:pm_atual"`
+
 	// Stats calculados (não salvos no DB)
 	PVTotal int `json:"pv_total" gorm:"-"`
 	PMTotal int `json:"pm_total" gorm:"-"`
 	Defesa  int `json:"defesa" gorm:"-"`
 
+	// Carga em espaços: itens guardados em containers contam só na capacidade do container
+	CargaUsada  float64 `json:"carga_usada" gorm:"-"`
+	CargaMaxima int     `json:"carga_maxima" gorm:"-"`
+
+	// Ataques com as armas equipadas, incluindo melhorias e encantos (não salvos no DB)
+	Ataques []Ataque `json:"ataques" gorm:"-"`
+
+	// Progressão calculada a partir de Nivel/Experiencia (não salvos no DB)
+	Patamar        string `json:"patamar" gorm:"-"`
+	XPProximoNivel int    `json:"xp_proximo_nivel" gorm:"-"`
+	PodeSubirNivel bool   `json:"pode_subir_nivel" gorm:"-"`
+
+	// Magias: atributo-chave da classe ou do caminho e CD das magias (não salvos no DB)
+	AtributoChave string `json:"atributo_chave" gorm:"-"`
+	CDMagia       int    `json:"cd_magia" gorm:"-"`
+
+	// Poderes da Tormenta e perda de Carisma (não salvos no DB)
+	Tormenta CorrupcaoTormenta `json:"tormenta" gorm:"-"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Exclusão lógica: personagens excluídos ficam na lixeira até a limpeza periódica
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Fontes dos itens do inventário
+const (
+	FonteItemManual     = "manual"
+	FonteItemOrigem     = "origem"
+	FonteItemClasse     = "classe"
+	FonteItemLoja       = "loja"
+	FonteItemFabricacao = "fabricacao"
+	FonteItemTesouro    = "tesouro"
+)
+
+// tiposConsumiveis são os tipos de item gastos ao serem usados
+var tiposConsumiveis = map[string]bool{
+	"consumivel":  true,
+	"alquimico":   true,
+	"pocao":       true,
+	"municao":     true,
+	"alimentacao": true,
 }
 
 // PersonagemItem representa um item no inventário do personagem
@@ -69,6 +139,73 @@ type PersonagemItem struct {
 	Peso         float64 `json:"peso" gorm:"type:decimal(8,2);default:0"`
 	Valor        float64 `json:"valor" gorm:"type:decimal(10,2);default:0"` // em T$
 	Descricao    string  `json:"descricao" gorm:"type:text;default:''"`
+
+	// Entrada do catálogo de onde o item veio (compras na loja)
+	ItemCatalogoID *uint `json:"item_catalogo_id" gorm:"column:item_catalogo_id"`
+
+	// Fonte do item (manual, origem, loja, fabricacao). Itens de origem guardam a entrada do kit
+	Fonte        string `json:"fonte" gorm:"column:fonte;default:'manual'"`
+	OrigemItemID *uint  `json:"origem_item_id" gorm:"column:origem_item_id"`
+
+	// Posição no inventário e container (mochila) onde o item está guardado
+	Ordem       int     `json:"ordem" gorm:"column:ordem;default:0"`
+	ContainerID *uint   `json:"container_id" gorm:"column:container_id"`
+	Capacidade  float64 `json:"capacidade" gorm:"column:capacidade;type:decimal(8,2);default:0"` // > 0 = container
+
+	// Espaços ocupados pelos itens guardados neste container (não salvo no DB)
+	EspacosUsados float64 `json:"espacos_usados" gorm:"-"`
+
+	// Itens equipados contam para ataque, dano e Defesa
+	Equipado bool `json:"equipado" gorm:"column:equipado;default:false"`
+
+	// Categoria de armas (corpo_a_corpo, arremesso, disparo); vazia para outros itens
+	CategoriaArma string `json:"categoria_arma" gorm:"column:categoria_arma;type:varchar(20);default:''"`
+
+	// Item superior (melhorias) e mágico (encantos)
+	Melhorias []Melhoria `json:"melhorias" gorm:"many2many:personagem_item_melhorias;"`
+	Encantos  []Encanto  `json:"encantos" gorm:"many2many:personagem_item_encantos;"`
+
+	// Calculados a partir das melhorias e encantos (não salvos no DB)
+	PrecoTotal   float64 `json:"preco_total" gorm:"-"`
+	BonusAtaque  int     `json:"bonus_ataque" gorm:"-"`
+	BonusDano    int     `json:"bonus_dano" gorm:"-"`
+	BonusDefesa  int     `json:"bonus_defesa" gorm:"-"`
+	MargemAmeaca int     `json:"margem_ameaca" gorm:"-"`
+}
+
+// CalcularModificacoes preenche preço total e bônus do item a partir das melhorias e
+// encantos carregados. O preço segue a escala do livro: o custo adicional depende da
+// quantidade de melhorias/encantos, não de quais são.
+func (i *PersonagemItem) CalcularModificacoes() {
+	i.PrecoTotal = i.Valor + PrecoAdicionalMelhorias(len(i.Melhorias)) + PrecoAdicionalEncantos(len(i.Encantos))
+	i.BonusAtaque, i.BonusDano, i.BonusDefesa, i.MargemAmeaca = 0, 0, 0, 0
+
+	for _, m := range i.Melhorias {
+		i.BonusAtaque += m.BonusAtaque
+		i.BonusDano += m.BonusDano
+		i.BonusDefesa += m.BonusDefesa
+		i.MargemAmeaca += m.MargemAmeaca
+	}
+	for _, e := range i.Encantos {
+		i.BonusAtaque += e.BonusAtaque
+		i.BonusDano += e.BonusDano
+		i.BonusDefesa += e.BonusDefesa
+	}
+}
+
+// Ataque representa um ataque calculado com uma arma equipada
+type Ataque struct {
+	ItemID       uint   `json:"item_id"`
+	Nome         string `json:"nome"`
+	Pericia      string `json:"pericia"` // Luta ou Pontaria
+	BonusAtaque  int    `json:"bonus_ataque"`
+	BonusDano    int    `json:"bonus_dano"`
+	MargemAmeaca int    `json:"margem_ameaca"`
+}
+
+// Consumivel indica se o item é gasto ao ser usado (poções, alquímicos, munição...)
+func (i *PersonagemItem) Consumivel() bool {
+	return tiposConsumiveis[i.Tipo]
 }
 
 func (PersonagemItem) TableName() string {
@@ -81,7 +218,8 @@ func (Personagem) TableName() string {
 
 type Raca struct {
 	gorm.Model
-	Nome string `json:"nome"`
+	Nome  string `json:"nome"`
+	Livro string `json:"livro" gorm:"type:varchar(50);default:'basico'"` // livro de origem (regras da mesa)
 
 	// Modificadores de atributos (flexível)
 	AtributoBonus1 string `json:"atributo_bonus_1" gorm:"column:atributo_bonus_1"`
@@ -110,6 +248,7 @@ type Raca struct {
 type Classe struct {
 	gorm.Model
 	Nome                 string             `json:"nome"`
+	Livro                string             `json:"livro" gorm:"type:varchar(50);default:'basico'"`
 	PVPrimeiroNivel      int                `json:"pvprimeironivelc" gorm:"column:pv_primeiro_nivel"`
 	PVPorNivel           int                `json:"pvpornivel" gorm:"column:pv_por_nivel"`
 	PMPrimeiroNivel      int                `json:"pmprimeironivelc" gorm:"column:pm_primeiro_nivel"`
@@ -124,6 +263,30 @@ type Classe struct {
 	Habilidades          []HabilidadeClasse `json:"habilidades" gorm:"foreignKey:ClasseID"`
 	PericiasDisponiveis  []Pericia          `json:"pericias_disponiveis" gorm:"many2many:classe_pericias_disponiveis;"`
 	PericiasAutomaticas  []Pericia          `json:"pericias_automaticas" gorm:"many2many:classe_pericias_automaticas;"`
+
+	// Equipamento e dinheiro inicial da classe
+	DinheiroInicial float64         `json:"dinheiro_inicial" gorm:"column:dinheiro_inicial;type:decimal(10,2);default:0"`
+	KitItens        []ClasseKitItem `json:"kit_itens,omitempty" gorm:"foreignKey:ClasseID"`
+
+	// Atributo-chave das magias (vazio para classes não conjuradoras ou que dependem do caminho)
+	AtributoMagia string          `json:"atributo_magia" gorm:"column:atributo_magia;default:''"`
+	Caminhos      []ClasseCaminho `json:"caminhos,omitempty" gorm:"foreignKey:ClasseID"`
+}
+
+// ClasseCaminho é uma escolha de build da classe (caminho do Arcanista, do Cavaleiro...).
+// AtributoChave vazio mantém o atributo de magia da classe.
+type ClasseCaminho struct {
+	ID            uint               `json:"id" gorm:"primaryKey"`
+	ClasseID      uint               `json:"classe_id"`
+	Nome          string             `json:"nome"`
+	Descricao     string             `json:"descricao" gorm:"type:text;default:''"`
+	AtributoChave string             `json:"atributo_chave" gorm:"default:''"`
+	NivelEscolha  int                `json:"nivel_escolha" gorm:"default:1"` // nível em que a escolha é feita
+	Habilidades   []HabilidadeClasse `json:"habilidades,omitempty" gorm:"foreignKey:CaminhoID"`
+}
+
+func (ClasseCaminho) TableName() string {
+	return "classe_caminhos"
 }
 
 type OrigemItem struct {
@@ -133,15 +296,36 @@ type OrigemItem struct {
 	Tipo       string `json:"tipo" gorm:"default:'item'"`
 	Quantidade int    `json:"quantidade" gorm:"default:1"`
 	Descricao  string `json:"descricao" gorm:"type:text;default:''"`
+
+	// Itens "X ou Y (escolha um)": opções fechadas (JSON) ou escolha livre do jogador
+	Opcoes       string `json:"opcoes" gorm:"type:jsonb;default:'[]'"`
+	EscolhaLivre bool   `json:"escolha_livre" gorm:"default:false"`
 }
 
 func (OrigemItem) TableName() string {
 	return "origem_itens"
 }
 
+// ClasseKitItem representa um item do equipamento inicial de uma classe.
+// Grupo 0 é item fixo; grupos maiores são "escolha um" entre os itens do mesmo grupo.
+type ClasseKitItem struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	ClasseID   uint   `json:"classe_id"`
+	Grupo      int    `json:"grupo" gorm:"default:0"`
+	Nome       string `json:"nome"`
+	Tipo       string `json:"tipo" gorm:"default:'item'"`
+	Quantidade int    `json:"quantidade" gorm:"default:1"`
+	Descricao  string `json:"descricao" gorm:"type:text;default:''"`
+}
+
+func (ClasseKitItem) TableName() string {
+	return "classe_kit_itens"
+}
+
 type Origem struct {
 	gorm.Model
 	Nome        string             `json:"nome"`
+	Livro       string             `json:"livro" gorm:"type:varchar(50);default:'basico'"`
 	Descricao   string             `json:"descricao"`
 	Pericias    []Pericia          `json:"pericias" gorm:"many2many:origem_pericias;"`
 	Itens       []OrigemItem       `json:"itens" gorm:"foreignKey:OrigemID"`
@@ -155,6 +339,7 @@ func (Origem) TableName() string {
 type Divindade struct {
 	gorm.Model
 	Nome        string                `json:"nome"`
+	Livro       string                `json:"livro" gorm:"type:varchar(50);default:'basico'"`
 	Descricao   string                `json:"descricao"`
 	Dominio     string                `json:"dominio"`
 	Alinhamento string                `json:"alinhamento"`
@@ -173,6 +358,7 @@ type HabilidadeRaca struct {
 type HabilidadeClasse struct {
 	gorm.Model
 	ClasseID  uint   `json:"classe_id"`
+	CaminhoID *uint  `json:"caminho_id"` // Habilidade exclusiva de um caminho da classe
 	Nome      string `json:"nome"`
 	Descricao string `json:"descricao"`
 	Nivel     int    `json:"nivel"`    // Em que nível a classe ganha esta habilidade
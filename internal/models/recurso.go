@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Gatilhos de reinício de um recurso limitado
+const (
+	ReinicioCena     = "cena"
+	ReinicioDia      = "dia"
+	ReinicioDescanso = "descanso"
+	ReinicioAventura = "aventura"
+)
+
+// GatilhosReiniciados retorna os tipos de recurso que voltam ao máximo com o gatilho.
+// O fim de uma aventura reinicia tudo; uma noite de descanso também encerra a cena e o dia.
+func GatilhosReiniciados(gatilho string) []string {
+	switch gatilho {
+	case ReinicioCena:
+		return []string{ReinicioCena}
+	case ReinicioDia, ReinicioDescanso:
+		return []string{ReinicioCena, ReinicioDia, ReinicioDescanso}
+	case ReinicioAventura:
+		return []string{ReinicioCena, ReinicioDia, ReinicioDescanso, ReinicioAventura}
+	}
+	return nil
+}
+
+// PersonagemRecurso é um recurso limitado do personagem: usos de uma habilidade
+// ("uma vez por cena", "1 + Car vezes por dia") ou cargas de um item
+type PersonagemRecurso struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	PersonagemID uint      `json:"personagem_id" gorm:"not null;index"`
+	Nome         string    `json:"nome" gorm:"type:varchar(100);not null"`
+	Maximo       string    `json:"maximo" gorm:"type:varchar(100);not null;default:'1'"` // fórmula, ex: "1 + CAR"
+	Atual        *int      `json:"atual"`                                                // nil = cheio
+	Reinicio     string    `json:"reinicio" gorm:"type:varchar(20);not null;default:'dia'"`
+	ItemID       *uint     `json:"item_id"`
+	Descricao    string    `json:"descricao" gorm:"type:text;default:''"`
+	Sistema      bool      `json:"sistema" gorm:"not null;default:false"` // mantido pelo servidor
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Calculados a partir da fórmula e dos atributos atuais
+	MaximoValor int `json:"maximo_valor" gorm:"-"`
+	Disponivel  int `json:"disponivel" gorm:"-"`
+}
+
+func (PersonagemRecurso) TableName() string {
+	return "personagem_recursos"
+}
+
+// AplicarMaximo define o máximo calculado e os usos disponíveis; um atual acima
+// do máximo (atributo reduzido depois do gasto) é limitado ao máximo
+func (r *PersonagemRecurso) AplicarMaximo(maximo int) {
+	r.MaximoValor = max(maximo, 0)
+	r.Disponivel = r.MaximoValor
+	if r.Atual != nil && *r.Atual < r.MaximoValor {
+		r.Disponivel = *r.Atual
+	}
+}
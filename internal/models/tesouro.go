@@ -0,0 +1,49 @@
+package models
+
+// Tipos de tesouro rolados por ND
+const (
+	TesouroDinheiro = "dinheiro"
+	TesouroRiqueza  = "riqueza"
+	TesouroItens    = "itens"
+)
+
+// Resultados possíveis de uma linha da tabela de tesouro
+const (
+	ResultadoTesouroNada     = "nada"
+	ResultadoTesouroTibares  = "tibares"
+	ResultadoTesouroRiqueza  = "riqueza"
+	ResultadoTesouroItem     = "item"
+	ResultadoTesouroSuperior = "superior"
+)
+
+// TesouroTabela é uma faixa de d% da tabela de tesouro de um ND
+type TesouroTabela struct {
+	ID            uint   `json:"id" gorm:"primaryKey"`
+	ND            string `json:"nd" gorm:"column:nd"`
+	Tipo          string `json:"tipo"`
+	FaixaMin      int    `json:"faixa_min"`
+	FaixaMax      int    `json:"faixa_max"`
+	Resultado     string `json:"resultado"`
+	Dados         string `json:"dados"`
+	Multiplicador int    `json:"multiplicador" gorm:"default:1"`
+	Categoria     string `json:"categoria"` // categoria da riqueza ou do item no catálogo
+}
+
+func (TesouroTabela) TableName() string {
+	return "tesouro_tabelas"
+}
+
+// Riqueza é uma faixa de d% da tabela de riquezas (objetos de valor)
+type Riqueza struct {
+	ID            uint   `json:"id" gorm:"primaryKey"`
+	Categoria     string `json:"categoria"` // menor, media, maior
+	FaixaMin      int    `json:"faixa_min"`
+	FaixaMax      int    `json:"faixa_max"`
+	Nome          string `json:"nome"`
+	Dados         string `json:"dados"`
+	Multiplicador int    `json:"multiplicador" gorm:"default:1"`
+}
+
+func (Riqueza) TableName() string {
+	return "tesouro_riquezas"
+}
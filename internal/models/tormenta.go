@@ -0,0 +1,28 @@
+package models
+
+// TipoPoderTormenta é o tipo dos poderes da Tormenta no catálogo de poderes
+const TipoPoderTormenta = "Tormenta"
+
+// CorrupcaoTormenta resume os poderes da Tormenta de um personagem (calculado, não salvo no DB).
+// Bônus de Deformidade do lefou (e o poder trocado por um deles) contam como poderes da
+// Tormenta, mas não para a perda de Carisma.
+type CorrupcaoTormenta struct {
+	Poderes             int      `json:"poderes"`      // poderes da Tormenta escolhidos como poderes
+	Deformidades        int      `json:"deformidades"` // bônus de Deformidade escolhidos em escolhas_raca
+	Total               int      `json:"total"`
+	PenalidadeCarisma   int      `json:"penalidade_carisma"`
+	PericiasDeformidade []string `json:"pericias_deformidade,omitempty"` // perícias com +2 da Deformidade
+}
+
+// PenalidadeCarismaTormenta retorna a perda de Carisma: -1 a cada dois poderes da Tormenta
+func PenalidadeCarismaTormenta(poderes int) int {
+	if poderes <= 0 {
+		return 0
+	}
+	return poderes / 2
+}
+
+// CarismaEfetivo retorna o Carisma do personagem já com a perda pelos poderes da Tormenta
+func (p *Personagem) CarismaEfetivo() int {
+	return p.Car - p.Tormenta.PenalidadeCarisma
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// PersonagemTransacao representa uma entrada no livro-caixa do personagem.
+// O saldo em Personagem.Dinheiro é sempre atualizado junto com a transação.
+type PersonagemTransacao struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	PersonagemID uint      `json:"personagem_id" gorm:"not null;index"`
+	Valor        float64   `json:"valor" gorm:"type:decimal(10,2);not null"`                        // positivo = crédito, negativo = débito
+	SaldoApos    float64   `json:"saldo_apos" gorm:"column:saldo_apos;type:decimal(10,2);not null"` // saldo após a transação
+	Motivo       string    `json:"motivo" gorm:"type:varchar(200);not null"`
+	ItemID       *uint     `json:"item_id" gorm:"column:item_id"` // item relacionado (opcional)
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (PersonagemTransacao) TableName() string {
+	return "personagem_transacoes"
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Usuario é uma conta local (e-mail + senha) dona de personagens
+type Usuario struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Email     string    `json:"email" gorm:"type:varchar(255);not null"`
+	Nome      string    `json:"nome" gorm:"type:varchar(100);default:''"`
+	SenhaHash string    `json:"-" gorm:"type:varchar(100);not null"` // bcrypt
+	Papel     string    `json:"papel" gorm:"type:varchar(20);not null;default:'jogador'"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Usuario) TableName() string {
+	return "usuarios"
+}
+
+// UsuarioSessao é uma sessão de login. Apenas o hash SHA-256 do token é salvo.
+type UsuarioSessao struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UsuarioID uint      `json:"usuario_id" gorm:"not null;index"`
+	TokenHash string    `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	UserAgent string    `json:"user_agent" gorm:"type:varchar(255);default:''"`
+	ExpiraEm  time.Time `json:"expira_em" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (UsuarioSessao) TableName() string {
+	return "usuario_sessoes"
+}
+
+// SessaoTransferencia é um código de uso único que permite a outro dispositivo assumir
+// uma sessão anônima. Apenas o hash SHA-256 do código é salvo.
+type SessaoTransferencia struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	CodigoHash    string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	UserSessionID string     `json:"-" gorm:"type:varchar(36);not null;index"`
+	UserIPHash    *string    `json:"-" gorm:"type:char(64)"`
+	ExpiraEm      time.Time  `json:"expira_em" gorm:"not null"`
+	UsadoEm       *time.Time `json:"usado_em"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (SessaoTransferencia) TableName() string {
+	return "sessao_transferencias"
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Motivos de uma versão do personagem
+const (
+	VersaoCriacao     = "criacao"
+	VersaoBase        = "base" // estado de personagens criados antes do histórico
+	VersaoEdicao      = "edicao"
+	VersaoRestauracao = "restauracao"
+)
+
+// PersonagemVersao é uma foto imutável da ficha gravada a cada salvamento. Dados guarda
+// uma FichaVersao em JSON.
+type PersonagemVersao struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	PersonagemID uint      `json:"personagem_id" gorm:"not null;index"`
+	Versao       int       `json:"versao" gorm:"not null"`
+	Dados        string    `json:"-" gorm:"type:jsonb;not null"`
+	UsuarioID    *uint     `json:"usuario_id"`
+	Motivo       string    `json:"motivo" gorm:"type:varchar(20);not null"`
+	RestauradaDe *int      `json:"restaurada_de"` // versão de origem, em restaurações
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (PersonagemVersao) TableName() string {
+	return "personagem_versoes"
+}
+
+// FichaVersao é o conteúdo de uma versão: os campos editáveis da ficha e suas relações.
+// PV e PM atuais, rolagens de PV, mesa e estado ficam de fora: não são salvos pela edição da ficha.
+type FichaVersao struct {
+	Nome            string          `json:"nome"`
+	Nivel           int             `json:"nivel"`
+	Experiencia     int             `json:"experiencia"`
+	For             int             `json:"for"`
+	Des             int             `json:"des"`
+	Con             int             `json:"con"`
+	Int             int             `json:"int"`
+	Sab             int             `json:"sab"`
+	Car             int             `json:"car"`
+	RacaID          uint            `json:"raca_id"`
+	ClasseID        uint            `json:"classe_id"`
+	OrigemID        uint            `json:"origem_id"`
+	DivindadeID     *uint           `json:"divindade_id"`
+	CaminhoID       *uint           `json:"caminho_id"`
+	EscolhasRaca    json.RawMessage `json:"escolhas_raca"`
+	AtributosLivres json.RawMessage `json:"atributos_livres"`
+	Dinheiro        float64         `json:"dinheiro"`
+	Anotacoes       string          `json:"anotacoes"`
+	Historico       string          `json:"historico"`
+
+	Pericias           []PersonagemPericia     `json:"pericias"`
+	BeneficiosPericias []uint                  `json:"beneficios_pericias"`
+	BeneficiosPoderes  []uint                  `json:"beneficios_poderes"`
+	PoderesDivinos     []PersonagemPoderDivino `json:"poderes_divinos"`
+	PoderesClasse      []PersonagemPoderClasse `json:"poderes_classe"`
+	Itens              []ItemVersao            `json:"itens"`
+	Parceiros          []ParceiroVersao        `json:"parceiros"` // nil em versões gravadas antes dos parceiros
+}
+
+// ParceiroVersao é um parceiro do personagem numa versão
+type ParceiroVersao struct {
+	Nome         string          `json:"nome"`
+	Tipo         string          `json:"tipo"`
+	Patamar      string          `json:"patamar"`
+	Fonte        string          `json:"fonte"`
+	Ativo        bool            `json:"ativo"`
+	BonusAtaque  int             `json:"bonus_ataque"`
+	BonusDano    int             `json:"bonus_dano"`
+	BonusDefesa  int             `json:"bonus_defesa"`
+	BonusPericia int             `json:"bonus_pericia"`
+	Pericias     json.RawMessage `json:"pericias"`
+	Descricao    string          `json:"descricao"`
+}
+
+// ItemVersao é um item do inventário numa versão, com melhorias e encantos por ID
+type ItemVersao struct {
+	ID             uint    `json:"id"`
+	Nome           string  `json:"nome"`
+	Tipo           string  `json:"tipo"`
+	Quantidade     int     `json:"quantidade"`
+	Peso           float64 `json:"peso"`
+	Valor          float64 `json:"valor"`
+	Descricao      string  `json:"descricao"`
+	ItemCatalogoID *uint   `json:"item_catalogo_id"`
+	Fonte          string  `json:"fonte"`
+	OrigemItemID   *uint   `json:"origem_item_id"`
+	Ordem          int     `json:"ordem"`
+	ContainerID    *uint   `json:"container_id"`
+	Capacidade     float64 `json:"capacidade"`
+	Equipado       bool    `json:"equipado"`
+	CategoriaArma  string  `json:"categoria_arma"`
+	MelhoriaIDs    []uint  `json:"melhoria_ids"`
+	EncantoIDs     []uint  `json:"encanto_ids"`
+}
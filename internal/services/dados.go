@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxDados limita a quantidade de dados numa expressão para evitar abusos
+const maxDados = 100
+
+var expressaoDados = regexp.MustCompile(`^(\d*)d(\d+)([+-]\d+)?$`)
+
+// ResultadoDados guarda o resultado de uma rolagem como "2d8+2"
+type ResultadoDados struct {
+	Expressao   string `json:"expressao"`
+	Rolagens    []int  `json:"rolagens"`
+	Modificador int    `json:"modificador"`
+	Total       int    `json:"total"`
+}
+
+// RolarDados rola uma expressão no formato XdY+Z (X e Z opcionais). Um número puro
+// ("5") é tratado como valor fixo.
+func RolarDados(expressao string) (*ResultadoDados, error) {
+	return RolarDadosCom(nil, expressao)
+}
+
+// RolarDadosCom rola a expressão usando o gerador informado, permitindo resultados
+// reproduzíveis a partir de uma seed. Com gerador nil, usa o gerador global.
+func RolarDadosCom(gerador *rand.Rand, expressao string) (*ResultadoDados, error) {
+	intn := rand.Intn
+	if gerador != nil {
+		intn = gerador.Intn
+	}
+
+	expr := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(expressao)), " ", "")
+	resultado := &ResultadoDados{Expressao: expr, Rolagens: []int{}}
+
+	if fixo, err := strconv.Atoi(expr); err == nil {
+		resultado.Modificador = fixo
+		resultado.Total = fixo
+		return resultado, nil
+	}
+
+	partes := expressaoDados.FindStringSubmatch(expr)
+	if partes == nil {
+		return nil, fmt.Errorf("expressão de dados inválida: %q", expressao)
+	}
+
+	quantidade := 1
+	if partes[1] != "" {
+		quantidade, _ = strconv.Atoi(partes[1])
+	}
+	faces, _ := strconv.Atoi(partes[2])
+	if quantidade < 1 || quantidade > maxDados || faces < 1 {
+		return nil, fmt.Errorf("expressão de dados inválida: %q", expressao)
+	}
+	if partes[3] != "" {
+		resultado.Modificador, _ = strconv.Atoi(partes[3])
+	}
+
+	resultado.Total = resultado.Modificador
+	for i := 0; i < quantidade; i++ {
+		rolagem := intn(faces) + 1
+		resultado.Rolagens = append(resultado.Rolagens, rolagem)
+		resultado.Total += rolagem
+	}
+
+	return resultado, nil
+}
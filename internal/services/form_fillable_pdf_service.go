@@ -38,6 +38,7 @@ func (s *FormFillablePDFService) generateSinglePageFormSheet(pdf *gofpdf.Fpdf, p
 	s.addFormBasicInfo(pdf, personagem)
 	s.addCombatStats(pdf, personagem)
 	s.addFormAttributes(pdf, personagem, options.ShowCalculations)
+	s.addFormParceiros(pdf, personagem)
 
 	for _, section := range options.ExtraSections {
 		switch section {
@@ -77,6 +78,7 @@ func (s *FormFillablePDFService) generateDoublePageFormSheet(pdf *gofpdf.Fpdf, p
 	// Pagina 2: Poderes, inventario, notas, historico
 	pdf.AddPage()
 	s.addPowersSection(pdf, personagem)
+	s.addFormParceiros(pdf, personagem)
 	s.addFormInventory(pdf)
 	s.addFormNotes(pdf)
 	s.addFormHistory(pdf)
@@ -134,6 +136,9 @@ func (s *FormFillablePDFService) addFormBasicInfo(pdf *gofpdf.Fpdf, personagem *
 	classeNome := "-"
 	if personagem.Classe.Nome != "" {
 		classeNome = personagem.Classe.Nome
+		if personagem.Caminho != nil {
+			classeNome += " (" + personagem.Caminho.Nome + ")"
+		}
 	}
 	origemNome := "-"
 	if personagem.Origem.Nome != "" {
@@ -224,7 +229,16 @@ func (s *FormFillablePDFService) addCombatStats(pdf *gofpdf.Fpdf, personagem *mo
 	pdf.Text(x+14, y+14, fmt.Sprintf("%+d", modDes))
 	pdf.SetTextColor(0, 0, 0)
 
-	pdf.SetY(y + boxH + 4)
+	y += boxH + 4
+
+	// CD de magia (atributo-chave da classe ou do caminho)
+	if personagem.CDMagia > 0 {
+		pdf.SetFont("Arial", "B", 8)
+		pdf.Text(10, y+2, fmt.Sprintf("CD DAS MAGIAS: %d (atributo-chave %s)", personagem.CDMagia, personagem.AtributoChave))
+		y += 6
+	}
+
+	pdf.SetY(y)
 }
 
 // ========== ATRIBUTOS ==========
@@ -246,7 +260,7 @@ func (s *FormFillablePDFService) addFormAttributes(pdf *gofpdf.Fpdf, personagem
 		{"Constituicao", "CON", personagem.Con},
 		{"Inteligencia", "INT", personagem.Int},
 		{"Sabedoria", "SAB", personagem.Sab},
-		{"Carisma", "CAR", personagem.Car},
+		{"Carisma", "CAR", personagem.CarismaEfetivo()},
 	}
 
 	colW := 31.3
@@ -337,7 +351,7 @@ func (s *FormFillablePDFService) addFormSkillsFilled(pdf *gofpdf.Fpdf, personage
 	// Mapa de atributos para valores
 	attrMap := map[string]int{
 		"FOR": personagem.For, "DES": personagem.Des, "CON": personagem.Con,
-		"INT": personagem.Int, "SAB": personagem.Sab, "CAR": personagem.Car,
+		"INT": personagem.Int, "SAB": personagem.Sab, "CAR": personagem.CarismaEfetivo(),
 	}
 
 	// Duas colunas
@@ -488,6 +502,43 @@ func (s *FormFillablePDFService) addPowersSection(pdf *gofpdf.Fpdf, personagem *
 	pdf.SetY(y + 2)
 }
 
+// ========== PARCEIROS ==========
+
+func (s *FormFillablePDFService) addFormParceiros(pdf *gofpdf.Fpdf, personagem *models.Personagem) {
+	if len(personagem.Parceiros) == 0 {
+		return
+	}
+	if pdf.GetY() > 250 {
+		pdf.AddPage()
+	}
+
+	y := pdf.GetY()
+	s.drawSectionTitle(pdf, "PARCEIROS", y)
+	y += 7
+
+	for _, p := range personagem.Parceiros {
+		if y > 275 {
+			pdf.AddPage()
+			y = 15
+		}
+		status := ""
+		if !p.Ativo {
+			status = " - inativo"
+		}
+		pdf.SetFont("Arial", "B", 8)
+		pdf.Text(10, y, fmt.Sprintf("%s (%s %s%s)", p.Nome, p.Tipo, p.Patamar, status))
+		y += 4
+		if p.Descricao != "" {
+			pdf.SetFont("Arial", "", 7)
+			pdf.Text(12, y, p.Descricao)
+			y += 4
+		}
+		y += 1
+	}
+
+	pdf.SetY(y + 2)
+}
+
 // ========== INVENTARIO ==========
 
 func (s *FormFillablePDFService) addFormInventory(pdf *gofpdf.Fpdf) {
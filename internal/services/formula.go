@@ -0,0 +1,185 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxTamanhoFormula limita o tamanho de uma fórmula para evitar abusos
+const maxTamanhoFormula = 100
+
+var removedorAcentos = strings.NewReplacer("Á", "A", "Â", "A", "Ã", "A", "É", "E", "Ê", "E", "Í", "I", "Ó", "O", "Ô", "O", "Õ", "O", "Ú", "U", "Ç", "C")
+
+// NormalizarVariavel converte o nome de uma variável de fórmula para a forma usada nas
+// chaves: maiúsculas e sem acentos ("Nível" -> "NIVEL")
+func NormalizarVariavel(nome string) string {
+	return removedorAcentos.Replace(strings.ToUpper(strings.TrimSpace(nome)))
+}
+
+// AvaliarFormula calcula uma fórmula inteira como "1 + Car" ou "(NIVEL / 2) + SAB".
+// Aceita números, variáveis (nomes normalizados com NormalizarVariavel), + - * / e
+// parênteses. Divisões arredondam para baixo, como nas regras do T20.
+func AvaliarFormula(formula string, variaveis map[string]int) (int, error) {
+	if len(formula) > maxTamanhoFormula {
+		return 0, fmt.Errorf("fórmula excede %d caracteres", maxTamanhoFormula)
+	}
+
+	tokens, err := tokensFormula(formula)
+	if err != nil {
+		return 0, err
+	}
+	if len(tokens) == 0 {
+		return 0, fmt.Errorf("fórmula vazia")
+	}
+
+	a := &avaliadorFormula{tokens: tokens, variaveis: variaveis}
+	valor, err := a.expressao()
+	if err != nil {
+		return 0, err
+	}
+	if a.pos < len(a.tokens) {
+		return 0, fmt.Errorf("fórmula inválida perto de %q", a.tokens[a.pos])
+	}
+	return valor, nil
+}
+
+// ValidarFormula verifica se a fórmula é válida usando apenas as variáveis conhecidas
+func ValidarFormula(formula string, variaveis []string) error {
+	valores := make(map[string]int, len(variaveis))
+	for _, v := range variaveis {
+		valores[v] = 1
+	}
+	_, err := AvaliarFormula(formula, valores)
+	return err
+}
+
+func tokensFormula(formula string) ([]string, error) {
+	var tokens []string
+	runas := []rune(formula)
+	for i := 0; i < len(runas); {
+		r := runas[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("+-*/()", r):
+			tokens = append(tokens, string(r))
+			i++
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runas) && unicode.IsDigit(runas[j]) {
+				j++
+			}
+			tokens = append(tokens, string(runas[i:j]))
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runas) && (unicode.IsLetter(runas[j]) || unicode.IsDigit(runas[j]) || runas[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, NormalizarVariavel(string(runas[i:j])))
+			i = j
+		default:
+			return nil, fmt.Errorf("caractere inválido na fórmula: %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+// avaliadorFormula é um analisador descendente recursivo para fórmulas inteiras
+type avaliadorFormula struct {
+	tokens    []string
+	pos       int
+	variaveis map[string]int
+}
+
+func (a *avaliadorFormula) proximo() string {
+	if a.pos < len(a.tokens) {
+		return a.tokens[a.pos]
+	}
+	return ""
+}
+
+// expressao := termo (('+' | '-') termo)*
+func (a *avaliadorFormula) expressao() (int, error) {
+	valor, err := a.termo()
+	if err != nil {
+		return 0, err
+	}
+	for op := a.proximo(); op == "+" || op == "-"; op = a.proximo() {
+		a.pos++
+		direita, err := a.termo()
+		if err != nil {
+			return 0, err
+		}
+		if op == "+" {
+			valor += direita
+		} else {
+			valor -= direita
+		}
+	}
+	return valor, nil
+}
+
+// termo := fator (('*' | '/') fator)*
+func (a *avaliadorFormula) termo() (int, error) {
+	valor, err := a.fator()
+	if err != nil {
+		return 0, err
+	}
+	for op := a.proximo(); op == "*" || op == "/"; op = a.proximo() {
+		a.pos++
+		direita, err := a.fator()
+		if err != nil {
+			return 0, err
+		}
+		if op == "*" {
+			valor *= direita
+			continue
+		}
+		if direita == 0 {
+			return 0, fmt.Errorf("divisão por zero na fórmula")
+		}
+		// Divisão arredondando para baixo também com valores negativos
+		quociente := valor / direita
+		if (valor%direita != 0) && ((valor < 0) != (direita < 0)) {
+			quociente--
+		}
+		valor = quociente
+	}
+	return valor, nil
+}
+
+// fator := número | variável | '(' expressao ')' | '-' fator
+func (a *avaliadorFormula) fator() (int, error) {
+	token := a.proximo()
+	if token == "" {
+		return 0, fmt.Errorf("fórmula incompleta")
+	}
+	a.pos++
+
+	switch {
+	case token == "-":
+		valor, err := a.fator()
+		return -valor, err
+	case token == "(":
+		valor, err := a.expressao()
+		if err != nil {
+			return 0, err
+		}
+		if a.proximo() != ")" {
+			return 0, fmt.Errorf("parêntese não fechado na fórmula")
+		}
+		a.pos++
+		return valor, nil
+	case unicode.IsDigit([]rune(token)[0]):
+		return strconv.Atoi(token)
+	}
+
+	valor, ok := a.variaveis[token]
+	if !ok {
+		return 0, fmt.Errorf("variável desconhecida na fórmula: %s", token)
+	}
+	return valor, nil
+}
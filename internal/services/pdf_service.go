@@ -51,6 +51,7 @@ func (s *PDFService) generateSinglePageSheet(mrt core.Maroto, personagem *models
 	s.addBasicInfo(mrt, personagem)
 	s.addCombatRow(mrt, personagem)
 	s.addAttributes(mrt, personagem, options.ShowCalculations)
+	s.addParceiros(mrt, personagem)
 
 	for _, section := range options.ExtraSections {
 		switch section {
@@ -79,6 +80,7 @@ func (s *PDFService) generateDoublePageSheet(mrt core.Maroto, personagem *models
 	s.addCombatRow(mrt, personagem)
 	s.addAttributes(mrt, personagem, options.ShowCalculations)
 	s.addSkillsFilled(mrt, personagem)
+	s.addParceiros(mrt, personagem)
 	s.addInventory(mrt)
 	s.addNotes(mrt)
 	s.addHistory(mrt)
@@ -113,6 +115,9 @@ func (s *PDFService) addBasicInfo(mrt core.Maroto, personagem *models.Personagem
 	classeNome := "-"
 	if personagem.Classe.Nome != "" {
 		classeNome = personagem.Classe.Nome
+		if personagem.Caminho != nil {
+			classeNome += " (" + personagem.Caminho.Nome + ")"
+		}
 	}
 	origemNome := "-"
 	if personagem.Origem.Nome != "" {
@@ -192,6 +197,16 @@ func (s *PDFService) addCombatRow(mrt core.Maroto, personagem *models.Personagem
 		),
 	)
 
+	if personagem.CDMagia > 0 {
+		mrt.AddRow(6,
+			col.New(12).Add(
+				text.New(fmt.Sprintf("CD das magias: %d (atributo-chave %s)", personagem.CDMagia, personagem.AtributoChave), props.Text{
+					Top: 1, Align: align.Center, Size: 10,
+				}),
+			),
+		)
+	}
+
 	mrt.AddRow(3)
 }
 
@@ -212,7 +227,7 @@ func (s *PDFService) addAttributes(mrt core.Maroto, personagem *models.Personage
 		{"Constituicao", "CON", personagem.Con},
 		{"Inteligencia", "INT", personagem.Int},
 		{"Sabedoria", "SAB", personagem.Sab},
-		{"Carisma", "CAR", personagem.Car},
+		{"Carisma", "CAR", personagem.CarismaEfetivo()},
 	}
 
 	mrt.AddRow(8,
@@ -259,7 +274,7 @@ func (s *PDFService) addSkillsFilled(mrt core.Maroto, personagem *models.Persona
 
 	attrMap := map[string]int{
 		"FOR": personagem.For, "DES": personagem.Des, "CON": personagem.Con,
-		"INT": personagem.Int, "SAB": personagem.Sab, "CAR": personagem.Car,
+		"INT": personagem.Int, "SAB": personagem.Sab, "CAR": personagem.CarismaEfetivo(),
 	}
 
 	// Cabecalho
@@ -300,6 +315,41 @@ func (s *PDFService) addSkillsFilled(mrt core.Maroto, personagem *models.Persona
 	mrt.AddRow(3)
 }
 
+// addParceiros lista os parceiros do personagem (a seção é omitida se não houver nenhum)
+func (s *PDFService) addParceiros(mrt core.Maroto, personagem *models.Personagem) {
+	if len(personagem.Parceiros) == 0 {
+		return
+	}
+
+	mrt.AddRow(6,
+		col.New(12).Add(
+			text.New("PARCEIROS", props.Text{Top: 1, Style: fontstyle.Bold, Align: align.Center, Size: 11}),
+		),
+	)
+
+	mrt.AddRow(5,
+		col.New(3).Add(text.New("Nome", props.Text{Style: fontstyle.Bold, Size: 8})),
+		col.New(2).Add(text.New("Tipo", props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Center})),
+		col.New(2).Add(text.New("Patamar", props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Center})),
+		col.New(5).Add(text.New("Beneficio", props.Text{Style: fontstyle.Bold, Size: 8})),
+	)
+
+	for _, p := range personagem.Parceiros {
+		nome := p.Nome
+		if !p.Ativo {
+			nome += " (inativo)"
+		}
+		mrt.AddRow(4,
+			col.New(3).Add(text.New(nome, props.Text{Size: 7})),
+			col.New(2).Add(text.New(p.Tipo, props.Text{Size: 7, Align: align.Center})),
+			col.New(2).Add(text.New(p.Patamar, props.Text{Size: 7, Align: align.Center})),
+			col.New(5).Add(text.New(p.Descricao, props.Text{Size: 7})),
+		)
+	}
+
+	mrt.AddRow(3)
+}
+
 func (s *PDFService) addInventory(mrt core.Maroto) {
 	mrt.AddRow(6,
 		col.New(12).Add(